@@ -0,0 +1,213 @@
+package testdock
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ory/dockertest/v3"
+)
+
+// ArtifactMode defines how the schema artifact is used.
+type ArtifactMode int
+
+const (
+	// ArtifactModeOff - the artifact is not used.
+	ArtifactModeOff ArtifactMode = 0
+	// ArtifactModeRecord - after migrations, the test database is dumped into the artifact.
+	ArtifactModeRecord ArtifactMode = 1
+	// ArtifactModeReplay - the artifact is restored into the test database instead of applying migrations.
+	// Combine it with RunModeExternal (or another backend without Docker) to run tests offline.
+	ArtifactModeReplay ArtifactMode = 2
+	// ArtifactModeAuto - checks the environment variable TESTDOCK_ARTIFACT_MODE.
+	// Values `record` and `replay` select ArtifactModeRecord and ArtifactModeReplay,
+	// otherwise ArtifactModeOff.
+	ArtifactModeAuto ArtifactMode = 3
+)
+
+// artifactModeEnv is the environment variable used by ArtifactModeAuto.
+const artifactModeEnv = "TESTDOCK_ARTIFACT_MODE"
+
+// WithArtifact sets the file of the schema artifact and the artifact mode.
+// The artifact is a plain SQL dump of the migrated test database (schema and seed data).
+// Only PostgreSQL drivers are supported. Recording uses pg_dump from the container in RunModeDocker
+// and pg_dump from PATH otherwise.
+func WithArtifact(path string, mode ArtifactMode) Option {
+	return func(o *testDB) {
+		o.artifactPath = path
+		o.artifactMode = mode
+	}
+}
+
+// prepareArtifactOptions validates the artifact options.
+func (d *testDB) prepareArtifactOptions() error {
+	if d.artifactMode == ArtifactModeAuto {
+		switch strings.ToLower(os.Getenv(artifactModeEnv)) {
+		case "record":
+			d.artifactMode = ArtifactModeRecord
+		case "replay":
+			d.artifactMode = ArtifactModeReplay
+		default:
+			d.artifactMode = ArtifactModeOff
+		}
+	}
+
+	if d.artifactMode == ArtifactModeOff {
+		return nil
+	}
+
+	if d.artifactPath == "" {
+		return errors.New("artifact path is empty")
+	}
+	if !isPostgresDriver(d.driver) {
+		return fmt.Errorf("artifact is not supported for driver %s", d.driver)
+	}
+
+	return nil
+}
+
+// isPostgresDriver checks if the driver is one of the PostgreSQL drivers.
+func isPostgresDriver(driver string) bool {
+	return driver == "pgx" || driver == "postgres"
+}
+
+// recordArtifact dumps the test database into the artifact file.
+func (d *testDB) recordArtifact(ctx context.Context) error {
+	d.logger.Info(ctx, "recording artifact", "dsn", d.dsnNoPass, "artifact", d.artifactPath)
+
+	dump, err := d.pgDump(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(d.artifactPath), 0o750); err != nil {
+		return fmt.Errorf("create artifact directory: %w", err)
+	}
+	// tests record the artifact concurrently, so it is replaced atomically
+	f, err := os.CreateTemp(filepath.Dir(d.artifactPath), filepath.Base(d.artifactPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create artifact: %w", err)
+	}
+	_, err = f.Write(dump)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), d.artifactPath)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("write artifact: %w", err)
+	}
+
+	d.logger.Info(ctx, "artifact recorded", "dsn", d.dsnNoPass, "artifact", d.artifactPath)
+
+	return nil
+}
+
+// pgDump dumps the schema and data of the test database into plain SQL with INSERT statements,
+// which can be executed without psql.
+func (d *testDB) pgDump(ctx context.Context) ([]byte, error) {
+	args := []string{
+		"--no-owner",
+		"--no-privileges",
+		"--inserts",
+		"--username", d.url.User,
+		"--dbname", d.databaseName,
+	}
+
+	var (
+		dump bytes.Buffer
+		err  error
+	)
+	if d.mode == RunModeDocker {
+		err = d.execInContainer(append([]string{"pg_dump", "--host", "127.0.0.1",
+			"--port", strconv.Itoa(d.dockerPort)}, args...), &dump)
+	} else {
+		cmd := exec.CommandContext(ctx, "pg_dump", append([]string{"--host", d.url.Host,
+			"--port", strconv.Itoa(d.url.Port)}, args...)...)
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+d.url.Password)
+		cmd.Stdout = &dump
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err = cmd.Run(); err != nil {
+			err = fmt.Errorf("%w: %s", err, stderr.String())
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pg_dump: %w", err)
+	}
+
+	return dump.Bytes(), nil
+}
+
+// execInContainer executes the command in the Docker container of the test database.
+func (d *testDB) execInContainer(cmd []string, stdout *bytes.Buffer) error {
+	if d.resource == nil {
+		return errors.New("docker resource is not found")
+	}
+
+	var stderr bytes.Buffer
+	exitCode, err := d.resource.Exec(cmd, dockertest.ExecOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Env:    []string{"PGPASSWORD=" + d.url.Password},
+		StdOut: stdout,
+		StdErr: &stderr,
+	})
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("exit code %d: %s", exitCode, stderr.String())
+	}
+
+	return nil
+}
+
+// replayArtifact restores the artifact into the test database.
+func (d *testDB) replayArtifact(ctx context.Context) error {
+	d.logger.Info(ctx, "replaying artifact", "dsn", d.dsnNoPass, "artifact", d.artifactPath)
+
+	data, err := os.ReadFile(d.artifactPath)
+	if err != nil {
+		return fmt.Errorf("read artifact (record it with %s=record): %w", artifactModeEnv, err)
+	}
+
+	db, err := d.connectSQLDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	if _, err = db.ExecContext(ctx, stripPsqlMetaCommands(string(data))); err != nil {
+		return fmt.Errorf("replay artifact: %w", err)
+	}
+
+	d.logger.Info(ctx, "artifact replayed", "dsn", d.dsnNoPass, "artifact", d.artifactPath)
+
+	return nil
+}
+
+// stripPsqlMetaCommands removes psql meta-commands (for example \restrict) which pg_dump
+// writes into plain dumps and which the server cannot execute.
+func stripPsqlMetaCommands(dump string) string {
+	var b strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(dump))
+	scanner.Buffer(nil, len(dump)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, `\`) {
+			continue
+		}
+		_, _ = b.WriteString(line)
+		_, _ = b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestStripPsqlMetaCommands verifies that pg_dump meta-commands are not sent to the server.
+func TestStripPsqlMetaCommands(t *testing.T) {
+	t.Parallel()
+
+	dump := "\\restrict abc\nSET statement_timeout = 0;\nCREATE TABLE t (id int);\n\\unrestrict abc\n"
+	require.Equal(t, "SET statement_timeout = 0;\nCREATE TABLE t (id int);\n", stripPsqlMetaCommands(dump))
+}
+
+// TestWithArtifactValidation verifies the artifact option contract.
+func TestWithArtifactValidation(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	err := db.prepareOptions("pgx", []Option{WithArtifact("", ArtifactModeRecord)})
+	require.ErrorContains(t, err, "artifact path is empty")
+
+	db = newExternalTestDB(t)
+	db.driver = "mysql"
+	db.dsn = DefaultMySQLDSN
+	err = db.prepareOptions("mysql", []Option{WithArtifact("testdata/schema.sql", ArtifactModeReplay)})
+	require.ErrorContains(t, err, "artifact is not supported for driver mysql")
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions("pgx", []Option{WithArtifact("testdata/schema.sql", ArtifactModeReplay)}))
+	require.Equal(t, ArtifactModeReplay, db.artifactMode)
+}
@@ -0,0 +1,134 @@
+package testdock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strconv"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/ory/dockertest/v3"
+)
+
+const (
+	// atlasLocation is the directory of the mounted migrations inside the atlas container.
+	atlasLocation = "/migrations"
+	// atlasRevisionsTable is the table of the applied versions, created by Atlas in its own schema.
+	atlasRevisionsTable = "atlas_schema_revisions"
+)
+
+// AtlasMigrateFactory runs the arigaio/atlas docker image against the test database,
+// see AtlasImageMigrateFactory.
+var AtlasMigrateFactory = AtlasImageMigrateFactory("arigaio/atlas", "0.32.0")
+
+// AtlasImageMigrateFactory creates a migrator which runs "atlas migrate apply" of the atlas docker image
+// with the repository and tag against the test database, so projects with an Atlas versioned migrations directory
+// (https://atlasgo.io/concepts/migration-directory-integrity) can use it without converting to goose.
+// Atlas verifies the directory against atlas.sum, so run "atlas migrate hash" after editing migration files,
+// and records the applied versions in the atlas_schema_revisions table.
+// The migrations directory is mounted into the container, the database is reached like with FlywayMigrateFactory.
+// Supported DSNs: PostgreSQL URLs (postgres://...) and MySQL DSNs (user:password@tcp(host:port)/db).
+func AtlasImageMigrateFactory(repository, tag string) MigrateFactory {
+	return describeMigrateFactory(func(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		dir, err := resolveMigrationsDir(migrationsDir)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkMigrationFiles(dir, "*.sql"); err != nil {
+			return nil, err
+		}
+
+		url, err := parseURL(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("parse dsn: %w", err)
+		}
+
+		pool, err := migratorDockerPool()
+		if err != nil {
+			return nil, err
+		}
+
+		return &atlasMigrator{
+			pool:          pool,
+			repository:    repository,
+			tag:           tag,
+			url:           url,
+			migrationsDir: dir,
+			logger:        logger,
+		}, nil
+	}, "atlas %s:%s", repository, tag)
+}
+
+// atlasMigrator runs atlas in a docker container.
+type atlasMigrator struct {
+	pool          *dockertest.Pool
+	repository    string
+	tag           string
+	url           *dbURL
+	migrationsDir string
+	logger        ctxlog.ILogger
+}
+
+// Up applies all new migrations.
+func (m *atlasMigrator) Up(ctx context.Context) error {
+	return m.run(ctx)
+}
+
+// UpTo applies new migrations up to and including the target version.
+func (m *atlasMigrator) UpTo(ctx context.Context, version int64) error {
+	return m.run(ctx, "--to-version", strconv.FormatInt(version, 10))
+}
+
+// run starts "atlas migrate apply" with the arguments and waits for its exit.
+func (m *atlasMigrator) run(ctx context.Context, args ...string) error {
+	host, port, networkMode := migrationContainerTarget(m.pool, m.url)
+
+	atlasURL, err := atlasDatabaseURL(m.url, host, port)
+	if err != nil {
+		return err
+	}
+
+	cmd := append([]string{"migrate", "apply", "--url", atlasURL, "--dir", "file://" + atlasLocation}, args...)
+	err = runMigrationContainer(ctx, m.pool, &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Repository: m.repository,
+		Tag:        m.tag,
+		Cmd:        cmd,
+		Mounts:     []string{m.migrationsDir + ":" + atlasLocation + ":ro"},
+	}, host, networkMode)
+	if err != nil {
+		return fmt.Errorf("atlas migrate apply: %w", err)
+	}
+
+	m.logger.Info(ctx, "atlas migrations applied", "dir", m.migrationsDir)
+
+	return nil
+}
+
+// atlasDatabaseURL returns the Atlas URL of the database from the DSN with the host and the port.
+func atlasDatabaseURL(url *dbURL, host string, port int) (string, error) {
+	u := neturl.URL{ //nolint:exhaustruct // optional URL parts.
+		Host: net.JoinHostPort(host, strconv.Itoa(port)),
+		Path: "/" + url.Database,
+		User: neturl.UserPassword(url.User, url.Password),
+	}
+
+	switch {
+	case url.Protocol == "postgres" || url.Protocol == "postgresql":
+		// the password of a URL is escaped, the password of a MySQL DSN is not
+		password, err := neturl.PathUnescape(url.Password)
+		if err != nil {
+			return "", fmt.Errorf("invalid password in dsn %s: %w", url.string(true), err)
+		}
+		u.Scheme = "postgres"
+		u.User = neturl.UserPassword(url.User, password)
+		u.RawQuery = "sslmode=disable"
+	case url.Protocol == "mysql" || url.Transport == "tcp":
+		u.Scheme = "mysql"
+	default:
+		return "", fmt.Errorf("atlas does not support dsn %s", url.string(true))
+	}
+
+	return u.String(), nil
+}
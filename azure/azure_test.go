@@ -0,0 +1,64 @@
+package azure
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/n-r-w/testdock/v2/internal/backend/backendtest"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Azurite(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetBlobClient(t, testdock.DefaultAzuriteDSN, testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, informer.DatabaseName(), informer.ContainerName())
+	require.True(t, strings.HasSuffix(informer.BlobEndpoint(), "/devstoreaccount1"))
+	require.Contains(t, informer.ConnectionString(), "AccountName=devstoreaccount1;")
+
+	_, err := client.UploadBuffer(t.Context(), informer.ContainerName(), "blob", []byte("data"), nil)
+	require.NoError(t, err)
+
+	resp, err := client.DownloadStream(t.Context(), informer.ContainerName(), "blob", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+}
+
+// TestContainerLifecycle verifies the container requests of the test.
+func TestContainerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	url, err := backend.ParseDSN(strings.Replace(testdock.DefaultAzuriteDSN,
+		"127.0.0.1:10000", strings.TrimPrefix(srv.URL, "http://"), 1))
+	require.NoError(t, err)
+	db := backendtest.New(t, url, "test_container")
+
+	require.NoError(t, createContainer(t.Context(), db))
+	require.NoError(t, deleteContainer(t.Context(), db))
+
+	path := "/devstoreaccount1/" + db.DatabaseName() + "?restype=container"
+	require.Equal(t, []string{"PUT " + path, "DELETE " + path}, requests)
+}
@@ -0,0 +1,262 @@
+package testdock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+)
+
+const (
+	// kubernetesModule is the submodule of RunModeKubernetes.
+	kubernetesModule = "github.com/n-r-w/testdock/v2/k8s"
+	// embeddedModule is the submodule of RunModeEmbedded.
+	embeddedModule = "github.com/n-r-w/testdock/v2/embedded"
+	// azure blob driver name of the github.com/n-r-w/testdock/v2/azure module.
+	azureBlobDriverName = "azblob"
+	// tarantool driver name of the github.com/n-r-w/testdock/v2/tarantool module.
+	tarantoolDriverName = "tarantool"
+	// redis driver name of the github.com/n-r-w/testdock/v2/redis module.
+	redisDriverName = "redis"
+)
+
+// The hooks connect the submodules to the test databases of the package.
+//
+//nolint:gochecknoinits // the submodules use the hooks, which can not import this package from internal/backend.
+func init() {
+	backend.Start = startBackend
+	backend.FromInformer = backendFromInformer
+	backend.ParseDSN = parseBackendURL
+	backend.LoadScripts = loadBackendScripts
+	backend.NewScriptMigrator = newBackendScriptMigrator
+}
+
+// withBackendEngine sets the engine of a submodule, which creates and removes the test database.
+func withBackendEngine(engine *backend.Engine) Option {
+	return func(o *testDB) {
+		o.engine = engine
+	}
+}
+
+// startBackend creates the test database of a Get function of a submodule.
+func startBackend(tb testing.TB, dsn string, preset backend.Preset, opt any) backend.Database {
+	tb.Helper()
+
+	presetOptions, _ := preset.Options.([]Option)
+	testOptions, _ := opt.([]Option)
+
+	optPrepared := make([]Option, 0, len(presetOptions)+2) //nolint:mnd // the data directory and the engine.
+	optPrepared = append(optPrepared, presetOptions...)
+	if preset.DataDir != "" {
+		optPrepared = append(optPrepared, withDockerDataDir(preset.DataDir))
+	}
+	if preset.Engine != nil {
+		optPrepared = append(optPrepared, withBackendEngine(preset.Engine))
+	}
+
+	optPrepared = append(optPrepared, withDriverDefaults(preset.Defaults, testOptions)...)
+
+	return backendDB{testDB: newTDB(context.Background(), tb, preset.Driver, dsn, optPrepared)}
+}
+
+// backendFromInformer returns the test database of the informer, nil for informers of other packages.
+func backendFromInformer(informer any) backend.Database {
+	i, ok := informer.(interface{ testDatabase() *testDB })
+	if !ok {
+		return nil
+	}
+
+	return backendDB{testDB: i.testDatabase()}
+}
+
+// testDatabase returns the test database of the informers which embed it.
+func (d *testDB) testDatabase() *testDB {
+	return d
+}
+
+// parseBackendURL parses the connection string for a submodule.
+func parseBackendURL(dsn string) (backend.URL, error) {
+	url, err := parseURL(dsn)
+	if err != nil {
+		return backend.URL{}, err //nolint:exhaustruct // no URL on error.
+	}
+
+	return backend.URL{
+		User:     url.User,
+		Password: url.Password,
+		Host:     url.Host,
+		Port:     url.Port,
+		Database: url.Database,
+	}, nil
+}
+
+// loadBackendScripts finds the migration files of a submodule.
+func loadBackendScripts(migrationsDir, pattern string) ([]backend.Script, error) {
+	migrations, err := loadScriptMigrations(migrationsDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make([]backend.Script, 0, len(migrations))
+	for _, migration := range migrations {
+		scripts = append(scripts, backend.Script{Version: migration.version, Path: migration.path})
+	}
+
+	return scripts, nil
+}
+
+// newBackendScriptMigrator returns the script migrator of a submodule.
+func newBackendScriptMigrator(scripts []backend.Script, exec func(ctx context.Context, script string) error,
+	closeConn func() error, logger ctxlog.ILogger,
+) backend.Migrator {
+	migrations := make([]scriptMigration, 0, len(scripts))
+	for _, script := range scripts {
+		migrations = append(migrations, scriptMigration{version: script.Version, path: script.Path})
+	}
+
+	return &scriptMigrator{
+		exec:       exec,
+		begin:      nil,
+		close:      closeConn,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// createBackendServerResources starts the server of the run mode registered by a submodule.
+func (d *testDB) createBackendServerResources(ctx context.Context) error {
+	server, ok := backend.LookupServer(int(d.mode))
+	if !ok {
+		name, module := "RunModeEmbedded", embeddedModule
+		if d.mode == RunModeKubernetes {
+			name, module = "RunModeKubernetes", kubernetesModule
+		}
+		return fmt.Errorf("%s requires the import of %s", name, module)
+	}
+
+	return server.Start(ctx, backendDB{testDB: d})
+}
+
+// backendDB implements backend.ServerDatabase.
+type backendDB struct {
+	*testDB
+}
+
+// URL returns the parsed connection string with the port of the running server.
+func (b backendDB) URL() backend.URL {
+	return backend.URL{
+		User:     b.url.User,
+		Password: b.url.Password,
+		Host:     b.url.Host,
+		Port:     b.url.Port,
+		Database: b.connectDatabase,
+	}
+}
+
+// LogDSN returns the connection string without the password.
+func (b backendDB) LogDSN() string {
+	return b.dsnNoPass
+}
+
+// Logger returns the logger of the test.
+func (b backendDB) Logger() ctxlog.ILogger {
+	return b.logger
+}
+
+// Retry retries the operation with the retry options of the test.
+func (b backendDB) Retry(ctx context.Context, op func() error) error {
+	return b.retryConnect(ctx, b.dsnNoPass, op)
+}
+
+// CloseAfterTest closes the resource after the test with the close timeout of the test.
+func (b backendDB) CloseAfterTest(resource string, closeResource func() error) {
+	b.t.Cleanup(func() {
+		if closeErr := closeResourceWithTimeout(b.closeTimeout, closeResource, func() string {
+			return b.closeTimeoutDetails(resource, nil)
+		}); closeErr != nil {
+			b.t.Errorf("%v", closeErr)
+		}
+	})
+}
+
+// PublishedPort returns the host port of the container port, false without a docker container.
+func (b backendDB) PublishedPort(port int) (int, bool) {
+	if b.resource == nil {
+		return 0, false
+	}
+
+	published, err := strconv.Atoi(b.resource.GetPort(fmt.Sprintf("%d/tcp", port)))
+	if err != nil {
+		return 0, false
+	}
+
+	return published, true
+}
+
+// TB returns the test.
+func (b backendDB) TB() testing.TB {
+	return b.t
+}
+
+// Container returns the docker container of the test database.
+func (b backendDB) Container() backend.Container {
+	pullPolicy := "IfNotPresent"
+	switch b.imagePullPolicy {
+	case ImagePullAlways:
+		pullPolicy = "Always"
+	case ImagePullNever:
+		pullPolicy = "Never"
+	case ImagePullIfNotPresent:
+	}
+
+	return backend.Container{
+		Key:             b.dockerResourceKey(),
+		Image:           b.dockerRepository + ":" + b.dockerImage,
+		PullPolicy:      pullPolicy,
+		Entrypoint:      b.dockerEntrypoint,
+		Cmd:             b.dockerCmd,
+		Env:             b.dockerEnv,
+		Port:            b.dockerPort,
+		CPUs:            b.dockerCPUs,
+		Memory:          b.dockerMemory,
+		Privileged:      b.dockerPrivileged,
+		StopTimeout:     b.containerStopTimeout,
+		Labels:          b.containerLabels(),
+		SessionLabels:   map[string]string{dockerLabelSession: dockerSessionID},
+		KeepOnFailure:   b.keepOnFailure,
+		FailureLogLines: b.failureLogLines,
+		Namespace:       b.kubeNamespace,
+		KubeContext:     b.kubeContext,
+	}
+}
+
+// SetPort sets the port of the running server.
+func (b backendDB) SetPort(port int) {
+	b.url.Port = port
+	b.dsnNoPass = b.url.string(true)
+}
+
+// StartTimeout returns the time limit of the start of the server.
+func (b backendDB) StartTimeout() time.Duration {
+	return b.untilTestDeadline(b.totalRetryDuration)
+}
+
+// CloseTimeout returns the time limit of the removal of the server.
+func (b backendDB) CloseTimeout() time.Duration {
+	return b.closeTimeout
+}
+
+// RunStartHooks executes the functions which run once after the server is started.
+func (b backendDB) RunStartHooks(ctx context.Context) error {
+	for _, hook := range b.dockerStartHooks {
+		if err := hook(ctx, b.testDB); err != nil {
+			return fmt.Errorf("start hook: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package testdock
+
+// IsolationLevel defines how tests are isolated from each other.
+type IsolationLevel int
+
+const (
+	// IsolationNone - testdock does not know the driver and cannot isolate tests.
+	IsolationNone IsolationLevel = 0
+	// IsolationDatabase - every test gets its own database.
+	IsolationDatabase IsolationLevel = 1
+	// IsolationSchema - every test gets its own schema (user) inside the shared database.
+	IsolationSchema IsolationLevel = 2
+)
+
+// DriverCapabilities describes what testdock can do for a driver.
+type DriverCapabilities struct {
+	// Driver is the driver name, for example "pgx", "mysql" or "mongodb".
+	Driver string
+	// Isolation is the way tests are isolated from each other.
+	Isolation IsolationLevel
+	// DockerPreset reports that a Get* function with docker defaults exists for the driver.
+	DockerPreset bool
+	// EmbeddedMode reports that RunModeEmbedded is supported with the import of github.com/n-r-w/testdock/v2/embedded.
+	EmbeddedMode bool
+	// TemplateCloning reports that a test database can be cloned from a prepared template.
+	TemplateCloning bool
+	// TruncateReset reports that a test database can be reset by truncating tables instead of recreating it.
+	TruncateReset bool
+	// Artifact reports that WithArtifact is supported.
+	Artifact bool
+	// GooseMigrations reports that a predefined GooseMigrateFactory exists for the driver.
+	GooseMigrations bool
+	// GolangMigrateMigrations reports that GolangMigrateFactory supports the driver.
+	GolangMigrateMigrations bool
+}
+
+// Capabilities returns the capabilities of testdock for the driver.
+// Generic test harnesses can use it to select the isolation strategy without per-driver knowledge.
+// For unknown drivers, only the Driver field is filled.
+func Capabilities(driver string) DriverCapabilities {
+	c := DriverCapabilities{ //nolint:exhaustruct // capabilities are filled below per driver.
+		Driver: driver,
+	}
+
+	switch driver {
+	case "pgx", "postgres":
+		c.Isolation = IsolationDatabase
+		c.DockerPreset = true
+		c.EmbeddedMode = true
+		c.TemplateCloning = true
+		c.Artifact = true
+		c.GooseMigrations = true
+		c.GolangMigrateMigrations = true
+	case "mysql":
+		c.Isolation = IsolationDatabase
+		c.DockerPreset = true
+		c.GooseMigrations = true
+	case oracleDriverName:
+		c.Isolation = IsolationSchema
+		c.DockerPreset = true
+	case surrealDriverName, spannerDriverName, firestoreDriverName, pubSubDriverName, azureBlobDriverName,
+		clickHouseDriverName:
+		c.Isolation = IsolationDatabase
+		c.DockerPreset = true
+	case duckDBDriverName, "sqlite3", "sqlite":
+		c.Isolation = IsolationDatabase
+		c.GooseMigrations = driver != duckDBDriverName
+	case tarantoolDriverName, typesenseDriverName, meilisearchDriverName, qdrantDriverName, weaviateDriverName,
+		redisDriverName:
+		c.Isolation = IsolationSchema
+		c.DockerPreset = true
+	case localStackDriverName:
+		c.DockerPreset = true
+	case mongoDriverName:
+		c.Isolation = IsolationDatabase
+		c.DockerPreset = true
+		c.GolangMigrateMigrations = true
+	}
+
+	return c
+}
@@ -0,0 +1,24 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCapabilitiesMatchOptions verifies that reported capabilities match option validation.
+func TestCapabilitiesMatchOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, driver := range []string{"pgx", "postgres", "mysql", oracleDriverName, mongoDriverName, "unknown"} {
+		c := Capabilities(driver)
+		require.Equal(t, driver, c.Driver)
+		require.Equal(t, isPostgresDriver(driver), c.EmbeddedMode, driver)
+		require.Equal(t, isPostgresDriver(driver), c.Artifact, driver)
+		require.Equal(t, isPostgresDriver(driver), c.TemplateCloning, driver)
+	}
+
+	require.Equal(t, IsolationSchema, Capabilities(oracleDriverName).Isolation)
+	require.Equal(t, IsolationNone, Capabilities("unknown").Isolation)
+	require.False(t, Capabilities("unknown").DockerPreset)
+}
@@ -0,0 +1,94 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+)
+
+// ChainMigrateFactory creates a migrator which runs the migrators of the factories one by one
+// against the same DSN, for example goose for the schema and ScriptMigrateFactory for seed data.
+// Every factory receives the same migrations directory, use SubdirMigrateFactory to give each one
+// its own subdirectory. The migrator of the next factory is created after the previous one is applied.
+// Down rolls back the migrators in reverse order, each of them must implement ReversibleMigrator.
+func ChainMigrateFactory(factories ...MigrateFactory) MigrateFactory {
+	return describeMigrateFactory(func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		if len(factories) == 0 {
+			return nil, errors.New("migrate factory chain is empty")
+		}
+		for i, factory := range factories {
+			if factory == nil {
+				return nil, fmt.Errorf("migrate factory %d of the chain is nil", i+1)
+			}
+		}
+
+		return &chainMigrator{
+			t:             t,
+			dsn:           dsn,
+			migrationsDir: migrationsDir,
+			logger:        logger,
+			factories:     factories,
+		}, nil
+	}, "chain %s", migrateFactoriesDescription(factories))
+}
+
+// SubdirMigrateFactory creates a factory which applies the migrations of the subdirectory
+// of the migrations directory with the factory.
+func SubdirMigrateFactory(subdir string, factory MigrateFactory) MigrateFactory {
+	return describeMigrateFactory(func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		if isMigrationsSourceURL(migrationsDir) {
+			return factory(t, dsn, migrationsDir+"/"+path.Clean(subdir), logger)
+		}
+
+		return factory(t, dsn, filepath.Join(migrationsDir, filepath.FromSlash(subdir)), logger)
+	}, "subdir %s %s", subdir, migrateFactoryDescription(factory))
+}
+
+// chainMigrator runs the migrators of the factories one by one.
+type chainMigrator struct {
+	t             testing.TB
+	dsn           string
+	migrationsDir string
+	logger        ctxlog.ILogger
+	factories     []MigrateFactory
+}
+
+// Up applies the migrations of all factories in order.
+func (m *chainMigrator) Up(ctx context.Context) error {
+	for i, factory := range m.factories {
+		migrator, err := factory(m.t, m.dsn, m.migrationsDir, m.logger)
+		if err != nil {
+			return fmt.Errorf("chain migrator %d: new migrator: %w", i+1, err)
+		}
+		if err = migrator.Up(ctx); err != nil {
+			return fmt.Errorf("chain migrator %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Down rolls back the migrations of all factories in reverse order.
+func (m *chainMigrator) Down(ctx context.Context) error {
+	for i := len(m.factories) - 1; i >= 0; i-- {
+		migrator, err := m.factories[i](m.t, m.dsn, m.migrationsDir, m.logger)
+		if err != nil {
+			return fmt.Errorf("chain migrator %d: new migrator: %w", i+1, err)
+		}
+
+		reversibleMigrator, ok := migrator.(ReversibleMigrator)
+		if !ok {
+			return fmt.Errorf("chain migrator %d does not implement ReversibleMigrator", i+1)
+		}
+		if err = reversibleMigrator.Down(ctx); err != nil {
+			return fmt.Errorf("chain migrator %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxChainMigrations(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg", ChainMigrateFactory(
+			SubdirMigrateFactory("goose", GooseMigrateFactoryPGX),
+			SubdirMigrateFactory("seed", ScriptMigrateFactory("pgx")),
+		)),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireTestTableNames(t, db, "test", "seed")
+}
+
+// TestChainMigrateFactory verifies the order of the migrators, the subdirectories and the round trip.
+func TestChainMigrateFactory(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	recording := func(name string) MigrateFactory {
+		return func(_ testing.TB, _, migrationsDir string, _ ctxlog.ILogger) (Migrator, error) {
+			calls = append(calls, name+":"+filepath.ToSlash(migrationsDir))
+			return &recordingMigrator{calls: &calls}, nil
+		}
+	}
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMigrations("migrations", ChainMigrateFactory(
+			SubdirMigrateFactory("schema", recording("schema")),
+			SubdirMigrateFactory("seed", recording("seed")),
+		)),
+		WithMigrationRoundTripCheck(),
+	}))
+
+	require.NoError(t, db.migrationsUp(context.Background()))
+	require.Equal(t, []string{
+		"schema:migrations/schema", "up", "seed:migrations/seed", "up",
+		"seed:migrations/seed", "down", "schema:migrations/schema", "down",
+		"schema:migrations/schema", "up", "seed:migrations/seed", "up",
+	}, calls)
+}
+
+// TestChainMigrateFactoryErrors verifies that the chain stops on the first error.
+func TestChainMigrateFactoryErrors(t *testing.T) {
+	t.Parallel()
+
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+
+	_, err := ChainMigrateFactory()(t, "dsn", "migrations", logger)
+	require.ErrorContains(t, err, "migrate factory chain is empty")
+	_, err = ChainMigrateFactory(GolangMigrateFactory, nil)(t, "dsn", "migrations", logger)
+	require.ErrorContains(t, err, "migrate factory 2 of the chain is nil")
+
+	called := false
+	failing := func(testing.TB, string, string, ctxlog.ILogger) (Migrator, error) {
+		return nil, errors.New("broken")
+	}
+	next := func(testing.TB, string, string, ctxlog.ILogger) (Migrator, error) {
+		called = true
+		return upOnlyMigrator{}, nil
+	}
+
+	migrator, err := ChainMigrateFactory(failing, next)(t, "dsn", "migrations", logger)
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Up(t.Context()), "chain migrator 1: new migrator: broken")
+	require.False(t, called)
+
+	migrator, err = ChainMigrateFactory(next)(t, "dsn", "migrations", logger)
+	require.NoError(t, err)
+	reversible, ok := migrator.(ReversibleMigrator)
+	require.True(t, ok)
+	require.ErrorContains(t, reversible.Down(t.Context()), "does not implement ReversibleMigrator")
+}
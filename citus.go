@@ -0,0 +1,157 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ory/dockertest/v3"
+)
+
+const (
+	// citusCoordinatorRole is the role of the coordinator container, which is the main container of the topology.
+	citusCoordinatorRole = "coordinator"
+	// citusWorkerPort is the port of PostgreSQL inside the worker containers.
+	citusWorkerPort = 5432
+)
+
+// GetCitusPool starts a Citus coordinator and workers on a private docker network, creates the test database
+// with the citus extension on all nodes, registers the workers with citus_add_node, applies migrations
+// on the coordinator, and returns pgx connection pool to the test database on the coordinator.
+// Migrations can create distributed tables, for example with create_distributed_table.
+// Workers accept connections from the coordinator without a password and are published on random host ports.
+// Only RunModeDocker is supported. Tests with the same DSN share the cluster, so they should request
+// the same number of workers.
+// Docker image: https://hub.docker.com/r/citusdata/citus.
+func GetCitusPool(tb testing.TB, dsn string, workers int, opt ...Option) (*pgxpool.Pool, Informer) {
+	tb.Helper()
+
+	if workers < 1 {
+		tb.Fatalf("citus cluster requires at least one worker, got %d", workers)
+	}
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("citusdata/citus"),
+		WithDockerImage("13.0"),
+		withCitusWorkers(workers),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults("citus", opt)...)
+
+	return GetPgxPool(tb, dsn, optPrepared...)
+}
+
+// withCitusWorkers starts the coordinator with the workers.
+func withCitusWorkers(workers int) Option {
+	return func(o *testDB) {
+		o.citusWorkers = workers
+	}
+}
+
+// prepareCitusOptions configures the coordinator container and the hook starting the workers.
+func (d *testDB) prepareCitusOptions() error {
+	if d.citusWorkers == 0 {
+		return nil
+	}
+	if d.mode != RunModeDocker {
+		return errors.New("GetCitusPool supports only RunModeDocker")
+	}
+
+	d.topologyRole = citusCoordinatorRole
+	d.dockerRunOptions = append(d.dockerRunOptions, func(o *dockertest.RunOptions) {
+		o.Cmd = []string{"postgres", "-p", strconv.Itoa(d.dockerPort)}
+	})
+	d.dockerStartHooks = append(d.dockerStartHooks, func(_ context.Context, d *testDB) error {
+		return d.startCitusWorkers()
+	})
+
+	return nil
+}
+
+// citusWorkerRole returns the role of the worker container with the index.
+func citusWorkerRole(i int) string {
+	return fmt.Sprintf("worker%d", i+1)
+}
+
+// startCitusWorkers starts the worker containers.
+func (d *testDB) startCitusWorkers() error {
+	for i := range d.citusWorkers {
+		_, err := d.runTopologyContainer(citusWorkerRole(i), &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+			Repository: d.dockerRepository,
+			Tag:        d.dockerImage,
+			Env: []string{
+				fmt.Sprintf("POSTGRES_USER=%s", d.url.User),
+				fmt.Sprintf("POSTGRES_PASSWORD=%s", d.url.Password),
+				fmt.Sprintf("POSTGRES_DB=%s", d.connectDatabase),
+				// the coordinator connects to the workers as the user of the query without a password
+				"POSTGRES_HOST_AUTH_METHOD=trust",
+			},
+			ExposedPorts: []string{fmt.Sprintf("%d/tcp", citusWorkerPort)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// initCitusDatabase creates the test database with the citus extension on the workers
+// and registers the workers in the test database on the coordinator.
+func (d *testDB) initCitusDatabase(ctx context.Context) error {
+	d.logger.Info(ctx, "initializing citus cluster", "dsn", d.dsnNoPass, "database", d.databaseName,
+		"workers", d.citusWorkers)
+
+	queries := []string{
+		"CREATE EXTENSION IF NOT EXISTS citus",
+		fmt.Sprintf("SELECT citus_set_coordinator_host('%s', %d)",
+			d.topology.containerName(citusCoordinatorRole), d.dockerPort),
+	}
+
+	for i := range d.citusWorkers {
+		role := citusWorkerRole(i)
+		workerURL, err := d.topologyURL(role, citusWorkerPort)
+		if err != nil {
+			return err
+		}
+
+		if err = d.execSQLURL(ctx, workerURL.replaceDatabase(d.connectDatabase),
+			fmt.Sprintf("CREATE DATABASE %s", d.databaseName)); err != nil {
+			return fmt.Errorf("citus %s: %w", role, err)
+		}
+		if err = d.execSQLURL(ctx, workerURL.replaceDatabase(d.databaseName),
+			"CREATE EXTENSION IF NOT EXISTS citus"); err != nil {
+			return fmt.Errorf("citus %s: %w", role, err)
+		}
+
+		queries = append(queries, fmt.Sprintf("SELECT citus_add_node('%s', %d)",
+			d.topology.containerName(role), citusWorkerPort))
+	}
+
+	if err := d.execSQLURL(ctx, d.testURL(), queries...); err != nil {
+		return fmt.Errorf("citus coordinator: %w", err)
+	}
+
+	return nil
+}
+
+// execSQLURL connects to the database from the url and executes the queries.
+func (d *testDB) execSQLURL(ctx context.Context, dbURL *dbURL, queries ...string) error {
+	db, err := d.connectSQLURL(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	for _, query := range queries {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec (%s): %w", query, err)
+		}
+	}
+
+	return nil
+}
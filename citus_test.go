@@ -0,0 +1,60 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CitusPool(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5632", 1)
+	pool, _ := GetCitusPool(t, dsn, 2)
+
+	var workers int
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM citus_get_active_worker_nodes()").Scan(&workers))
+	require.Equal(t, 2, workers)
+
+	_, err := pool.Exec(t.Context(), "CREATE TABLE events (tenant_id int, payload text)")
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), "SELECT create_distributed_table('events', 'tenant_id')")
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), "INSERT INTO events SELECT i, 'x' FROM generate_series(1, 100) i")
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM events").Scan(&count))
+	require.Equal(t, 100, count)
+}
+
+// TestPrepareCitusOptions verifies the coordinator configuration and the supported modes.
+func TestPrepareCitusOptions(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		db.driver = "pgx"
+		db.dsn = strings.Replace(DefaultPostgresDSN, "5432", "5632", 1)
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions("pgx", []Option{
+		WithMode(RunModeDocker), WithDockerRepository("citusdata/citus"), withCitusWorkers(2),
+	}))
+	require.Equal(t, citusCoordinatorRole, db.topologyRole)
+	require.Len(t, db.dockerStartHooks, 1)
+	require.Equal(t, "worker2", citusWorkerRole(1))
+
+	runOptions := &dockertest.RunOptions{} //nolint:exhaustruct // only the command is used.
+	for _, f := range db.dockerRunOptions {
+		f(runOptions)
+	}
+	require.Equal(t, []string{"postgres", "-p", "5632"}, runOptions.Cmd)
+
+	err := newDB().prepareOptions("pgx", []Option{WithMode(RunModeExternal), withCitusWorkers(2)})
+	require.ErrorContains(t, err, "supports only RunModeDocker")
+}
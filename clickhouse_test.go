@@ -0,0 +1,120 @@
+package testdock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ClickHouseCluster(t *testing.T) {
+	t.Parallel()
+
+	conn, informer := GetClickHouseCluster(t, DefaultClickHouseDSN)
+	require.Equal(t, ClickHouseClusterName, informer.Cluster())
+
+	queries := []string{
+		"CREATE TABLE events_local ON CLUSTER " + informer.Cluster() +
+			" (id UInt64, payload String) ENGINE = MergeTree ORDER BY id",
+		"CREATE TABLE events ON CLUSTER " + informer.Cluster() + " AS events_local ENGINE = Distributed(" +
+			informer.Cluster() + ", " + informer.DatabaseName() + ", events_local, id)",
+		"INSERT INTO events SELECT number, 'x' FROM numbers(100) SETTINGS distributed_foreground_insert = 1",
+	}
+	for _, query := range queries {
+		_, err := conn.Query(t.Context(), query)
+		require.NoError(t, err)
+	}
+
+	data, err := conn.Query(t.Context(), "SELECT count() FROM events")
+	require.NoError(t, err)
+	require.Equal(t, "100", strings.TrimSpace(string(data)))
+
+	data, err = conn.Query(t.Context(), "SELECT count() FROM events_local")
+	require.NoError(t, err)
+	require.NotEqual(t, "100", strings.TrimSpace(string(data)), "rows must be split between shards")
+}
+
+// TestPrepareClickHouseClusterOptions verifies the generated configuration and the supported modes.
+func TestPrepareClickHouseClusterOptions(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		db.driver = clickHouseDriverName
+		db.dsn = "clickhouse://default:se<cret@127.0.0.1:8123/default"
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions(clickHouseDriverName, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("clickhouse/clickhouse-server"), withClickHouseCluster(),
+	}))
+	require.Equal(t, "shard1", db.topologyRole)
+	require.Len(t, db.dockerStartHooks, 1)
+
+	db.topology = &dockerTopology{name: "testdock-abc"} //nolint:exhaustruct // only the name is used.
+	t.Cleanup(func() {
+		if db.topology.configDir != "" {
+			require.NoError(t, os.RemoveAll(db.topology.configDir))
+		}
+	})
+
+	runOptions := &dockertest.RunOptions{} //nolint:exhaustruct // only the mounts are used.
+	for _, f := range db.dockerRunOptions {
+		f(runOptions)
+	}
+	require.Len(t, runOptions.Mounts, 1)
+	path, target, ok := strings.Cut(runOptions.Mounts[0], ":")
+	require.True(t, ok)
+	require.Equal(t, clickHouseNodeConfigPath+":ro", target)
+
+	config, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(config), "<host>testdock-abc-shard1</host>")
+	require.Contains(t, string(config), "<host>testdock-abc-shard2</host>")
+	require.Contains(t, string(config), "<host>testdock-abc-keeper</host>")
+	require.Contains(t, string(config), "<password>se&lt;cret</password>")
+	require.Contains(t, string(config), "<shard>1</shard>")
+	require.Contains(t, db.clickHouseNodeConfig(1), "<shard>2</shard>")
+
+	err = newDB().prepareOptions(clickHouseDriverName, []Option{WithMode(RunModeExternal), withClickHouseCluster()})
+	require.ErrorContains(t, err, "supports only RunModeDocker")
+}
+
+// TestClickHouseConnQuery verifies that queries are sent to the database with the credentials.
+func TestClickHouseConnQuery(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "default", r.Header.Get("X-ClickHouse-User"))
+		require.Equal(t, "secret", r.Header.Get("X-ClickHouse-Key"))
+		require.Equal(t, "t_db", r.URL.Query().Get("database"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if string(body) != "SELECT 1" {
+			http.Error(w, "Code: 62. DB::Exception: Syntax error", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("1\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newExternalTestDB(t)
+	db.driver = clickHouseDriverName
+	db.dsn = strings.Replace(srv.URL, "http://", "clickhouse://default:secret@", 1) + "/default"
+	require.NoError(t, db.prepareOptions(clickHouseDriverName, nil))
+
+	conn := db.clickHouseConn("t_db")
+	data, err := conn.Query(t.Context(), "SELECT 1")
+	require.NoError(t, err)
+	require.Equal(t, "1\n", string(data))
+
+	_, err = conn.Query(t.Context(), "SELEC 1")
+	require.ErrorContains(t, err, "Syntax error")
+}
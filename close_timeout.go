@@ -117,7 +117,7 @@ func (d *testDB) redactedTestDSN() string {
 		return d.dsnNoPass
 	}
 
-	return d.url.replaceDatabase(d.databaseName).string(true)
+	return d.testURL().string(true)
 }
 
 // rawTestDSN returns the temporary database DSN with password for diagnostic redaction only.
@@ -126,7 +126,7 @@ func (d *testDB) rawTestDSN() string {
 		return d.dsn
 	}
 
-	return d.url.replaceDatabase(d.databaseName).string(false)
+	return d.testURL().string(false)
 }
 
 // urlPassword returns the configured password for diagnostic redaction only.
@@ -246,6 +246,8 @@ func runGetPgxPoolCloseTimeoutChild(t *testing.T) {
 }
 
 // newCloseTimeoutOptionTestDB creates a database config that runs option validation only.
+//
+//nolint:exhaustruct // the fields added after the fixture keep their zero values.
 func newCloseTimeoutOptionTestDB() *testDB {
 	return &testDB{
 		t:                         nil,
@@ -0,0 +1,200 @@
+package testdock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ory/dockertest/v3"
+)
+
+// errContainerLeaseUnsupported is returned by lockContainerLease on platforms without file locks.
+var errContainerLeaseUnsupported = errors.New("container leases are not supported on this platform")
+
+// containerLease is the lease of a container which can be used by several test binaries, for example
+// after another test binary adopted it on a port conflict. The lease file in os.TempDir lists the pids
+// of the test binaries which use the container and is guarded by a file lock. The container is purged
+// by the last test binary which releases the lease, so its creator does not remove it while it is still in use.
+// Test binaries attach only to containers with a running holder of the lease.
+type containerLease struct {
+	path string
+	lock *os.File
+	pids []int // running holders of the lease
+}
+
+// containerLeasePath returns the path of the lease file of the container, the lock file has the .lock suffix.
+func containerLeasePath(name string) string {
+	return filepath.Join(os.TempDir(), "testdock-lease-"+strings.TrimPrefix(name, "/")+".json")
+}
+
+// lockContainerLease takes the file lock of the lease of the container with the name and reads
+// the running holders. Holders which have exited without the release of the lease are dropped.
+func lockContainerLease(name string) (*containerLease, error) {
+	if !sharedContainerSupported {
+		return nil, errContainerLeaseUnsupported
+	}
+
+	path := containerLeasePath(name)
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open container lease lock: %w", err)
+	}
+	if err = lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock container lease: %w", err)
+	}
+
+	lease := &containerLease{path: path, lock: f}
+	if data, readErr := os.ReadFile(path); readErr == nil {
+		var pids []int
+		if json.Unmarshal(data, &pids) == nil {
+			for _, pid := range pids {
+				if pid == os.Getpid() || processExists(pid) {
+					lease.pids = append(lease.pids, pid)
+				}
+			}
+		}
+	}
+
+	return lease, nil
+}
+
+// unlock releases the file lock of the lease.
+func (l *containerLease) unlock() {
+	_ = unlockFile(l.lock)
+	_ = l.lock.Close()
+}
+
+// heldByOthers reports whether another running test binary holds the lease.
+func (l *containerLease) heldByOthers() bool {
+	return slices.ContainsFunc(l.pids, func(pid int) bool {
+		return pid != os.Getpid()
+	})
+}
+
+// acquire adds the test binary to the holders of the lease.
+func (l *containerLease) acquire() error {
+	if !slices.Contains(l.pids, os.Getpid()) {
+		l.pids = append(l.pids, os.Getpid())
+	}
+
+	return l.write()
+}
+
+// write saves the holders to the lease file.
+func (l *containerLease) write() error {
+	data, err := json.Marshal(l.pids)
+	if err != nil {
+		return fmt.Errorf("marshal container lease: %w", err)
+	}
+	if err = os.WriteFile(l.path, data, 0o600); err != nil {
+		return fmt.Errorf("write container lease: %w", err)
+	}
+
+	return nil
+}
+
+// release removes the test binary from the holders and reports whether no running holders are left.
+// The lease file is removed with the last holder.
+func (l *containerLease) release() (bool, error) {
+	l.pids = slices.DeleteFunc(l.pids, func(pid int) bool {
+		return pid == os.Getpid()
+	})
+	if len(l.pids) > 0 {
+		return false, l.write()
+	}
+
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return true, fmt.Errorf("remove container lease: %w", err)
+	}
+
+	return true, nil
+}
+
+// errContainerNotLeased is returned when a container of another test binary has no running holder of the lease.
+var errContainerNotLeased = errors.New("the container is not leased by a running test binary")
+
+// join adds the test binary to the lease of a container created by another test binary.
+// A container without running holders is removed by its creator or is left by a killed run, so it is not joined.
+func (l *containerLease) join() error {
+	if !l.heldByOthers() {
+		return errContainerNotLeased
+	}
+
+	return l.acquire()
+}
+
+// leaseNewContainer makes the test binary the holder of the lease of the container it created.
+// The lease of a named container is locked by the caller before the creation, nil locks the lease here.
+// Containers kept for reuse and containers of topologies are not leased.
+// If the lease can not be taken, the container is not shared and is purged by the test binary.
+func (d *testDB) leaseNewContainer(ctx context.Context, info *dockerResourceInfo, logDsn string,
+	lease *containerLease,
+) {
+	if info.foreign || info.topology != nil || d.reuseContainer != "" {
+		return
+	}
+
+	name := info.resource.Container.Name
+	var err error
+	if lease == nil {
+		if lease, err = lockContainerLease(name); err == nil {
+			defer lease.unlock()
+		}
+	}
+	if err == nil {
+		err = lease.acquire()
+	}
+	if err != nil {
+		if !errors.Is(err, errContainerLeaseUnsupported) {
+			d.logger.Info(ctx, "failed to lease the container", "component", "docker", "dsn", logDsn, "error", err)
+		}
+		return
+	}
+
+	info.lease = name
+}
+
+// adoptDockerResource joins the lease of the container of another test binary and uses the container.
+func (d *testDB) adoptDockerResource(info *dockerResourceInfo, resource *dockertest.Resource) error {
+	lease, err := lockContainerLease(resource.Container.Name)
+	if err != nil {
+		return err
+	}
+	defer lease.unlock()
+
+	if err = lease.join(); err != nil {
+		return err
+	}
+
+	info.resource = resource
+	info.foreign = true
+	info.lease = resource.Container.Name
+
+	return nil
+}
+
+// releaseContainerLease releases the lease of the container and reports whether the test binary must purge it:
+// the container is not leased and is not foreign, or no other running test binary holds the lease.
+// The returned function unlocks the lease after the purge, so no test binary joins a removed container.
+func (info *dockerResourceInfo) releaseContainerLease() (bool, func(), error) {
+	if info.lease == "" {
+		return !info.foreign, func() {}, nil
+	}
+
+	lease, err := lockContainerLease(info.lease)
+	if err != nil {
+		// the container is kept, it may still be used by another test binary
+		return false, func() {}, err
+	}
+	info.lease = ""
+
+	last, err := lease.release()
+
+	return last, lease.unlock, err
+}
@@ -0,0 +1,117 @@
+package testdock
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// writeContainerLease writes the holders of the lease of the container for the test.
+func writeContainerLease(t *testing.T, name string, pids ...int) {
+	t.Helper()
+
+	data, err := json.Marshal(pids)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(containerLeasePath(name), data, 0o600))
+}
+
+// newLeaseTestContainerName returns a unique container name and removes its lease files after the test.
+func newLeaseTestContainerName(t *testing.T) string {
+	t.Helper()
+
+	name := "testdock-lease-test-" + uuid.NewString()
+	t.Cleanup(func() {
+		_ = os.Remove(containerLeasePath(name))
+		_ = os.Remove(containerLeasePath(name) + ".lock")
+	})
+
+	return name
+}
+
+// TestContainerLease verifies that the last running holder of the lease purges the container
+// and that a container without running holders is not joined.
+func TestContainerLease(t *testing.T) {
+	t.Parallel()
+
+	// the pid of an exited process
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	exited := cmd.Process.Pid
+
+	name := newLeaseTestContainerName(t)
+	writeContainerLease(t, name, exited)
+
+	lease, err := lockContainerLease(name)
+	require.NoError(t, err)
+	require.Empty(t, lease.pids)
+	require.ErrorIs(t, lease.join(), errContainerNotLeased)
+	lease.unlock()
+
+	// the go command which runs the test binary stands for another test binary
+	writeContainerLease(t, name, os.Getppid(), exited)
+	lease, err = lockContainerLease(name)
+	require.NoError(t, err)
+	require.True(t, lease.heldByOthers())
+	require.NoError(t, lease.join())
+	lease.unlock()
+
+	data, err := os.ReadFile(containerLeasePath(name))
+	require.NoError(t, err)
+	require.JSONEq(t, "["+strconv.Itoa(os.Getppid())+","+strconv.Itoa(os.Getpid())+"]", string(data))
+
+	info := &dockerResourceInfo{lease: name, foreign: true}
+	purge, unlock, err := info.releaseContainerLease()
+	unlock()
+	require.NoError(t, err)
+	require.False(t, purge)
+	require.Empty(t, info.lease)
+
+	// the other holder has exited
+	writeContainerLease(t, name, exited, os.Getpid())
+	info = &dockerResourceInfo{lease: name, foreign: true}
+	purge, unlock, err = info.releaseContainerLease()
+	unlock()
+	require.NoError(t, err)
+	require.True(t, purge)
+	require.NoFileExists(t, containerLeasePath(name))
+}
+
+// TestLeaseNewContainer verifies which containers are leased by their creator.
+func TestLeaseNewContainer(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+
+	name := newLeaseTestContainerName(t)
+	info := &dockerResourceInfo{}
+	info.resource = &dockertest.Resource{Container: &docker.Container{Name: "/" + name}} //nolint:exhaustruct // only the name is used.
+
+	db.leaseNewContainer(t.Context(), info, db.dsn, nil)
+	require.Equal(t, "/"+name, info.lease)
+	lease, err := lockContainerLease(info.lease)
+	require.NoError(t, err)
+	require.Equal(t, []int{os.Getpid()}, lease.pids)
+	lease.unlock()
+
+	purge, unlock, err := info.releaseContainerLease()
+	unlock()
+	require.NoError(t, err)
+	require.True(t, purge)
+
+	// containers kept for reuse are not leased and are not purged
+	db.reuseContainer = "dev"
+	info = &dockerResourceInfo{resource: info.resource, foreign: true}
+	db.leaseNewContainer(t.Context(), info, db.dsn, nil)
+	require.Empty(t, info.lease)
+	purge, unlock, err = info.releaseContainerLease()
+	unlock()
+	require.NoError(t, err)
+	require.False(t, purge)
+}
@@ -0,0 +1,127 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	// defaultFailureLogLines is the default number of container log lines attached to a failed test.
+	defaultFailureLogLines = 100
+	// failureLogsTimeout limits reading of the container logs and state.
+	failureLogsTimeout = 10 * time.Second
+)
+
+// WithFailureLogLines sets the number of the last container log lines which are attached to the output
+// of a failed test together with the state of the container, for example the exit code or OOM kill,
+// so connection retry errors show why the database did not start. The default is 100, zero disables it.
+func WithFailureLogLines(lines int) Option {
+	return func(o *testDB) {
+		o.failureLogLines = lines
+	}
+}
+
+// registerFailureLogs attaches the container diagnostics to the output of the test if it fails.
+// The cleanup is registered after the cleanup of the container, so it runs before the container is removed.
+func (d *testDB) registerFailureLogs() {
+	if d.failureLogLines <= 0 || d.resource == nil {
+		return
+	}
+
+	resource := d.resource
+	d.t.Cleanup(func() {
+		if d.t.Failed() {
+			d.logContainerDiagnostics(resource)
+		}
+	})
+}
+
+// logContainerDiagnostics attaches the container diagnostics to the output of the test.
+func (d *testDB) logContainerDiagnostics(resource *dockertest.Resource) {
+	if d.failureLogLines <= 0 {
+		return
+	}
+
+	d.t.Logf("%s", d.containerDiagnostics(resource))
+}
+
+// containerDiagnostics returns the state and the last log lines of the container.
+// Known passwords are redacted.
+func (d *testDB) containerDiagnostics(resource *dockertest.Resource) string {
+	if globalDockerPool == nil || resource == nil || resource.Container == nil {
+		return "testdock container diagnostics are unavailable"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), failureLogsTimeout)
+	defer cancel()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "testdock container %s (%s:%s, %s):\n", shortContainerID(resource.Container.ID),
+		d.dockerRepository, d.dockerImage, d.dsnNoPass)
+
+	container, err := globalDockerPool.Client.InspectContainerWithContext(resource.Container.ID, ctx)
+	if err != nil {
+		fmt.Fprintf(&b, "inspect failed: %v\n", err)
+	} else {
+		b.WriteString(formatContainerState(container.State))
+	}
+
+	var output bytes.Buffer
+	err = globalDockerPool.Client.Logs(docker.LogsOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:      ctx,
+		Container:    resource.Container.ID,
+		OutputStream: &output,
+		ErrorStream:  &output,
+		Stdout:       true,
+		Stderr:       true,
+		Tail:         strconv.Itoa(d.failureLogLines),
+	})
+	if err != nil {
+		fmt.Fprintf(&b, "logs failed: %v\n", err)
+	} else {
+		fmt.Fprintf(&b, "last %d log lines:\n%s", d.failureLogLines, output.String())
+	}
+
+	return redactKnownSecrets(b.String(), d.urlPassword())
+}
+
+// formatContainerState formats the state of the container reported by docker inspect.
+func formatContainerState(state docker.State) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "state: %s, exit code %d", state.StateString(), state.ExitCode)
+	if state.OOMKilled {
+		b.WriteString(", killed by OOM")
+	}
+	if state.Error != "" {
+		fmt.Fprintf(&b, ", error %q", state.Error)
+	}
+	if state.Health.Status != "" {
+		fmt.Fprintf(&b, ", health %s", state.Health.Status)
+	}
+	if !state.StartedAt.IsZero() {
+		fmt.Fprintf(&b, ", started at %s", state.StartedAt.Format(time.RFC3339))
+	}
+	if !state.FinishedAt.IsZero() {
+		fmt.Fprintf(&b, ", finished at %s", state.FinishedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// shortContainerID returns the short form of the container id used by the docker CLI.
+func shortContainerID(id string) string {
+	const shortLen = 12
+	if len(id) > shortLen {
+		return id[:shortLen]
+	}
+
+	return id
+}
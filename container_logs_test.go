@@ -0,0 +1,42 @@
+package testdock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormatContainerState verifies the state line of the failure diagnostics.
+func TestFormatContainerState(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	state := docker.State{ //nolint:exhaustruct // only the reported fields are set.
+		ExitCode:   137,
+		OOMKilled:  true,
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Minute),
+	}
+	require.Equal(t, "state: exited, exit code 137, killed by OOM, started at 2025-01-02T03:04:05Z, "+
+		"finished at 2025-01-02T03:05:05Z\n", formatContainerState(state))
+
+	state = docker.State{Running: true, StartedAt: started} //nolint:exhaustruct // only the reported fields are set.
+	state.Health.Status = "starting"
+	require.Equal(t, "state: running, exit code 0, health starting, started at 2025-01-02T03:04:05Z\n",
+		formatContainerState(state))
+
+	require.Equal(t, "0123456789ab", shortContainerID("0123456789abcdef"))
+}
+
+// TestWithFailureLogLines verifies the default and disabling of the failure diagnostics.
+func TestWithFailureLogLines(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.Equal(t, defaultFailureLogLines, db.failureLogLines)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithFailureLogLines(0)}))
+	require.Zero(t, db.failureLogLines)
+	require.Equal(t, "testdock container diagnostics are unavailable", db.containerDiagnostics(nil))
+}
@@ -0,0 +1,114 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// containerNameRe is the format of docker container names.
+var containerNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// WithContainerName sets the name of the docker container instead of a random name.
+// A running container with the name, DSN and image of another test binary is shared. Used only in RunModeDocker.
+func WithContainerName(name string) Option {
+	return func(o *testDB) {
+		o.containerName = name
+	}
+}
+
+// prepareContainerNameOptions validates WithContainerName.
+func (d *testDB) prepareContainerNameOptions() error {
+	if d.containerName == "" || d.mode != RunModeDocker {
+		return nil
+	}
+	if !containerNameRe.MatchString(d.containerName) {
+		return fmt.Errorf("invalid container name %q", d.containerName)
+	}
+	if d.topologyRole != "" {
+		return errors.New("container name is not supported for multi-container topologies")
+	}
+
+	return nil
+}
+
+// lockContainerNameLease locks the lease of the container with the name of WithContainerName before
+// its creation, so a test binary which fails to create the container because of the name sees
+// the lease of the creator. Returns nil without the name and for containers which are not leased.
+func (d *testDB) lockContainerNameLease() (*containerLease, error) {
+	if d.containerName == "" || d.reuseContainer != "" {
+		return nil, nil //nolint:nilnil // the container is not leased.
+	}
+
+	lease, err := lockContainerLease(d.containerName)
+	if errors.Is(err, errContainerLeaseUnsupported) {
+		return nil, nil //nolint:nilnil // the container is not leased.
+	}
+
+	return lease, err
+}
+
+// attachNamedContainer attaches to the running container with the name of WithContainerName
+// and the same resource key, after the creation of the container failed because the name is in use.
+// The container is used only while another running test binary holds its lease.
+func (d *testDB) attachNamedContainer(ctx context.Context, info *dockerResourceInfo, logDsn string,
+	lease *containerLease,
+) error {
+	containers, err := globalDockerPool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		All:     true,
+		Filters: map[string][]string{"name": {"^/" + regexp.QuoteMeta(d.containerName) + "$"}},
+	})
+	if err != nil {
+		return fmt.Errorf("list containers with name %s: %w", d.containerName, err)
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("container name %s is in use, but the container is not found", d.containerName)
+	}
+
+	c := containers[0]
+	if c.Labels[dockerLabelKey] != d.dockerResourceLabel() || c.State != "running" {
+		return fmt.Errorf("container name %s is in use by a %s container with another configuration, remove it with: "+
+			"docker rm -f %s", d.containerName, c.State, d.containerName)
+	}
+
+	resource, ok := containerByName(d.containerName)
+	if !ok || resource.Container.ID != c.ID {
+		return fmt.Errorf("inspect container %s", d.containerName)
+	}
+	port, err := strconv.Atoi(resource.GetPort(fmt.Sprintf("%d/tcp", d.dockerPort)))
+	if err != nil {
+		return fmt.Errorf("host port of the container %s: %w", d.containerName, err)
+	}
+
+	if lease == nil {
+		// the container is not leased on this platform or with WithReuseContainer
+		return fmt.Errorf("container name %s is in use by another container, remove it with: docker rm -f %s",
+			d.containerName, d.containerName)
+	}
+	if err = lease.join(); err != nil {
+		return fmt.Errorf("container name %s is in use by a container which no running test binary uses, "+
+			"remove it with: docker rm -f %s: %w", d.containerName, d.containerName, err)
+	}
+
+	info.resource = resource
+	info.foreign = true
+	info.lease = d.containerName
+	d.url.Port = port
+	d.logger.Info(ctx, "container name is in use by the same container, attaching to it", "component", "docker",
+		"dsn", logDsn, "container", strings.TrimPrefix(resource.Container.Name, "/"))
+
+	return nil
+}
+
+// containerByName returns the container with exactly the name.
+// dockertest matches the name by an unanchored regular expression, which also finds containers
+// whose names only contain the name.
+func containerByName(name string) (*dockertest.Resource, bool) {
+	return globalDockerPool.ContainerByName("^" + regexp.QuoteMeta(strings.TrimPrefix(name, "/")) + "$")
+}
@@ -0,0 +1,78 @@
+package testdock
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithContainerName verifies the validation of the container name and that the name is a part of the resource key.
+func TestWithContainerName(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	key := db.dockerResourceKey()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithContainerName("orders-pg_1.test"),
+	}))
+	require.Equal(t, "orders-pg_1.test", db.containerName)
+	require.NotEqual(t, key, db.dockerResourceKey())
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithContainerName("orders pg"),
+	})
+	require.ErrorContains(t, err, `invalid container name "orders pg"`)
+
+	err = newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithContainerName("orders-pg"), withPostgresReplica(),
+	})
+	require.ErrorContains(t, err, "container name is not supported for multi-container topologies")
+}
+
+func Test_PgxContainerName(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5553", 1)
+	_, info := GetPgxPool(t, dsn, WithContainerName("testdock-container-name"))
+
+	dockerInfo, ok := info.(DockerInformer)
+	require.True(t, ok)
+	require.Equal(t, "/testdock-container-name", dockerInfo.Resource().Container.Name)
+
+	// only the exact name is found
+	_, ok = containerByName("testdock-container")
+	require.False(t, ok)
+	_, ok = containerByName("container-name")
+	require.False(t, ok)
+
+	db := newTDB(t.Context(), t, "pgx", dsn, getPostgresOptions(t, "pgx", dsn,
+		WithContainerName("testdock-container-name")))
+	lease, err := lockContainerLease("testdock-container-name")
+	require.NoError(t, err)
+	defer lease.unlock()
+	require.Equal(t, []int{os.Getpid()}, lease.pids)
+
+	// a container without other running test binaries is not attached, for example one left by a killed run
+	err = db.attachNamedContainer(t.Context(), &dockerResourceInfo{}, db.dsnNoPass, lease)
+	require.ErrorContains(t, err, "in use by a container which no running test binary uses")
+
+	// the container of another test binary with the same name and configuration is attached,
+	// the go command stands for the other test binary
+	lease.pids = append(lease.pids, os.Getppid())
+	info2 := &dockerResourceInfo{}
+	require.NoError(t, db.attachNamedContainer(t.Context(), info2, db.dsnNoPass, lease))
+	require.True(t, info2.foreign)
+	require.Equal(t, "testdock-container-name", info2.lease)
+	require.Equal(t, dockerInfo.ContainerID(), info2.resource.Container.ID)
+
+	// the container is removed by the cleanup of the test
+	lease.pids = []int{os.Getpid()}
+	require.NoError(t, lease.write())
+}
@@ -0,0 +1,108 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// WithContainerStopTimeout stops the docker container gracefully with SIGTERM before it is removed
+// and waits up to the timeout for the database to shut down, then the container is killed.
+// By default the container is removed with SIGKILL, which is faster, but can leave the files
+// of mounted volumes inconsistent. Auxiliary containers of topologies are killed. Used only in RunModeDocker.
+func WithContainerStopTimeout(timeout time.Duration) Option {
+	return func(o *testDB) {
+		o.containerStopTimeout = timeout
+	}
+}
+
+// WithKeepContainerOnFailure keeps the docker container running if a test which used it failed
+// or the container did not start. Used only in RunModeDocker.
+func WithKeepContainerOnFailure() Option {
+	return func(o *testDB) {
+		o.keepOnFailure = true
+	}
+}
+
+// WithKeepDatabaseOnFailure keeps the test database if the test failed, so the failing state can be inspected
+// with psql or another client. The full connection string of the test database, including the password,
+// is attached to the output of the test. In RunModeDocker the container is kept too, as with
+// WithKeepContainerOnFailure. Kept databases of RunModeExternal are removed manually.
+func WithKeepDatabaseOnFailure() Option {
+	return func(o *testDB) {
+		o.keepDatabase = true
+		o.keepOnFailure = true
+	}
+}
+
+// keepFailedDatabase reports that the test database of the failed test is kept and tells how to connect to it.
+func (d *testDB) keepFailedDatabase() bool {
+	if !d.keepDatabase || !d.t.Failed() {
+		return false
+	}
+
+	d.t.Logf("testdock test database %s is kept for inspection, connect with: %s", d.databaseName, d.DSN())
+
+	return true
+}
+
+// removeFailedDockerResource purges the container which did not start or keeps it with WithKeepContainerOnFailure.
+func (d *testDB) removeFailedDockerResource(ctx context.Context, info *dockerResourceInfo, logDsn string) {
+	// the container did not start, so it is removed even if another test binary has joined the lease
+	_, unlockLease, _ := info.releaseContainerLease()
+	defer unlockLease()
+
+	if d.keepOnFailure {
+		d.keepFailedDockerResource(info)
+		return
+	}
+
+	d.purgeDockerResource(ctx, info, logDsn)
+}
+
+// keepFailedDockerResource leaves the container running and tells how to remove it.
+func (d *testDB) keepFailedDockerResource(info *dockerResourceInfo) {
+	if info.resource == nil || info.resource.Container == nil {
+		return
+	}
+
+	id := shortContainerID(info.resource.Container.ID)
+	d.t.Logf("testdock container %s is kept for inspection (%s), remove it with: docker rm -f %s", id, d.dsnNoPass, id)
+	if info.topology != nil {
+		d.t.Logf("testdock containers and the network of the topology %s are kept, remove them with: "+
+			"docker rm -f $(docker ps -aq --filter name=%s) && docker network rm %s",
+			info.topology.name, info.topology.name, info.topology.network.Network.Name)
+	}
+}
+
+// stopDockerContainer sends SIGTERM to the container and waits up to the timeout until it exits.
+// The stop signal of dockertest containers is SIGWINCH, so docker stop is not used.
+// A container which is not running or is already removed is not an error.
+func stopDockerContainer(ctx context.Context, resource *dockertest.Resource, timeout time.Duration) error {
+	if resource == nil || resource.Container == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id := resource.Container.ID
+	err := globalDockerPool.Client.KillContainer(docker.KillContainerOptions{ID: id, Signal: docker.SIGTERM, Context: ctx})
+	if err == nil {
+		_, err = globalDockerPool.Client.WaitContainerWithContext(id, ctx)
+	}
+
+	var (
+		notRunning *docker.ContainerNotRunning
+		noSuch     *docker.NoSuchContainer
+	)
+	if err != nil && !errors.As(err, &notRunning) && !errors.As(err, &noSuch) {
+		return fmt.Errorf("stop container %s: %w", shortContainerID(id), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package testdock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContainerStopOptions verifies the stop options and that a kept container is not purged.
+func TestContainerStopOptions(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.Zero(t, db.containerStopTimeout)
+	require.False(t, db.keepOnFailure)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithContainerStopTimeout(5 * time.Second), WithKeepContainerOnFailure(),
+	}))
+	require.Equal(t, 5*time.Second, db.containerStopTimeout)
+	require.True(t, db.keepOnFailure)
+
+	// the pool is not used, so the container is only logged
+	db.t = t
+	info := &dockerResourceInfo{resource: &dockertest.Resource{ //nolint:exhaustruct // only the container is set.
+		Container: &docker.Container{ID: "0123456789abcdef"}, //nolint:exhaustruct // only the ID is used.
+	}}
+	db.removeFailedDockerResource(t.Context(), info, db.dsnNoPass)
+	require.NotNil(t, info.resource)
+	require.NoError(t, stopDockerContainer(t.Context(), nil, time.Second))
+}
+
+func Test_PgxContainerStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	var id string
+	t.Run("graceful stop", func(t *testing.T) {
+		dsn := strings.Replace(DefaultPostgresDSN, "5432", "5552", 1)
+		_, info := GetPgxPool(t, dsn, WithContainerStopTimeout(10*time.Second))
+		dockerInfo, ok := info.(DockerInformer)
+		require.True(t, ok)
+		id = dockerInfo.ContainerID()
+	})
+
+	pool, err := migratorDockerPool()
+	require.NoError(t, err)
+	_, err = pool.Client.InspectContainer(id)
+	var noSuchContainer *docker.NoSuchContainer
+	require.ErrorAs(t, err, &noSuchContainer)
+}
+
+// failedTB is a failed test which records its log.
+type failedTB struct {
+	testing.TB
+	logs []string
+}
+
+func (tb *failedTB) Failed() bool { return true }
+
+func (tb *failedTB) Logf(format string, args ...any) {
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+// TestKeepDatabaseOnFailure verifies that the database of a failed test is kept and its DSN is logged.
+func TestKeepDatabaseOnFailure(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.False(t, db.keepFailedDatabase())
+
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithKeepDatabaseOnFailure()}))
+	require.True(t, db.keepDatabase)
+	require.True(t, db.keepOnFailure)
+	require.False(t, db.keepFailedDatabase())
+
+	failed := &failedTB{TB: t, logs: nil}
+	db.t = failed
+	require.True(t, db.keepFailedDatabase())
+	require.Len(t, failed.logs, 1)
+	require.Contains(t, failed.logs[0], db.DSN())
+}
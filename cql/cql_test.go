@@ -0,0 +1,83 @@
+package cql
+
+import (
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitStatements verifies that separators in strings and comments are ignored.
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	script := `-- schema; with a comment
+CREATE TABLE a (id int PRIMARY KEY, "odd;name" text); // trailing; comment
+INSERT INTO a (id, "odd;name") VALUES (1, 'it''s; fine');
+/* block;
+comment */ CREATE FUNCTION f() RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS $$ return 1; $$;
+`
+	require.Equal(t, []string{
+		`CREATE TABLE a (id int PRIMARY KEY, "odd;name" text)`,
+		`INSERT INTO a (id, "odd;name") VALUES (1, 'it''s; fine')`,
+		`CREATE FUNCTION f() RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS $$ return 1; $$`,
+	}, splitStatements(script))
+	require.Empty(t, splitStatements("-- only a comment\n"))
+}
+
+func Test_CQLMigrations(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{ //nolint:exhaustruct // defaults of the image.
+		Repository: "scylladb/scylla",
+		Tag:        "6.2",
+		Cmd:        []string{"--smp", "1", "--memory", "512M", "--developer-mode", "1"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pool.Purge(resource) })
+
+	dsn := "cassandra://" + resource.GetHostPort("9042/tcp") + "/testks"
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+	pool.MaxWait = 3 * time.Minute
+	require.NoError(t, pool.Retry(func() error {
+		migrator, factoryErr := MigrateFactory(t, dsn, "migrations", logger)
+		if factoryErr != nil {
+			return factoryErr
+		}
+		//nolint:forcetypeassert // CQL migrator is versioned.
+		return migrator.(testdock.VersionedMigrator).UpTo(t.Context(), 1)
+	}))
+
+	// only the new migration is applied
+	migrator, err := MigrateFactory(t, dsn, "migrations", logger)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(t.Context()))
+
+	cluster := gocql.NewCluster(resource.GetHostPort("9042/tcp"))
+	cluster.Keyspace = "testks"
+	session, err := cluster.CreateSession()
+	require.NoError(t, err)
+	defer session.Close()
+
+	var name string
+	require.NoError(t, session.Query("SELECT name FROM test_table WHERE id = 1").ScanContext(t.Context(), &name))
+	require.Equal(t, "it's; fine", name)
+
+	var versions int
+	require.NoError(t, session.Query("SELECT count(*) FROM schema_version").ScanContext(t.Context(), &versions))
+	require.Equal(t, 2, versions)
+}
+
+// TestMigrateFactory verifies the validation of the DSN.
+func TestMigrateFactory(t *testing.T) {
+	t.Parallel()
+
+	_, err := MigrateFactory(t, "cassandra://127.0.0.1:9042", "migrations", ctxlog.Must(ctxlog.WithTesting(t)))
+	require.ErrorContains(t, err, "dsn must contain the keyspace")
+}
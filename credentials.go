@@ -0,0 +1,139 @@
+package testdock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RotatePassword changes the password of the test-scoped user on the live database
+// and returns the connection string with the new password.
+func (d *testDB) RotatePassword(ctx context.Context) (string, error) {
+	if d.driver != oracleDriverName && d.driver != "mysql" && !isPostgresDriver(d.driver) {
+		return "", fmt.Errorf("password rotation is not supported for driver %s", d.driver)
+	}
+
+	password, err := newPassword()
+	if err != nil {
+		return "", err
+	}
+
+	// Oracle schema user is changed by the administrator connected to the service,
+	// other engines manage users from the test database.
+	db, err := d.connectSQLDB(ctx, d.driver != oracleDriverName)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	user := d.rotateUser
+	if d.driver == oracleDriverName {
+		user = d.databaseName
+	}
+
+	var queries []string
+	if user == "" {
+		user = "u_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:16]
+		queries = d.createUserQueries(user, password)
+	} else {
+		queries = []string{d.alterPasswordQuery(user, password)}
+	}
+
+	for _, query := range queries {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return "", fmt.Errorf("rotate password: %w", err)
+		}
+	}
+
+	if d.driver != oracleDriverName && d.rotateUser == "" {
+		d.rotateUser = user
+		d.t.Cleanup(func() {
+			if dropErr := d.dropRotateUser(context.Background()); dropErr != nil {
+				d.logger.Info(context.Background(), "failed to drop test user", "dsn", d.dsnNoPass, "error", dropErr)
+			}
+		})
+	}
+
+	d.logger.Info(ctx, "password rotated", "dsn", d.dsnNoPass, "user", user)
+
+	url := d.testURL()
+	url.User = user
+	url.Password = password
+
+	return url.string(false), nil
+}
+
+// createUserQueries returns the queries which create the test-scoped user with full privileges on the test database.
+func (d *testDB) createUserQueries(user, password string) []string {
+	if d.driver == "mysql" {
+		return []string{
+			fmt.Sprintf("CREATE USER '%s'@'%%' IDENTIFIED BY '%s'", user, password),
+			fmt.Sprintf("GRANT ALL PRIVILEGES ON `%s`.* TO '%s'@'%%'", d.databaseName, user),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD '%s'", user, password),
+		fmt.Sprintf("GRANT ALL PRIVILEGES ON DATABASE %s TO %s", d.databaseName, user),
+		fmt.Sprintf("GRANT ALL ON SCHEMA public TO %s", user),
+		fmt.Sprintf("GRANT ALL ON ALL TABLES IN SCHEMA public TO %s", user),
+		fmt.Sprintf("GRANT ALL ON ALL SEQUENCES IN SCHEMA public TO %s", user),
+	}
+}
+
+// alterPasswordQuery returns the query which changes the password of the user.
+func (d *testDB) alterPasswordQuery(user, password string) string {
+	switch {
+	case d.driver == oracleDriverName:
+		return fmt.Sprintf(`ALTER USER %s IDENTIFIED BY "%s"`, user, password)
+	case d.driver == "mysql":
+		return fmt.Sprintf("ALTER USER '%s'@'%%' IDENTIFIED BY '%s'", user, password)
+	default:
+		return fmt.Sprintf("ALTER ROLE %s PASSWORD '%s'", user, password)
+	}
+}
+
+// dropRotateUser removes the test-scoped user created by RotatePassword.
+func (d *testDB) dropRotateUser(ctx context.Context) error {
+	db, err := d.connectSQLDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	for _, query := range d.dropUserQueries() {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("drop test user: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dropUserQueries returns the queries which remove the test-scoped user and its privileges.
+func (d *testDB) dropUserQueries() []string {
+	if d.driver == "mysql" {
+		return []string{fmt.Sprintf("DROP USER '%s'@'%%'", d.rotateUser)}
+	}
+
+	return []string{
+		fmt.Sprintf("DROP OWNED BY %s", d.rotateUser),
+		fmt.Sprintf("DROP ROLE %s", d.rotateUser),
+	}
+}
+
+// newPassword returns a random password which starts with a letter, as required by Oracle.
+func newPassword() (string, error) {
+	const passwordBytes = 12
+
+	b := make([]byte, passwordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate password: %w", err)
+	}
+
+	return "P" + hex.EncodeToString(b), nil
+}
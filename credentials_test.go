@@ -0,0 +1,81 @@
+package testdock
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotatePassword(t *testing.T) {
+	t.Parallel()
+
+	_, informer := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+	)
+
+	dbInfo, ok := informer.(DatabaseInformer)
+	require.True(t, ok)
+	firstDSN, err := dbInfo.RotatePassword(t.Context())
+	require.NoError(t, err)
+	requireRotatedConnection(t, firstDSN, true)
+
+	secondDSN, err := dbInfo.RotatePassword(t.Context())
+	require.NoError(t, err)
+	require.NotEqual(t, firstDSN, secondDSN)
+	requireRotatedConnection(t, secondDSN, true)
+	requireRotatedConnection(t, firstDSN, false)
+}
+
+// requireRotatedConnection checks whether the connection string can be used to read the test table.
+func requireRotatedConnection(t *testing.T, dsn string, valid bool) {
+	t.Helper()
+
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	var name string
+	err = db.QueryRowContext(t.Context(), "SELECT name FROM test_table").Scan(&name)
+	if !valid {
+		require.Error(t, err)
+		return
+	}
+	require.NoError(t, err)
+	require.Equal(t, "test", name)
+}
+
+// TestRotatePasswordUnsupportedDriver verifies the error for engines without users.
+func TestRotatePasswordUnsupportedDriver(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.driver = mongoDriverName
+
+	_, err := db.RotatePassword(t.Context())
+	require.ErrorContains(t, err, "not supported for driver mongodb")
+}
+
+// TestRotatePasswordQueries verifies the queries of the test-scoped user.
+func TestRotatePasswordQueries(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.databaseName = "t_db"
+	db.rotateUser = "u_test"
+
+	require.Equal(t, "ALTER ROLE u_test PASSWORD 'secret'", db.alterPasswordQuery("u_test", "secret"))
+	require.Equal(t, []string{"DROP OWNED BY u_test", "DROP ROLE u_test"}, db.dropUserQueries())
+
+	db.driver = "mysql"
+	require.Equal(t, "GRANT ALL PRIVILEGES ON `t_db`.* TO 'u_test'@'%'", db.createUserQueries("u_test", "secret")[1])
+	require.Equal(t, []string{"DROP USER 'u_test'@'%'"}, db.dropUserQueries())
+
+	db.driver = oracleDriverName
+	require.Equal(t, `ALTER USER u_test IDENTIFIED BY "secret"`, db.alterPasswordQuery("u_test", "secret"))
+
+	password, err := newPassword()
+	require.NoError(t, err)
+	require.Len(t, password, 25)
+}
@@ -0,0 +1,235 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+)
+
+// csvSeedCounter makes names of the MySQL reader handlers unique.
+var csvSeedCounter atomic.Int64
+
+// WithCSVSeed bulk-loads CSV files with a header into the existing tables after migrations.
+// The keys are table names, the values are paths to the files.
+func WithCSVSeed(files map[string]string) Option {
+	tables := make([]string, 0, len(files))
+	for table := range files {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+
+	return func(o *testDB) {
+		o.seeds = append(o.seeds, func(ctx context.Context, db *sql.DB) error {
+			for _, table := range tables {
+				if err := loadCSVSeed(ctx, db, table, files[table]); err != nil {
+					return fmt.Errorf("load %s into %s: %w", files[table], table, err)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// loadCSVSeed loads the CSV file into the table with the bulk-load command of the database.
+func loadCSVSeed(ctx context.Context, db *sql.DB, table, path string) error {
+	columns, err := csvHeader(path)
+	if err != nil {
+		return err
+	}
+
+	switch db.Driver().(type) {
+	case *stdlib.Driver:
+		return copyCSVPgx(ctx, db, table, path, columns)
+	case *pq.Driver:
+		return copyCSVPq(ctx, db, table, path, columns)
+	case *mysql.MySQLDriver:
+		return loadCSVMySQL(ctx, db, table, path, columns)
+	default:
+		return fmt.Errorf("CSV seed is not supported for driver %T", db.Driver())
+	}
+}
+
+// csvHeader returns the column names from the header of the CSV file.
+func csvHeader(path string) ([]string, error) {
+	f, err := os.Open(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		return nil, fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file.
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv header not found")
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	return header, nil
+}
+
+// copyCSVPgx loads the file with COPY FROM STDIN of the pgx connection.
+func copyCSVPgx(ctx context.Context, db *sql.DB, table, path string, columns []string) error {
+	f, err := os.Open(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		return fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file.
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // Close only returns the connection to the pool.
+
+	query := fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv, HEADER true)",
+		pgx.Identifier(strings.Split(table, ".")).Sanitize(), quotePgxColumns(columns))
+
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected pgx connection %T", driverConn)
+		}
+		if _, copyErr := pgxConn.Conn().PgConn().CopyFrom(ctx, f, query); copyErr != nil {
+			return fmt.Errorf("copy from: %w", copyErr)
+		}
+		return nil
+	})
+}
+
+// quotePgxColumns returns the comma-separated list of quoted column names.
+func quotePgxColumns(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, pgx.Identifier{column}.Sanitize())
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// copyCSVPq loads the file with the COPY FROM STDIN support of the pq driver in a transaction.
+func copyCSVPq(ctx context.Context, db *sql.DB, table, path string, columns []string) error {
+	f, err := os.Open(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		return fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file.
+
+	reader := csv.NewReader(f)
+	reader.ReuseRecord = true
+	if _, err = reader.Read(); err != nil {
+		return fmt.Errorf("read csv header: %w", err)
+	}
+
+	query := pq.CopyIn(table, columns...)
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		query = pq.CopyInSchema(schema, name, columns...)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op.
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("prepare copy: %w", err)
+	}
+
+	values := make([]any, len(columns))
+	for {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("read csv: %w", readErr)
+		}
+
+		for i, value := range record {
+			if value == "" {
+				values[i] = nil
+			} else {
+				values[i] = value
+			}
+		}
+		if _, err = stmt.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("copy row: %w", err)
+		}
+	}
+
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("copy from: %w", err)
+	}
+	if err = stmt.Close(); err != nil {
+		return fmt.Errorf("close copy: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// loadCSVMySQL loads the file with LOAD DATA LOCAL INFILE through a registered reader handler.
+func loadCSVMySQL(ctx context.Context, db *sql.DB, table, path string, columns []string) error {
+	if err := enableMySQLLocalInfile(ctx, db); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		return fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file.
+
+	name := fmt.Sprintf("testdock_csv_%d", csvSeedCounter.Add(1))
+	mysql.RegisterReaderHandler(name, func() io.Reader { return f })
+	defer mysql.DeregisterReaderHandler(name)
+
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, "`"+strings.ReplaceAll(column, "`", "``")+"`")
+	}
+
+	query := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE `%s` CHARACTER SET utf8mb4 "+
+		"FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' "+
+		"LINES TERMINATED BY '\\n' IGNORE 1 LINES (%s)",
+		name, strings.ReplaceAll(table, "`", "``"), strings.Join(quoted, ", "))
+	if _, err = db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("load data: %w", err)
+	}
+
+	return nil
+}
+
+// enableMySQLLocalInfile enables local_infile on the server if it is disabled.
+func enableMySQLLocalInfile(ctx context.Context, db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.local_infile").Scan(&enabled); err != nil {
+		return fmt.Errorf("check local_infile: %w", err)
+	}
+	if enabled {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, "SET GLOBAL local_infile = 1"); err != nil {
+		return fmt.Errorf("local_infile is disabled on the server and cannot be enabled: %w", err)
+	}
+
+	return nil
+}
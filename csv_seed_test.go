@@ -0,0 +1,94 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testCSVSeed = map[string]string{"test_table": "migrations/pg/csv/test_table.csv"}
+
+func Test_PgxCSVSeed(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithCSVSeed(testCSVSeed),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireTestTableNames(t, db, "test", "csv1", "csv, 2")
+}
+
+func Test_LibPGCSVSeed(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPqConn(context.Background(), t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPQ),
+		WithCSVSeed(testCSVSeed),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireSQLTestTableNames(t, db, "test", "csv1", "csv, 2")
+}
+
+func Test_MySQLCSVSeed(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetMySQLConn(t,
+		DefaultMySQLDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryMySQL),
+		WithCSVSeed(testCSVSeed),
+	)
+
+	requireSQLTestTableNames(t, db, "test", "csv1", "csv, 2")
+}
+
+// requireSQLTestTableNames checks the names in test_table in the insertion order.
+func requireSQLTestTableNames(t *testing.T, db *sql.DB, want ...string) {
+	t.Helper()
+
+	rows, err := db.QueryContext(t.Context(), "SELECT name FROM test_table ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close() //nolint:errcheck // read-only query.
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, want, names)
+}
+
+// TestCSVSeed verifies the header parsing and that other drivers are rejected.
+func TestCSVSeed(t *testing.T) {
+	t.Parallel()
+
+	columns, err := csvHeader("migrations/pg/csv/test_table.csv")
+	require.NoError(t, err)
+	require.Equal(t, []string{"name"}, columns)
+	require.Equal(t, `"name", "Full ""Name"""`, quotePgxColumns([]string{"name", `Full "Name"`}))
+
+	empty := filepath.Join(t.TempDir(), "empty.csv")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err = csvHeader(empty)
+	require.ErrorContains(t, err, "csv header not found")
+
+	registerScriptTestDriver()
+	conn, err := sql.Open("testdock_script", "csv")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	db := newExternalTestDB(t)
+	WithCSVSeed(testCSVSeed)(db)
+	require.Len(t, db.seeds, 1)
+	require.ErrorContains(t, db.seeds[0](context.Background(), conn), "CSV seed is not supported for driver")
+}
@@ -0,0 +1,48 @@
+package testdock
+
+import (
+	"context"
+	"testing"
+)
+
+// DatabaseInformer changes the state of the live test database, for example to reset data between subtests
+// or to test credential reload logic. The informers returned by the Get... functions implement it,
+// use a type assertion:
+//
+//	dbInfo := info.(testdock.DatabaseInformer)
+//	err := dbInfo.TruncateAll(ctx)
+type DatabaseInformer interface {
+	Informer
+	// RotatePassword changes the password of the test-scoped user on the live database
+	// and returns the connection string with the new password.
+	// The first call creates the test-scoped user with full privileges on the test database,
+	// the user is removed after the test. For Oracle, the password of the schema user is changed.
+	// DSN keeps returning the original connection string.
+	// Supported for PostgreSQL, MySQL compatible databases and Oracle.
+	RotatePassword(ctx context.Context) (string, error)
+	// ApplyMigrations applies all pending migrations of the directory to the test database,
+	// so a test can add its own schema on top of the schema created by WithMigrations.
+	// The helper fails tb on migrator creation or migration errors.
+	ApplyMigrations(tb testing.TB, migrationsDir string, migrateFactory MigrateFactory)
+	// TruncateAll removes the rows of all tables of the test database, so subtests can share
+	// one migrated database and reset the data between cases. The listed tables are kept,
+	// PostgreSQL table names can be qualified with the schema.
+	// The version tables of goose, golang-migrate, Flyway and Atlas, the checksums of WithMigrationChecksums
+	// and the tables of PostgreSQL extensions, like spatial_ref_sys of PostGIS, are kept.
+	// PostgreSQL uses TRUNCATE ... RESTART IDENTITY CASCADE, so kept tables which reference emptied tables
+	// by foreign keys are emptied too. MySQL compatible databases truncate tables with FOREIGN_KEY_CHECKS=0.
+	// Supported for PostgreSQL and MySQL compatible databases.
+	TruncateAll(ctx context.Context, exceptTables ...string) error
+	// Snapshot saves the state of the test database under the name, so a test can capture a known-good state
+	// after expensive setup and return to it with Restore between cases. A snapshot with the same name is replaced.
+	// PostgreSQL snapshots are pg_dump dumps of the schema and data, taken with pg_dump from the container
+	// in RunModeDocker and with pg_dump from PATH otherwise. MySQL compatible snapshots are copies of the tables
+	// in a database on the same server, which is removed after the test.
+	// Supported for PostgreSQL and MySQL compatible databases.
+	Snapshot(ctx context.Context, name string) error
+	// Restore returns the test database to the state saved by Snapshot.
+	// PostgreSQL drops all user schemas and restores the dump, so objects created after the snapshot are removed.
+	// MySQL compatible databases restore the rows of the tables, tables created after the snapshot are emptied.
+	// Open transactions of the test block the restore.
+	Restore(ctx context.Context, name string) error
+}
@@ -0,0 +1,85 @@
+package testdock
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxDatabaseNameLength is the length limit of unquoted identifiers in PostgreSQL, the shortest of the engines.
+	maxDatabaseNameLength = 63
+	// maxDatabaseNamePrefixLength leaves room for the unique suffix of WithDatabaseNamePrefix.
+	maxDatabaseNamePrefixLength = 40
+	// databaseNameSuffixLength is the number of hex digits of the unique suffix of WithDatabaseNamePrefix.
+	databaseNameSuffixLength = 8
+)
+
+var (
+	// databaseNameRe is the format of names of WithDatabaseName, which are used without quotes.
+	databaseNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// databaseNamePrefixInvalidRe matches the characters replaced in prefixes of WithDatabaseNamePrefix.
+	databaseNamePrefixInvalidRe = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// WithDatabaseName sets the name of the test database.
+// The default is the generated t_<time>_<uuid>.
+func WithDatabaseName(name string) Option {
+	return func(o *testDB) {
+		o.fixedDatabaseName = name
+	}
+}
+
+// WithDatabaseNamePrefix generates the name of the test database from the prefix and a short unique suffix.
+// The default is the generated t_<time>_<uuid>.
+func WithDatabaseNamePrefix(prefix string) Option {
+	return func(o *testDB) {
+		o.databaseNamePrefix = prefix
+	}
+}
+
+// prepareDatabaseNameOptions validates WithDatabaseName and normalizes the prefix of WithDatabaseNamePrefix.
+func (d *testDB) prepareDatabaseNameOptions() error {
+	if d.fixedDatabaseName != "" {
+		switch {
+		case d.databaseNamePrefix != "":
+			return errors.New("WithDatabaseName can not be used with WithDatabaseNamePrefix")
+		case !databaseNameRe.MatchString(d.fixedDatabaseName) || len(d.fixedDatabaseName) > maxDatabaseNameLength:
+			return fmt.Errorf("invalid database name %q", d.fixedDatabaseName)
+		case d.databasePoolSize > 0:
+			return errors.New("WithDatabaseName can not be used with WithDatabasePoolSize")
+		case d.noTestDatabase:
+			return errors.New("WithDatabaseName can not be used with WithNoCreateDatabase")
+		}
+	}
+
+	if d.databaseNamePrefix != "" {
+		prefix := databaseNamePrefixInvalidRe.ReplaceAllString(strings.ToLower(d.databaseNamePrefix), "_")
+		prefix = strings.Trim(prefix[:min(len(prefix), maxDatabaseNamePrefixLength)], "_")
+		if prefix == "" {
+			return fmt.Errorf("invalid database name prefix %q", d.databaseNamePrefix)
+		}
+		if prefix[0] >= '0' && prefix[0] <= '9' {
+			prefix = "t_" + prefix
+		}
+		d.databaseNamePrefix = prefix
+	}
+
+	return nil
+}
+
+// newTestDatabaseName returns the name of a new test database.
+func (d *testDB) newTestDatabaseName() string {
+	switch {
+	case d.fixedDatabaseName != "":
+		return d.fixedDatabaseName
+	case d.databaseNamePrefix != "":
+		suffix := strings.ReplaceAll(uuid.NewString(), "-", "")[:databaseNameSuffixLength]
+		return d.databaseNamePrefix + "_" + suffix
+	}
+
+	return newDatabaseName()
+}
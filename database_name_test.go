@@ -0,0 +1,44 @@
+package testdock
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDatabaseNameOptions verifies the names of test databases of WithDatabaseName and WithDatabaseNamePrefix.
+func TestDatabaseNameOptions(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithDatabaseName("orders_test")}))
+	require.Equal(t, "orders_test", db.databaseName)
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithDatabaseNamePrefix("TestOrders/create-2")}))
+	require.Regexp(t, regexp.MustCompile(`^testorders_create_2_[0-9a-f]{8}$`), db.databaseName)
+	require.NotEqual(t, db.databaseName, db.newTestDatabaseName())
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithDatabaseNamePrefix("2fa")}))
+	require.Regexp(t, regexp.MustCompile(`^t_2fa_[0-9a-f]{8}$`), db.databaseName)
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithDatabaseNamePrefix("TestAVeryLongNameOfTheTestWhichDoesNotFitIntoTheLimit"),
+	}))
+	require.LessOrEqual(t, len(db.databaseName), maxDatabaseNameLength)
+
+	for name, opts := range map[string][]Option{
+		"invalid database name":        []Option{WithDatabaseName("orders-test")},
+		"invalid database name prefix": []Option{WithDatabaseNamePrefix("/-/")},
+		"can not be used with WithDatabaseNamePrefix": []Option{
+			WithDatabaseName("orders"), WithDatabaseNamePrefix("orders"),
+		},
+		"can not be used with WithNoCreateDatabase": []Option{WithDatabaseName("orders"), WithNoCreateDatabase()},
+	} {
+		db = newExternalTestDB(t)
+		require.ErrorContains(t, db.prepareOptions(db.driver, opts), name)
+	}
+}
@@ -0,0 +1,269 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// we ensure the tests with the same server and migrations share one pool of prepared databases.
+//
+//nolint:gochecknoglobals // used to share prepared test databases across tests.
+var (
+	globalDatabasePoolMu sync.Mutex
+	globalDatabasePools  = make(map[string]*databasePool)
+)
+
+// databasePool holds the test databases created and migrated in advance by WithDatabasePoolSize.
+type databasePool struct {
+	ready  chan pooledDatabase // prepared databases, the buffer is the size of the pool
+	driver string              // database driver
+	dsn    string              // connection string of the server, empty if the databases are removed with the container
+	logDsn string              // connection string of the server without password
+	users  int                 // tests which use the pool, guarded by globalDatabasePoolMu
+	closed bool                // the pool is removed and is not refilled, guarded by globalDatabasePoolMu
+}
+
+// pooledDatabase is a prepared test database or the error of its preparation.
+type pooledDatabase struct {
+	name string
+	err  error
+}
+
+// WithDatabasePoolSize keeps n test databases created, initialized and migrated in advance.
+// The default is 0, the database is created by the test.
+func WithDatabasePoolSize(n int) Option {
+	return func(o *testDB) {
+		o.databasePoolSize = n
+	}
+}
+
+// prepareDatabasePoolOptions validates WithDatabasePoolSize.
+func (d *testDB) prepareDatabasePoolOptions() error {
+	if d.databasePoolSize == 0 {
+		return nil
+	}
+
+	switch {
+	case d.databasePoolSize < 0:
+		return errors.New("database pool size must be greater than 0")
+	case !isSQLDriver(d.driver) || d.driver == oracleDriverName:
+		return fmt.Errorf("WithDatabasePoolSize is not supported for driver %s", d.driver)
+	case d.mode != RunModeDocker && d.mode != RunModeExternal:
+		return errors.New("WithDatabasePoolSize is supported only in RunModeDocker and RunModeExternal")
+	case d.noTestDatabase:
+		return errors.New("WithDatabasePoolSize can not be used without a test database")
+	case d.artifactMode != ArtifactModeOff:
+		return errors.New("WithDatabasePoolSize can not be used with WithArtifact")
+	case d.maxTestDatabases > 0:
+		return errors.New("WithDatabasePoolSize can not be used with WithMaxConcurrentTestDatabases")
+	}
+
+	return nil
+}
+
+// takePooledDatabase takes a prepared test database from the pool and starts the preparation of its replacement.
+func (d *testDB) takePooledDatabase(ctx context.Context) error {
+	if d.databasePoolSize == 0 {
+		return nil
+	}
+	if err := d.extractMigrationsFS(); err != nil {
+		return err
+	}
+
+	key, err := d.migratedDatabaseKey()
+	if err != nil {
+		return err
+	}
+
+	globalDatabasePoolMu.Lock()
+	pool, ok := globalDatabasePools[key]
+	if !ok {
+		pool = &databasePool{
+			ready:  make(chan pooledDatabase, d.databasePoolSize),
+			driver: d.driver,
+			dsn:    "",
+			logDsn: d.dsnNoPass,
+			users:  0,
+			closed: false,
+		}
+		// databases of a purged container are lost with it
+		if d.mode == RunModeExternal || d.containerKept {
+			pool.dsn = d.url.string(false)
+		}
+		globalDatabasePools[key] = pool
+	}
+	pool.users++
+	globalDatabasePoolMu.Unlock()
+
+	// the cleanup runs after the preparation of the databases started by the test
+	d.t.Cleanup(func() { d.releaseDatabasePool(key, pool) })
+
+	// migrators of the preparation log to the test, so the test waits for the databases it started
+	var wg sync.WaitGroup
+	d.t.Cleanup(wg.Wait)
+
+	if !ok {
+		d.logger.Info(ctx, "filling test database pool", "dsn", d.dsnNoPass, "size", d.databasePoolSize)
+		for range d.databasePoolSize {
+			d.fillDatabasePool(pool, &wg)
+		}
+	}
+
+	var db pooledDatabase
+	select {
+	case db = <-pool.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	d.fillDatabasePool(pool, &wg)
+
+	if db.err != nil {
+		return fmt.Errorf("prepare pooled database: %w", db.err)
+	}
+
+	d.databaseName = db.name
+	d.pooledDatabase = true
+	d.logger.Info(ctx, "using pooled test database", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	return nil
+}
+
+// migratedDatabaseKey returns the key of the pooled and shared databases
+// from the server and the hash of the migrations.
+func (d *testDB) migratedDatabaseKey() (string, error) {
+	sum, err := d.migrationsHash()
+	if err != nil {
+		return "", err
+	}
+
+	key := d.url.string(false) + " " + d.templateName + " " + sum
+	if d.resource != nil && d.resource.Container != nil {
+		// databases of a removed container are lost with it
+		key += " " + d.resource.Container.ID
+	}
+
+	return key, nil
+}
+
+// releaseDatabasePool drops the databases left in the pool after the last test which uses it.
+func (d *testDB) releaseDatabasePool(key string, pool *databasePool) {
+	globalDatabasePoolMu.Lock()
+	pool.users--
+	last := pool.users == 0 && !pool.closed
+	if last {
+		pool.closed = true
+		delete(globalDatabasePools, key)
+	}
+	globalDatabasePoolMu.Unlock()
+
+	if !last {
+		return
+	}
+
+	ctx := context.Background()
+	if err := pool.remove(ctx); err != nil {
+		d.logger.Info(ctx, "failed to remove pooled test databases", "dsn", pool.logDsn, "error", err)
+	} else {
+		d.logger.Info(ctx, "test database pool removed", "dsn", pool.logDsn)
+	}
+}
+
+// fillDatabasePool prepares one test database for the pool in the background.
+// A removed pool is not refilled.
+func (d *testDB) fillDatabasePool(pool *databasePool, wg *sync.WaitGroup) {
+	globalDatabasePoolMu.Lock()
+	closed := pool.closed
+	globalDatabasePoolMu.Unlock()
+	if closed {
+		return
+	}
+
+	prepared := d.poolPreparation()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		err := prepared.preparePooledDatabase(context.Background())
+		pool.ready <- pooledDatabase{name: prepared.databaseName, err: err}
+	}()
+}
+
+// poolPreparation returns a copy of the test database for the preparation of a pooled database in the background.
+// The slices read by the preparation are copied, the state of the test which is not used by the preparation is reset.
+func (d *testDB) poolPreparation() *testDB {
+	prepared := *d
+	prepared.databaseName = d.newTestDatabaseName()
+	url := *d.url
+	prepared.url = &url
+	prepared.initQueries = slices.Clone(d.initQueries)
+	prepared.extraMigrations = slices.Clone(d.extraMigrations)
+	prepared.prepareCleanUp = slices.Clone(d.prepareCleanUp)
+	prepared.shared = nil
+	prepared.releaseSlot = nil
+	prepared.seeds = nil
+	prepared.mongoFixtures = nil
+	prepared.snapshots = nil
+	prepared.dockerEnv = nil
+	prepared.dockerMounts = nil
+	prepared.dockerLabels = nil
+	prepared.dockerStartHooks = nil
+
+	return &prepared
+}
+
+// preparePooledDatabase creates, initializes and migrates a test database of the pool.
+// The database is removed if its initialization or migrations fail.
+func (d *testDB) preparePooledDatabase(ctx context.Context) error {
+	if err := d.createTestDatabase(ctx); err != nil {
+		return err
+	}
+	if d.templateName != "" {
+		return nil
+	}
+
+	err := d.initTestDatabase(ctx)
+	if err == nil && (d.migrationsDir != "" || len(d.extraMigrations) > 0) {
+		err = d.migrationsUp(ctx)
+	}
+	if err != nil {
+		if closeErr := d.close(ctx); closeErr != nil {
+			d.logger.Info(ctx, "failed to remove pooled test database", "dsn", d.dsnNoPass, "error", closeErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// remove drops the prepared databases left in the pool.
+func (p *databasePool) remove(ctx context.Context) error {
+	if p.dsn == "" {
+		return nil
+	}
+
+	db, err := sql.Open(p.driver, p.dsn)
+	if err != nil {
+		return fmt.Errorf("sql open url: %w", err)
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	var errs []error
+	for {
+		select {
+		case pooled := <-p.ready:
+			if pooled.err != nil {
+				continue
+			}
+			if _, err = db.ExecContext(ctx, "DROP DATABASE "+pooled.name); err != nil {
+				errs = append(errs, fmt.Errorf("drop pooled database %s: %w", pooled.name, err))
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package testdock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxDatabasePool(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5556", 1)
+	options := []Option{
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDatabasePoolSize(2),
+		WithDockerImage(testPostgresImage),
+	}
+
+	databases := make(map[string]bool)
+	var key string
+	t.Run("take", func(t *testing.T) {
+		for range 3 {
+			pool, info := GetPgxPool(t, dsn, options...)
+
+			var versions int
+			require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM goose_db_version").Scan(&versions))
+			require.Positive(t, versions)
+			databases[info.DatabaseName()] = true
+
+			db, ok := info.(*testDB)
+			require.True(t, ok)
+			var err error
+			key, err = db.migratedDatabaseKey()
+			require.NoError(t, err)
+		}
+	})
+	require.Len(t, databases, 3)
+
+	// the pool is removed after the last test which uses it
+	globalDatabasePoolMu.Lock()
+	_, ok := globalDatabasePools[key]
+	globalDatabasePoolMu.Unlock()
+	require.False(t, ok)
+}
+
+// TestPrepareDatabasePoolOptions verifies the validation of WithDatabasePoolSize.
+func TestPrepareDatabasePoolOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		driver  string
+		options []Option
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			driver:  "pgx",
+			options: []Option{WithDatabasePoolSize(4)},
+		},
+		{
+			name:    "negative size",
+			driver:  "pgx",
+			options: []Option{WithDatabasePoolSize(-1)},
+			wantErr: "database pool size must be greater than 0",
+		},
+		{
+			name:    "driver",
+			driver:  mongoDriverName,
+			options: []Option{WithDatabasePoolSize(2)},
+			wantErr: "not supported for driver mongodb",
+		},
+		{
+			name:    "kubernetes",
+			driver:  "pgx",
+			options: []Option{WithDatabasePoolSize(2), WithMode(RunModeKubernetes), WithDockerRepository("postgres")},
+			wantErr: "supported only in RunModeDocker and RunModeExternal",
+		},
+		{
+			name:    "no test database",
+			driver:  "pgx",
+			options: []Option{WithDatabasePoolSize(2), WithNoCreateDatabase()},
+			wantErr: "can not be used without a test database",
+		},
+		{
+			name:    "test database limit",
+			driver:  "pgx",
+			options: []Option{WithDatabasePoolSize(2), WithMode(RunModeExternal), WithMaxConcurrentTestDatabases(2)},
+			wantErr: "can not be used with WithMaxConcurrentTestDatabases",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := newExternalTestDB(t)
+			db.driver = tt.driver
+			err := db.prepareOptions(db.driver, tt.options)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestPoolPreparation verifies that the preparation of a pooled database does not share the state of the test.
+func TestPoolPreparation(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t, "pgx", DefaultPostgresDSN)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeExternal), WithDatabasePoolSize(2), WithPostgresExtensions("pgcrypto"),
+		WithSeedFunc(func(*sql.DB) error { return nil }),
+	}))
+
+	prepared := db.poolPreparation()
+	require.NotEqual(t, db.databaseName, prepared.databaseName)
+	require.Equal(t, db.initQueries, prepared.initQueries)
+	require.Empty(t, prepared.seeds)
+
+	prepared.url.Port = 1
+	prepared.initQueries[0] = "SELECT 1"
+	require.NotEqual(t, 1, db.url.Port)
+	require.Equal(t, `CREATE EXTENSION IF NOT EXISTS "pgcrypto"`, db.initQueries[0])
+}
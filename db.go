@@ -3,22 +3,30 @@ package testdock
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/cenkalti/backoff/v5"
 	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
 )
 
 // Informer interface for database information.
 type Informer interface {
 	// DSN returns the real database connection string.
 	DSN() string
-	// Host returns the host of the database server.
+	// Host returns the host of the database server. IPv6 addresses are returned without brackets,
+	// use net.JoinHostPort to build an address. In RunModeDocker it is the host where the container runtime
+	// publishes the port, for example the host of a remote daemon or TESTCONTAINERS_HOST_OVERRIDE.
 	Host() string
-	// Port returns the port of the database server.
+	// Port returns the port of the database server. In RunModeDocker it is the host port reported
+	// by the container runtime, which can differ from the port of the DSN.
 	Port() int
 	// DatabaseName returns the database name for testing.
 	DatabaseName() string
@@ -31,6 +39,11 @@ const (
 	DefaultTotalRetryDuration = time.Second * 30
 	// defaultCloseTimeout is the default timeout for closing returned resources during cleanup.
 	defaultCloseTimeout = time.Second * 30
+	// defaultDockerDaemonTimeout is the default timeout for waiting for the docker daemon.
+	defaultDockerDaemonTimeout = time.Second * 10
+	// testDeadlineMargin is the time before the deadline of the test when retries stop,
+	// left for the cleanup and for reporting the error.
+	testDeadlineMargin = time.Second * 5
 )
 
 // PrepareCleanUp - function for prepare to delete temporary test database.
@@ -53,65 +66,234 @@ type testDB struct {
 	dsn                       string           // database connection string
 	retryTimeout              time.Duration    // retry timeout for connecting to the database
 	totalRetryDuration        time.Duration    // total retry duration
+	retryBackOff              *retryPolicy     // exponential backoff of retries, nil for the constant retryTimeout
 	closeTimeout              time.Duration    // timeout for closing returned resources during cleanup
 	migrationsDir             string           // migrations directory
+	migrationsFS              fs.FS            // migrations file system of WithMigrationsFS, migrationsDir is its root
 	migrationTargetVersion    int64            // numeric migration file prefix where automatic migration must stop
 	hasMigrationTargetVersion bool             // enables migration up to migrationTargetVersion instead of all migrations
+	migrationRoundTrip        bool             // roll back all migrations and apply them again after the first up
+	migrationChecksums        bool             // record checksums of migration files and fail on changed ones
+	templateDatabase          bool             // create test databases as copies of a migrated template database
+	templateName              string           // name of the template database of WithTemplateDatabase
+	databasePoolSize          int              // number of test databases created and migrated in advance
+	pooledDatabase            bool             // the test database was taken from the pool of WithDatabasePoolSize
+	sharedDatabase            bool             // tests with the same server and migrations share one test database
+	shared                    *sharedDB        // shared test database the test is attached to
+	schemaIsolation           bool             // the test uses a schema of the DSN database instead of a new database
+	schemaName                string           // schema of the test with WithSchemaIsolation
+	fixedDatabaseName         string           // name of the test database of WithDatabaseName
+	databaseNamePrefix        string           // prefix of generated names of test databases
 	unsetProxyEnv             bool             // unset HTTP_PROXY, HTTPS_PROXY etc. environment variables
 	migrateFactory            MigrateFactory   // unified way to create migrations
+	extraMigrations           []migrationSet   // migrations applied after migrationsDir in order
 	prepareCleanUp            []PrepareCleanUp // function for prepare to delete temporary test database.
 	connectDatabase           string           // database name for connecting to the database server
 	connectDatabaseOverride   bool
-
-	dockerPort           int      // docker port
-	dockerRepository     string   // docker hub repository
-	dockerImage          string   // docker hub image tag
-	dockerSocketEndpoint string   // docker socket endpoint for connecting to the docker daemon
-	dockerEnv            []string // environment variables for the docker container
+	initQueries               []string         // queries executed in the test database before migrations
+	seeds                     []seedStep       // seed scripts and functions executed after migrations
+	readinessChecks           []readinessCheck // queries which must succeed before the database is considered ready
+	waitStrategies            []WaitStrategy   // checks which must succeed before a new docker container is considered ready
+	artifactPath              string           // file of the schema artifact
+	artifactMode              ArtifactMode     // how the schema artifact is used
+	testLabels                bool             // propagate test name, package and team into container labels and log fields
+	testLabelTeam             string           // optional team label
+	noTestDatabase            bool             // the test database is not created, tests share the database from the DSN
+	filePath                  string           // file of the test database for engines without a server
+	fileInMemory              bool             // use a shared-cache in-memory database instead of a file
+	rotateUser                string           // test-scoped user created by RotatePassword
+	maxTestDatabases          int              // limit of test databases existing at the same time on the server
+	releaseSlot               func()           // releases the test database slot taken for maxTestDatabases
+	mongoReplicaSet           bool             // MongoDB runs as a single-node replica set
+	mongoSharded              bool             // MongoDB runs as a sharded cluster behind mongos
+	mongoFixtures             []string         // directories of MongoDB document fixtures loaded after migrations
+	pgReplica                 bool             // PostgreSQL primary runs with a streaming replica
+	citusWorkers              int              // number of Citus workers started with the coordinator
+	clickHouseCluster         bool             // ClickHouse runs as a cluster of two shards with Keeper
+	topologyRole              string           // role of the main container in a multi-container topology
+	topology                  *dockerTopology  // network and auxiliary containers of the topology
+	engine                    *backend.Engine  // creates and removes the test database of a submodule
+
+	dockerPort           int           // docker port
+	dockerRepository     string        // docker hub repository
+	dockerImage          string        // docker hub image tag
+	dockerBuildContext   string        // build context directory of the image built by WithDockerBuild
+	dockerBuildFile      string        // dockerfile of the image built by WithDockerBuild relative to the context
+	dockerSocketEndpoint string        // docker socket endpoint for connecting to the docker daemon
+	dockerDaemonTimeout  time.Duration // timeout for waiting for the docker daemon
+	dockerEnv            []string      // environment variables for the docker container
+	dockerNetwork        string        // user-defined docker network joined by the container
+	dockerNetworkAliases []string      // aliases of the container in the user-defined docker network
+	dockerMounts         []string      // bind mounts and volumes of the container in the source:target[:options] form
+	initScripts          string        // host directory of scripts executed by the image when the database is initialized
+	dockerInitDir        string        // directory of init scripts in the docker image, empty if not supported
+	tmpfsData            bool          // mount the data directory of the engine on tmpfs
+	dockerDataDir        string        // data directory of the engine in the docker image
+	dockerDataEnv        []string      // environment variables which point the engine to dockerDataDir on tmpfs
+	reuseContainer       string        // key of the container kept running and reused by the next test runs
+	sharedContainer      bool          // share the container with other test binaries of the go test run
+	containerName        string        // name of the container, empty for a random name
+	reaperTTL            time.Duration // age of leftover containers and networks of previous runs which are removed
+	failureLogLines      int           // number of container log lines attached to a failed test
+	containerStopTimeout time.Duration // timeout of the graceful stop of the container before removal, zero kills it
+	keepOnFailure        bool          // keep the container running for inspection if a test which used it failed
+	keepDatabase         bool          // keep the test database of a failed test for inspection
+	kubeNamespace        string        // namespace of the pod in RunModeKubernetes, empty for the kubeconfig default
+	kubeContext          string        // kubeconfig context of RunModeKubernetes, empty for the current context
+	dockerCPUs           float64       // CPU limit of the container, zero means no limit
+	dockerMemory         int64         // memory limit of the container in bytes, zero means no limit
+	dockerShmSize        int64         // size of /dev/shm of the container in bytes, zero means the docker default
+	dockerPrivileged     bool          // run the container in privileged mode
+	dockerCmd            []string      // command of the container, nil keeps the command of the image and the preset
+	dockerEntrypoint     []string      // entrypoint of the container, nil keeps the entrypoint of the image
+	fastMode             bool          // run the engine with the durability settings traded for speed
+	dockerFastCmd        []string      // command of the image with the speed flags of the engine for WithFastMode
+
+	dockerRunOptions []func(*dockertest.RunOptions) // user modifications of docker run options
+	dockerHostConfig []func(*docker.HostConfig)     // user modifications of docker host config
+	dockerUlimits    []docker.ULimit                // ulimits of the container
+
+	dockerStartHooks []dockerStartHook // functions executed once after the docker container is created
+
+	dockerLabels map[string]string // custom labels of the docker containers
+
+	imagePullPolicy ImagePullPolicy          // when the docker image is pulled
+	registryAuth    docker.AuthConfiguration // credentials of the private docker registry
+
+	snapshots map[string]databaseSnapshot // snapshots of the test database taken by Snapshot
+
+	resource      *dockertest.Resource // docker resource used by the test database
+	containerKept bool                 // the docker container outlives the test binary, so the test database is dropped
 }
 
+// dockerStartHook is executed once after the docker container is created,
+// before the container is shared with other tests.
+type dockerStartHook func(ctx context.Context, d *testDB) error
+
 //nolint:gochecknoglobals // used to synchronize access to the same database connection string across tests.
 var (
 	globalMu      sync.Mutex
 	globalMuByDSN = make(map[string]*sync.Mutex)
 )
 
+// newTestDB creates a test database with default options.
+func newTestDB(tb testing.TB, driver, dsn string) *testDB {
+	return &testDB{
+		t:                         tb,
+		logger:                    ctxlog.Must(ctxlog.WithTesting(tb)),
+		databaseName:              "",
+		url:                       nil,
+		dsnNoPass:                 "",
+		driver:                    driver,
+		mode:                      RunModeAuto,
+		dsn:                       dsn,
+		retryTimeout:              DefaultRetryTimeout,
+		totalRetryDuration:        DefaultTotalRetryDuration,
+		retryBackOff:              nil,
+		closeTimeout:              defaultCloseTimeout,
+		migrationsDir:             "",
+		migrationsFS:              nil,
+		migrationTargetVersion:    0,
+		hasMigrationTargetVersion: false,
+		migrationRoundTrip:        false,
+		migrationChecksums:        false,
+		templateDatabase:          false,
+		templateName:              "",
+		databasePoolSize:          0,
+		pooledDatabase:            false,
+		sharedDatabase:            false,
+		shared:                    nil,
+		schemaIsolation:           false,
+		schemaName:                "",
+		fixedDatabaseName:         "",
+		databaseNamePrefix:        "",
+		unsetProxyEnv:             false,
+		migrateFactory:            nil,
+		extraMigrations:           nil,
+		prepareCleanUp:            nil,
+		connectDatabase:           "",
+		connectDatabaseOverride:   false,
+		initQueries:               nil,
+		seeds:                     nil,
+		readinessChecks:           nil,
+		waitStrategies:            nil,
+		artifactPath:              "",
+		artifactMode:              ArtifactModeOff,
+		testLabels:                false,
+		testLabelTeam:             "",
+		dockerLabels:              nil,
+		imagePullPolicy:           ImagePullIfNotPresent,
+		registryAuth:              docker.AuthConfiguration{},
+		snapshots:                 nil,
+		noTestDatabase:            false,
+		filePath:                  "",
+		fileInMemory:              false,
+		rotateUser:                "",
+		maxTestDatabases:          0,
+		releaseSlot:               nil,
+		mongoReplicaSet:           false,
+		mongoSharded:              false,
+		mongoFixtures:             nil,
+		pgReplica:                 false,
+		citusWorkers:              0,
+		clickHouseCluster:         false,
+		topologyRole:              "",
+		topology:                  nil,
+		engine:                    nil,
+		dockerPort:                0,
+		dockerRepository:          "",
+		dockerImage:               "",
+		dockerBuildContext:        "",
+		dockerBuildFile:           "",
+		dockerSocketEndpoint:      "",
+		dockerDaemonTimeout:       defaultDockerDaemonTimeout,
+		dockerEnv:                 nil,
+		dockerNetwork:             "",
+		dockerNetworkAliases:      nil,
+		dockerMounts:              nil,
+		initScripts:               "",
+		dockerInitDir:             "",
+		tmpfsData:                 false,
+		dockerDataDir:             "",
+		dockerDataEnv:             nil,
+		reuseContainer:            "",
+		sharedContainer:           false,
+		containerName:             "",
+		reaperTTL:                 defaultReaperTTL,
+		failureLogLines:           defaultFailureLogLines,
+		containerStopTimeout:      0,
+		keepOnFailure:             false,
+		keepDatabase:              false,
+		kubeNamespace:             "",
+		kubeContext:               "",
+		dockerCPUs:                0,
+		dockerMemory:              0,
+		dockerShmSize:             0,
+		dockerPrivileged:          false,
+		dockerCmd:                 nil,
+		dockerEntrypoint:          nil,
+		fastMode:                  false,
+		dockerFastCmd:             nil,
+		dockerRunOptions:          nil,
+		dockerHostConfig:          nil,
+		dockerUlimits:             nil,
+		dockerStartHooks:          nil,
+		resource:                  nil,
+	}
+}
+
 // newTDB creates a new test database and applies migrations.
 func newTDB(ctx context.Context, tb testing.TB, driver, dsn string, opt []Option) *testDB {
 	tb.Helper()
 
 	var (
-		db = &testDB{
-			t:                         tb,
-			logger:                    ctxlog.Must(ctxlog.WithTesting(tb)),
-			databaseName:              "",
-			url:                       nil,
-			dsnNoPass:                 "",
-			driver:                    driver,
-			mode:                      RunModeAuto,
-			dsn:                       dsn,
-			retryTimeout:              DefaultRetryTimeout,
-			totalRetryDuration:        DefaultTotalRetryDuration,
-			closeTimeout:              defaultCloseTimeout,
-			migrationsDir:             "",
-			migrationTargetVersion:    0,
-			hasMigrationTargetVersion: false,
-			unsetProxyEnv:             false,
-			migrateFactory:            nil,
-			prepareCleanUp:            nil,
-			connectDatabase:           "",
-			connectDatabaseOverride:   false,
-			dockerPort:                0,
-			dockerRepository:          "",
-			dockerImage:               "",
-			dockerSocketEndpoint:      "",
-			dockerEnv:                 nil,
-		}
+		db        = newTestDB(tb, driver, dsn)
 		errResult error
 	)
 
 	defer func() {
 		if errResult != nil {
+			db.releaseTestDatabaseSlot()
 			tb.Fatalf("cannot create test database: %v", errResult)
 		}
 	}()
@@ -120,6 +302,11 @@ func newTDB(ctx context.Context, tb testing.TB, driver, dsn string, opt []Option
 		return nil
 	}
 
+	// wait for the slot before the lock by DSN, so tests holding slots are not blocked
+	if errResult = db.acquireTestDatabaseSlot(ctx); errResult != nil {
+		return nil
+	}
+
 	globalMu.Lock()
 	mu, ok := globalMuByDSN[db.dsn]
 	if !ok {
@@ -128,51 +315,179 @@ func newTDB(ctx context.Context, tb testing.TB, driver, dsn string, opt []Option
 	}
 	globalMu.Unlock()
 
+	// only the creation of the server is serialized, tests create and migrate their databases concurrently
 	mu.Lock()
-	defer mu.Unlock()
+	unlock := sync.OnceFunc(mu.Unlock)
+	defer unlock()
 
-	if db.mode == RunModeDocker {
-		db.logger.Info(ctx, "using docker test database", "dsn", db.dsnNoPass)
-		if errResult = db.createDockerResources(ctx); errResult != nil {
-			return nil
-		}
-	} else {
-		db.logger.Info(ctx, "using real test database", "dsn", db.dsnNoPass)
+	if errResult = db.createServerResources(ctx); errResult != nil {
+		return nil
+	}
+	// tests sharing the database from the DSN apply the migrations one by one
+	if !db.noTestDatabase {
+		unlock()
 	}
 
-	if errResult = db.createTestDatabase(ctx); errResult != nil {
-		if err := db.close(ctx); err != nil {
-			db.logger.Info(ctx, "failed to close test database", "dsn", db.dsnNoPass, "error", err)
-		}
+	if errResult = db.prepareTemplateDatabase(ctx); errResult != nil {
+		return nil
+	}
+
+	if errResult = db.takePooledDatabase(ctx); errResult != nil {
+		return nil
+	}
+
+	var (
+		attached     bool
+		unlockShared func()
+	)
+	if attached, unlockShared, errResult = db.attachSharedDatabase(ctx); errResult != nil {
 		return nil
 	}
+	defer unlockShared()
 
-	if db.migrationsDir != "" {
-		if errResult = db.migrationsUp(ctx); errResult != nil {
+	// tests attached to a shared database use it as it was prepared by the first test
+	if !attached {
+		errResult = db.prepareTestDatabase(ctx)
+		db.registerSharedDatabase(errResult == nil)
+		unlockShared()
+		if errResult != nil {
 			return nil
 		}
 	}
 
 	tb.Cleanup(func() {
 		cleanupCtx := context.Background()
+		// the test detaches from the shared database even if it keeps the database, so the count of tests drops
+		if !db.detachSharedDatabase(db.keepFailedDatabase()) {
+			db.releaseTestDatabaseSlot()
+			return
+		}
 		if closeErr := db.close(cleanupCtx); closeErr != nil {
 			db.logger.Info(cleanupCtx, "failed to close test database", "dsn", db.dsnNoPass, "error", closeErr)
 		} else {
 			db.logger.Info(cleanupCtx, "test database closed", "dsn", db.dsnNoPass)
 		}
+		db.releaseTestDatabaseSlot()
 	})
 
 	return db
 }
 
+// createServerResources starts the database server of the run mode or reuses the server of other tests.
+func (d *testDB) createServerResources(ctx context.Context) error {
+	if d.mode == RunModeDocker {
+		d.logger.Info(ctx, "using docker test database", "dsn", d.dsnNoPass)
+		return d.createDockerResources(ctx)
+	} else if d.mode == RunModeKubernetes {
+		d.logger.Info(ctx, "using kubernetes test database", "dsn", d.dsnNoPass)
+		return d.createBackendServerResources(ctx)
+	} else if d.mode == RunModeEmbedded {
+		d.logger.Info(ctx, "using embedded test database", "dsn", d.dsnNoPass)
+		return d.createBackendServerResources(ctx)
+	}
+
+	d.logger.Info(ctx, "using real test database", "dsn", d.dsnNoPass)
+
+	return nil
+}
+
+// prepareTestDatabase creates, initializes, migrates and seeds the test database.
+func (d *testDB) prepareTestDatabase(ctx context.Context) error {
+	var err error
+
+	if !d.pooledDatabase {
+		if err = d.createTestDatabase(ctx); err != nil {
+			// the existing database belongs to another test or was kept by a previous run
+			if isDatabaseExistsError(err) {
+				return err
+			}
+			if closeErr := d.close(ctx); closeErr != nil {
+				d.logger.Info(ctx, "failed to close test database", "dsn", d.dsnNoPass, "error", closeErr)
+			}
+			return err
+		}
+	}
+
+	// the test databases created from the template or taken from the pool are already initialized and migrated
+	if d.templateName == "" && !d.pooledDatabase {
+		if err = d.initTestDatabase(ctx); err != nil {
+			return err
+		}
+	}
+
+	if d.artifactMode == ArtifactModeReplay {
+		if err = d.replayArtifact(ctx); err != nil {
+			return err
+		}
+	} else if d.templateName == "" && !d.pooledDatabase && (d.migrationsDir != "" || len(d.extraMigrations) > 0) {
+		if err = d.extractMigrationsFS(); err != nil {
+			return err
+		}
+		if err = d.migrationsUp(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = d.checkMigrationChecksums(ctx); err != nil {
+		return err
+	}
+
+	if d.artifactMode == ArtifactModeRecord {
+		if err = d.recordArtifact(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = d.seedTestDatabase(ctx); err != nil {
+		return err
+	}
+
+	return d.loadMongoFixtures(ctx)
+}
+
 // migrationsUp applies migrations to the database.
 func (d *testDB) migrationsUp(ctx context.Context) error {
 	d.logger.Info(ctx, "migrations up start", "dsn", d.dsnNoPass)
 	defer d.logger.Info(ctx, "migrations up end", "dsn", d.dsnNoPass)
 
-	dsn := d.url.replaceDatabase(d.databaseName).string(false)
+	if d.migrationsDir != "" {
+		if err := d.applyMigrations(ctx); err != nil {
+			return err
+		}
+
+		if d.migrationRoundTrip {
+			if err := d.migrationsRoundTrip(ctx); err != nil {
+				return fmt.Errorf("migration round trip check: %w", err)
+			}
+		}
+	}
+
+	for _, set := range d.extraMigrations {
+		if err := d.applyExtraMigrations(ctx, set); err != nil {
+			return fmt.Errorf("extra migrations %s: %w", set.dir, err)
+		}
+	}
+
+	return nil
+}
 
-	migrator, err := d.migrateFactory(d.t, dsn, d.migrationsDir, d.logger)
+// applyExtraMigrations applies all migrations of the set.
+func (d *testDB) applyExtraMigrations(ctx context.Context, set migrationSet) error {
+	migrator, err := set.factory(d.t, d.DSN(), set.dir, d.logger)
+	if err != nil {
+		return fmt.Errorf("new migrator: %w", err)
+	}
+
+	if err = migrator.Up(ctx); err != nil {
+		return fmt.Errorf("up migrations: %w", err)
+	}
+
+	return nil
+}
+
+// applyMigrations applies all migrations or migrations up to the target version.
+func (d *testDB) applyMigrations(ctx context.Context) error {
+	migrator, err := d.migrateFactory(d.t, d.DSN(), d.migrationsDir, d.logger)
 	if err != nil {
 		return fmt.Errorf("new migrator: %w", err)
 	}
@@ -191,17 +506,67 @@ func (d *testDB) migrationsUp(ctx context.Context) error {
 	return nil
 }
 
+// migrationsRoundTrip rolls back all applied migrations and applies them again.
+// A new migrator is created for every stage, because migrators may release resources after use.
+func (d *testDB) migrationsRoundTrip(ctx context.Context) error {
+	d.logger.Info(ctx, "migrations down start", "dsn", d.dsnNoPass)
+
+	migrator, err := d.migrateFactory(d.t, d.DSN(), d.migrationsDir, d.logger)
+	if err != nil {
+		return fmt.Errorf("new migrator: %w", err)
+	}
+
+	reversibleMigrator, ok := migrator.(ReversibleMigrator)
+	if !ok {
+		return errors.New("WithMigrationRoundTripCheck requires migrator to implement ReversibleMigrator")
+	}
+
+	if err = reversibleMigrator.Down(ctx); err != nil {
+		return fmt.Errorf("down migrations: %w", err)
+	}
+
+	d.logger.Info(ctx, "migrations down end", "dsn", d.dsnNoPass)
+
+	return d.applyMigrations(ctx)
+}
+
 // close closes the test database.
 func (d *testDB) close(ctx context.Context) error {
-	if d.mode != RunModeDocker {
-		if d.driver == mongoDriverName {
+	// the test databases in a removed container disappear with it
+	if d.mode != RunModeDocker || d.containerKept {
+		if d.driver == mongoDriverName || d.noTestDatabase {
 			return nil
 		}
+		if d.driver == surrealDriverName {
+			return d.removeSurrealDatabase(ctx)
+		}
+		if d.driver == spannerDriverName {
+			return d.dropSpannerDatabase(ctx)
+		}
+		if d.driver == firestoreDriverName {
+			return d.clearFirestoreProject(ctx)
+		}
+		if d.driver == pubSubDriverName {
+			return d.clearPubSubProject(ctx)
+		}
+		if d.engine != nil {
+			return d.engine.Remove(ctx, backendDB{testDB: d})
+		}
+		if d.driver == typesenseDriverName || d.driver == meilisearchDriverName {
+			return d.deleteSearchPrefix(ctx)
+		}
+		if d.driver == qdrantDriverName || d.driver == weaviateDriverName {
+			return d.deleteVectorPrefix(ctx)
+		}
 
 		// remove the database created before applying the migrations
 		d.logger.Info(ctx, "deleting test database", "dsn", d.dsnNoPass, "database", d.databaseName)
 
 		dsn := d.url.string(false)
+		if d.schemaName != "" {
+			// the schema is in the database from the DSN, which is used by other tests
+			dsn = d.url.replaceDatabase(d.databaseName).string(false)
+		}
 		db, err := sql.Open(d.driver, dsn)
 		if err != nil {
 			return fmt.Errorf("sql open url (%s): %w", dsn, err)
@@ -210,13 +575,18 @@ func (d *testDB) close(ctx context.Context) error {
 			_ = db.Close()
 		}()
 
-		for _, prepareCleanUp := range d.prepareCleanUp {
+		// the clean up functions disconnect all users of the database, which is shared by tests with schemas
+		prepareCleanUps := d.prepareCleanUp
+		if d.schemaName != "" {
+			prepareCleanUps = nil
+		}
+		for _, prepareCleanUp := range prepareCleanUps {
 			if prepareErr := prepareCleanUp(db, d.databaseName); prepareErr != nil {
 				d.logger.Info(ctx, "failed to prepare clean up", "dsn", d.dsnNoPass, "error", prepareErr)
 			}
 		}
 
-		if _, err = db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE %s", d.databaseName)); err != nil {
+		if err = d.dropTestDatabase(ctx, db); err != nil {
 			return fmt.Errorf("drop db: %w", err)
 		}
 
@@ -226,13 +596,55 @@ func (d *testDB) close(ctx context.Context) error {
 	return nil
 }
 
+// dropDatabaseQuery returns the statement that removes the temporary test database.
+func (d *testDB) dropDatabaseQuery() string {
+	if d.driver == oracleDriverName {
+		return fmt.Sprintf("DROP USER %s CASCADE", d.databaseName)
+	}
+	if d.schemaName != "" {
+		return d.dropSchemaQuery()
+	}
+
+	return fmt.Sprintf("DROP DATABASE %s", d.databaseName)
+}
+
 // initDatabase creates a test database or connects to an existing one.
 func (d *testDB) createTestDatabase(ctx context.Context) error {
-	if d.driver == mongoDriverName {
+	if d.mongoReplicaSet {
+		if err := d.waitMongoPrimary(ctx, d.url); err != nil {
+			return err
+		}
+	}
+	if d.noTestDatabase {
 		return nil
 	}
+	if d.schemaName != "" {
+		return d.createTestSchema(ctx)
+	}
+	if d.engine != nil {
+		return d.engine.Create(ctx, backendDB{testDB: d})
+	}
 
-	return d.createSQLDatabase(ctx)
+	switch d.driver {
+	case mongoDriverName:
+		return nil
+	case oracleDriverName:
+		return d.createOracleSchema(ctx)
+	case surrealDriverName:
+		return d.createSurrealDatabase(ctx)
+	case spannerDriverName:
+		return d.createSpannerDatabase(ctx)
+	case firestoreDriverName, pubSubDriverName:
+		return d.waitEmulator(ctx)
+	case typesenseDriverName, meilisearchDriverName:
+		return d.waitSearchEngine(ctx)
+	case qdrantDriverName, weaviateDriverName:
+		return d.waitVectorDB(ctx)
+	case clickHouseDriverName:
+		return d.createClickHouseDatabase(ctx)
+	default:
+		return d.createSQLDatabase(ctx)
+	}
 }
 
 // retryConnect connects to the database with retries.
@@ -248,9 +660,9 @@ func (d *testDB) retryConnect(ctx context.Context, info string, op func() error)
 	}
 
 	_, err := backoff.Retry(
-		context.Background(), operation,
-		backoff.WithBackOff(backoff.NewConstantBackOff(d.retryTimeout)),
-		backoff.WithMaxElapsedTime(d.totalRetryDuration),
+		ctx, operation,
+		backoff.WithBackOff(d.newRetryBackOff()),
+		backoff.WithMaxElapsedTime(d.untilTestDeadline(d.totalRetryDuration)),
 	)
 	if err != nil {
 		return fmt.Errorf("retry failed after %d attempts: %w", attempt, err)
@@ -259,18 +671,67 @@ func (d *testDB) retryConnect(ctx context.Context, info string, op func() error)
 	return nil
 }
 
+// untilTestDeadline limits the timeout by the deadline of the test from go test -timeout,
+// so retries stop and report the error before go test panics with the timeout.
+func (d *testDB) untilTestDeadline(timeout time.Duration) time.Duration {
+	t, ok := d.t.(interface{ Deadline() (time.Time, bool) })
+	if !ok {
+		return timeout
+	}
+	deadline, ok := t.Deadline()
+	if !ok {
+		return timeout
+	}
+
+	// zero means no limit for backoff, so at least one attempt is made after the deadline is passed
+	return max(min(timeout, time.Until(deadline)-testDeadlineMargin), time.Nanosecond)
+}
+
+// testURL returns the connection string of the temporary test database.
+func (d *testDB) testURL() *dbURL {
+	if d.schemaName != "" {
+		return d.schemaTestURL()
+	}
+
+	switch d.driver {
+	case oracleDriverName:
+		return d.oracleTestURL()
+	case surrealDriverName:
+		return d.surrealDBTestURL()
+	case spannerDriverName:
+		return d.spannerTestURL()
+	}
+
+	return d.url.replaceDatabase(d.databaseName)
+}
+
 // DSN returns the real database connection string.
+// For engines without a server, returns the path of the database file.
 func (d *testDB) DSN() string {
-	return d.url.replaceDatabase(d.databaseName).string(false)
+	if d.filePath != "" {
+		return d.filePath
+	}
+
+	return d.testURL().string(false)
 }
 
 // Host returns the database host.
+// For engines without a server, returns an empty string.
 func (d *testDB) Host() string {
+	if d.url == nil {
+		return ""
+	}
+
 	return d.url.Host
 }
 
 // Port returns the database port.
+// For engines without a server, returns 0.
 func (d *testDB) Port() int {
+	if d.url == nil {
+		return 0
+	}
+
 	return d.url.Port
 }
 
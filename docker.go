@@ -2,8 +2,15 @@ package testdock
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,10 +30,20 @@ var (
 	globalDockerPool      *dockertest.Pool
 )
 
+// dockerCPUPeriod is the CPU CFS period in microseconds used for the CPU limit of the container.
+const dockerCPUPeriod = 100000
+
+// dockerLabelKey is the container label with the hash of the shared resource key.
+const dockerLabelKey = "testdock.key"
+
 type dockerResourceInfo struct {
 	resource *dockertest.Resource
 	port     int
 	count    int
+	foreign  bool            // container is owned by another test binary or kept for reuse and must not be purged
+	lease    string          // name of the container lease, the last holder of the lease purges the container
+	failed   bool            // a test which used the container failed
+	topology *dockerTopology // network and auxiliary containers, nil for a single container
 	mu       sync.Mutex
 }
 
@@ -34,11 +51,12 @@ type dockerResourceInfo struct {
 func (d *testDB) createDockerResources(ctx context.Context) error {
 	globalDockerMu.Lock()
 
-	info, ok := globalDockerResources[d.dsn]
+	info, ok := globalDockerResources[d.dockerResourceKey()]
 	if !ok {
 		info = &dockerResourceInfo{}
 	}
 
+	d.useRemoteDockerHost()
 	logDsn := d.dsnNoPass
 	if globalDockerPool == nil {
 		if err := d.createDockerPoolLocked(ctx); err != nil {
@@ -56,25 +74,171 @@ func (d *testDB) createDockerResources(ctx context.Context) error {
 
 	if info.count > 0 {
 		d.url.Port = info.port
+		d.topology = info.topology
 		d.logger.Info(ctx, "use existing resources", "component", "docker", "dsn", logDsn)
-	} else if err := d.createDockerResource(ctx, info, logDsn); err != nil {
-		return err
+	} else {
+		unlock, err := d.lockSharedContainer()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err = d.createDockerResource(ctx, info, logDsn); err != nil {
+			return err
+		}
+
+		d.resource = info.resource
+		// the owner of a foreign container has already executed the hooks
+		if !info.foreign {
+			if err = d.runDockerStartHooks(ctx, info, logDsn); err != nil {
+				return err
+			}
+		}
+		// the container is kept for the next test runs
+		if d.reuseContainer != "" {
+			info.foreign = true
+		}
+		if err = d.registerSharedContainer(ctx, info, logDsn); err != nil {
+			return err
+		}
 	}
 
 	globalDockerMu.Lock()
-	globalDockerResources[d.dsn] = info
+	globalDockerResources[d.dockerResourceKey()] = info
 	globalDockerMu.Unlock()
 
 	info.count++
+	d.resource = info.resource
+	d.containerKept = info.foreign || d.sharedContainer
 	d.registerDockerResourceCleanup(info, logDsn)
+	d.registerFailureLogs()
+
+	return nil
+}
+
+// runDockerStartHooks waits for a new container, executes the hooks and purges it on failure.
+func (d *testDB) runDockerStartHooks(ctx context.Context, info *dockerResourceInfo, logDsn string) error {
+	if err := d.waitForContainer(ctx, logDsn); err != nil {
+		d.logContainerDiagnostics(info.resource)
+		d.removeFailedDockerResource(ctx, info, logDsn)
+		return err
+	}
+
+	for _, hook := range d.dockerStartHooks {
+		if err := hook(ctx, d); err != nil {
+			d.logContainerDiagnostics(info.resource)
+			d.removeFailedDockerResource(ctx, info, logDsn)
+			return fmt.Errorf("docker start hook: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// dockerResourceKey returns the key of the shared Docker resource.
+// Tests with the same DSN but different images, topologies, networks, environment or labels must not share a container.
+func (d *testDB) dockerResourceKey() string {
+	key := fmt.Sprintf("%s|%s:%s", d.dsn, d.dockerRepository, d.dockerImage)
+	if d.topologyRole != "" {
+		key += "|" + d.topologyRole
+	}
+	if d.dockerNetwork != "" {
+		key += "|" + d.dockerNetwork + ":" + strings.Join(d.dockerNetworkAliases, ",")
+	}
+	if len(d.dockerMounts) > 0 {
+		key += "|" + strings.Join(d.dockerMounts, ",")
+	}
+	if d.tmpfsData {
+		key += "|tmpfs:" + d.dockerDataDir
+	}
+	if d.dockerCPUs > 0 || d.dockerMemory > 0 || d.dockerShmSize > 0 {
+		key += fmt.Sprintf("|cpus:%g,memory:%d,shm:%d", d.dockerCPUs, d.dockerMemory, d.dockerShmSize)
+	}
+	if d.dockerPrivileged {
+		key += "|privileged"
+	}
+	for _, ulimit := range d.dockerUlimits {
+		key += fmt.Sprintf("|ulimit:%s=%d:%d", ulimit.Name, ulimit.Soft, ulimit.Hard)
+	}
+	if d.containerName != "" {
+		key += "|name:" + d.containerName
+	}
+	if d.dockerCmd != nil || d.dockerEntrypoint != nil {
+		key += "|" + strings.Join(d.dockerEntrypoint, " ") + "|" + strings.Join(d.dockerCmd, " ")
+	}
+	if len(d.dockerEnv) > 0 {
+		key += "|env:" + strings.Join(d.dockerEnv, ",")
+	}
+	for _, label := range slices.Sorted(maps.Keys(d.dockerLabels)) {
+		key += "|label:" + label + "=" + d.dockerLabels[label]
+	}
+	// the functions can not be compared, so the container is shared only by the tests with the same container name
+	if (len(d.dockerRunOptions) > 0 || len(d.dockerHostConfig) > 0) && d.containerName == "" && d.t != nil {
+		key += "|test:" + d.t.Name()
+	}
+
+	return key
+}
+
+// dockerResourceLabel returns the value of the dockerLabelKey label.
+// The key contains the password, so only its hash is visible in the container labels.
+func (d *testDB) dockerResourceLabel() string {
+	return hashResourceKey(d.dockerResourceKey())
+}
+
+// hashResourceKey returns the hash of a resource key, which contains the password and must not be logged.
+func hashResourceKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// migratorDockerPool returns the docker pool of testdock or a new pool if there are no docker resources.
+// It is used by migrators, which run their tools in docker containers.
+func migratorDockerPool() (*dockertest.Pool, error) {
+	globalDockerMu.Lock()
+	pool := globalDockerPool
+	globalDockerMu.Unlock()
+
+	if pool != nil {
+		return pool, nil
+	}
+
+	pool, err := newDockerPool("")
+	if err != nil {
+		return nil, fmt.Errorf("dockertest NewPool: %w", err)
+	}
+
+	return pool, nil
+}
+
+// findTestdockContainer returns the ID and the private port of the running testdock container
+// which publishes the host port.
+func findTestdockContainer(pool *dockertest.Pool, publicPort int) (string, int, bool) {
+	containers, err := pool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Filters: map[string][]string{
+			"label":  {dockerLabelKey},
+			"status": {"running"},
+		},
+	})
+	if err != nil {
+		return "", 0, false
+	}
+
+	for _, c := range containers {
+		for _, p := range c.Ports {
+			if int(p.PublicPort) == publicPort {
+				return c.ID, int(p.PrivatePort), true
+			}
+		}
+	}
+
+	return "", 0, false
+}
+
 // createDockerPoolLocked creates the global Docker pool while globalDockerMu is held.
 func (d *testDB) createDockerPoolLocked(ctx context.Context) error {
 	var err error
-	globalDockerPool, err = dockertest.NewPool(d.dockerSocketEndpoint)
+	globalDockerPool, err = newDockerPool(d.dockerSocketEndpoint)
 	if err != nil {
 		return fmt.Errorf("dockertest NewPool: %w", err)
 	}
@@ -83,15 +247,44 @@ func (d *testDB) createDockerPoolLocked(ctx context.Context) error {
 		d.unsetDockerProxyEnv(ctx)
 	}
 
-	if err = globalDockerPool.Client.Ping(); err != nil {
-		return fmt.Errorf("dockertest ping: %w", err)
+	if err = d.waitDockerDaemon(ctx); err != nil {
+		globalDockerPool = nil
+		return fmt.Errorf("docker is unavailable, start Docker or set %s to use an external database: %w",
+			dsnEnvName(d.driver), err)
 	}
 
 	d.logger.Info(ctx, "pool created", "component", "docker")
+	d.reapOrphanedResources(ctx)
 
 	return nil
 }
 
+// waitDockerDaemon pings the Docker daemon with retries, because the daemon may still be starting.
+func (d *testDB) waitDockerDaemon(ctx context.Context) error {
+	const retryTimeout = time.Second
+
+	var attempt int
+	operation := func() (struct{}, error) {
+		if err := globalDockerPool.Client.Ping(); err != nil {
+			attempt++
+			d.logger.Info(ctx, "waiting for docker daemon", "component", "docker", "attempt", attempt, "error", err)
+			return struct{}{}, err
+		}
+		return struct{}{}, nil
+	}
+
+	if d.dockerDaemonTimeout <= 0 {
+		_, err := operation()
+		return err
+	}
+
+	_, err := backoff.Retry(ctx, operation,
+		backoff.WithBackOff(backoff.NewConstantBackOff(retryTimeout)),
+		backoff.WithMaxElapsedTime(d.untilTestDeadline(d.dockerDaemonTimeout)))
+
+	return err
+}
+
 // unsetDockerProxyEnv removes proxy variables that can affect Docker client calls.
 func (d *testDB) unsetDockerProxyEnv(ctx context.Context) {
 	proxyEnv := []string{
@@ -137,29 +330,104 @@ func (d *testDB) createDockerResource(ctx context.Context, info *dockerResourceI
 		dockerPort = fmt.Sprintf("%d/tcp", d.dockerPort)
 		err        error
 	)
+	info.foreign = false
+	if d.reuseDockerResource(ctx, info, logDsn) || d.attachSharedContainer(ctx, info, logDsn) {
+		return nil
+	}
+	if d.dockerBuildContext != "" {
+		err = d.buildDockerImage(ctx)
+	} else {
+		err = d.pullDockerImage(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if d.topologyRole != "" {
+		if info.topology, err = newDockerTopology(globalDockerPool); err != nil {
+			return err
+		}
+		d.topology = info.topology
+	}
+	nameLease, err := d.lockContainerNameLease()
+	if err != nil {
+		return err
+	}
+	if nameLease != nil {
+		defer nameLease.unlock()
+	}
 	for {
 		runOptions := &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
 			Repository: d.dockerRepository,
 			Tag:        d.dockerImage,
 			Env:        d.dockerEnv,
+			Labels:     d.containerLabels(),
+			Auth:       d.dockerRegistryAuth(),
+			Mounts:     d.dockerMounts,
 			PortBindings: map[docker.Port][]docker.PortBinding{
 				docker.Port(dockerPort): {{
-					HostIP:   d.url.Host,
+					HostIP:   d.dockerHostIP(),
 					HostPort: strconv.Itoa(d.url.Port),
 				}},
 			},
 		}
-		info.resource, err = globalDockerPool.RunWithOptions(runOptions, func(config *docker.HostConfig) {
-			config.AutoRemove = true
-			config.RestartPolicy = docker.RestartPolicy{Name: "no", MaximumRetryCount: 0}
-		})
+		if d.tmpfsData {
+			runOptions.Env = append(slices.Clone(runOptions.Env), d.dockerDataEnv...)
+		}
+		if d.containerName != "" {
+			runOptions.Name = d.containerName
+		}
+		if d.topology != nil {
+			runOptions.Name = d.topology.containerName(d.topologyRole)
+			runOptions.NetworkID = d.topology.network.Network.ID
+		}
+		for _, f := range d.dockerRunOptions {
+			f(runOptions)
+		}
+		if d.dockerCmd != nil {
+			runOptions.Cmd = d.dockerCmd
+		}
+		if d.dockerEntrypoint != nil {
+			runOptions.Entrypoint = d.dockerEntrypoint
+		}
+		info.resource, err = globalDockerPool.RunWithOptions(runOptions, d.configureDockerHost)
 		if err == nil {
 			break
 		}
 
-		if isDockerBindError(err) {
-			d.logger.Info(ctx, "port is already allocated, trying next port", "dsn", logDsn, "next_port", d.url.Port+1)
-			d.url.Port++
+		if d.containerName != "" && errors.Is(err, docker.ErrContainerAlreadyExists) {
+			if err = d.attachNamedContainer(ctx, info, logDsn, nameLease); err != nil {
+				return err
+			}
+			break
+		}
+
+		if d.url.Port != 0 && isDockerBindError(err) {
+			if runOptions.Name != "" {
+				// the container is created before the port is bound, remove it to reuse the name
+				_ = globalDockerPool.Client.RemoveContainer(docker.RemoveContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+					ID:    runOptions.Name,
+					Force: true,
+				})
+			}
+			// topologies are not shared with other test binaries, named containers are attached by the name
+			if d.topology == nil && d.containerName == "" {
+				if resource := d.findDockerResourceOnPort(); resource != nil {
+					adoptErr := d.adoptDockerResource(info, resource)
+					if adoptErr == nil {
+						d.logger.Info(ctx, "port is taken by the same container of another test binary, reusing it",
+							"component", "docker", "dsn", logDsn, "container", resource.Container.ID)
+						err = nil
+						break
+					}
+					d.logger.Info(ctx, "port is taken by the same container of another test binary, "+
+						"but it can not be shared", "component", "docker", "dsn", logDsn,
+						"container", resource.Container.ID, "error", adoptErr)
+				}
+			}
+
+			// docker publishes port 0 on a free port atomically, so concurrent test binaries do not race for it
+			d.logger.Info(ctx, "port is already allocated, using a random free port", "dsn", logDsn, "port", d.url.Port)
+			d.url.Port = 0
 			continue
 		}
 
@@ -169,19 +437,159 @@ func (d *testDB) createDockerResource(ctx context.Context, info *dockerResourceI
 		}
 
 		d.logger.Info(ctx, "RunWithOptions failed", "component", "docker", "dsn", logDsn, "attempt", attempt, "error", err)
-		time.Sleep(sleepTime)
+		select {
+		case <-ctx.Done():
+			err = errors.Join(err, ctx.Err())
+		case <-time.After(sleepTime):
+			continue
+		}
+		break
 	}
 
 	if err != nil {
+		if info.topology != nil {
+			_ = globalDockerPool.Client.RemoveContainer(docker.RemoveContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+				ID:    info.topology.containerName(d.topologyRole),
+				Force: true,
+			})
+			_ = info.topology.purge(globalDockerPool)
+			info.topology = nil
+		}
 		return fmt.Errorf("dockertest RunWithOptions: %w", err)
 	}
 
+	// remote runtimes, for example Testcontainers Cloud, can publish the port on another host port,
+	// so the port reported by the runtime is used
+	port, portErr := strconv.Atoi(info.resource.GetPort(dockerPort))
+	switch {
+	case portErr == nil && port != d.url.Port:
+		if d.url.Port != 0 {
+			d.logger.Info(ctx, "the container runtime published the port on another host port", "component", "docker",
+				"dsn", logDsn, "port", port)
+		}
+		d.url.Port = port
+	case portErr != nil && d.url.Port == 0:
+		_ = globalDockerPool.Purge(info.resource)
+		if info.topology != nil {
+			_ = info.topology.purge(globalDockerPool)
+			info.topology = nil
+		}
+		return fmt.Errorf("host port of the container: %w", portErr)
+	}
+
+	if d.dockerNetwork != "" && !info.foreign {
+		if err = d.connectDockerNetwork(info.resource); err != nil {
+			_ = globalDockerPool.Purge(info.resource)
+			if info.topology != nil {
+				_ = info.topology.purge(globalDockerPool)
+				info.topology = nil
+			}
+			return err
+		}
+	}
+
 	info.port = d.url.Port
+	d.leaseNewContainer(ctx, info, logDsn, nameLease)
 	d.logger.Info(ctx, "resources created", "component", "docker", "dsn", logDsn)
 
 	return nil
 }
 
+// configureDockerHost fills the host config of the main container and applies WithDockerHostConfig.
+func (d *testDB) configureDockerHost(config *docker.HostConfig) {
+	config.AutoRemove = true
+	config.RestartPolicy = docker.RestartPolicy{Name: "no", MaximumRetryCount: 0}
+	if d.tmpfsData {
+		config.Tmpfs = map[string]string{d.dockerDataDir: ""}
+	}
+	if d.dockerCPUs > 0 {
+		// the same quota as the docker --cpus flag
+		config.CPUPeriod = dockerCPUPeriod
+		config.CPUQuota = int64(d.dockerCPUs * dockerCPUPeriod)
+	}
+	config.Memory = d.dockerMemory
+	config.ShmSize = d.dockerShmSize
+	config.Privileged = d.dockerPrivileged
+	if len(d.dockerUlimits) > 0 {
+		config.Ulimits = slices.Clone(d.dockerUlimits)
+	}
+	for _, f := range d.dockerHostConfig {
+		f(config)
+	}
+}
+
+// connectDockerNetwork connects the container to the network of WithDockerNetwork with the aliases of WithNetworkAlias.
+// The network is not created by testdock, it must exist before the test.
+func (d *testDB) connectDockerNetwork(resource *dockertest.Resource) error {
+	network, err := globalDockerPool.Client.NetworkInfo(d.dockerNetwork)
+	if err != nil {
+		return fmt.Errorf("docker network %s: %w", d.dockerNetwork, err)
+	}
+
+	err = globalDockerPool.Client.ConnectNetwork(network.ID, docker.NetworkConnectionOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Container:      resource.Container.ID,
+		EndpointConfig: &docker.EndpointConfig{Aliases: d.dockerNetworkAliases}, //nolint:exhaustruct // only aliases are set.
+	})
+	if err != nil {
+		return fmt.Errorf("connect container to docker network %s: %w", d.dockerNetwork, err)
+	}
+
+	return nil
+}
+
+// resolveDockerMount checks the mount in the source:target[:options] form and makes a relative host path absolute.
+// Sources without a path separator are names of docker volumes.
+func resolveDockerMount(mount string) (string, error) {
+	parts := strings.SplitN(mount, ":", 3) //nolint:mnd // source, target and options.
+	if len(parts) < 2 || parts[0] == "" || !path.IsAbs(parts[1]) {
+		return "", fmt.Errorf("invalid docker mount %q, expected source:target[:options] with absolute target", mount)
+	}
+
+	if strings.HasPrefix(parts[0], ".") || strings.ContainsRune(parts[0], filepath.Separator) {
+		source, err := filepath.Abs(parts[0])
+		if err != nil {
+			return "", fmt.Errorf("docker mount %q: %w", mount, err)
+		}
+		if _, err = os.Stat(source); err != nil {
+			return "", fmt.Errorf("docker mount %q: %w", mount, err)
+		}
+		parts[0] = source
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// findDockerResourceOnPort finds a running container with the same resource key
+// which was started by another test binary and has already bound the host port.
+// The container is used only while the test binary which started it holds its lease, see adoptDockerResource.
+func (d *testDB) findDockerResourceOnPort() *dockertest.Resource {
+	containers, err := globalDockerPool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Filters: map[string][]string{
+			"label":  {dockerLabelKey + "=" + d.dockerResourceLabel()},
+			"status": {"running"},
+		},
+	})
+	if err != nil {
+		return nil
+	}
+
+	for _, c := range containers {
+		if len(c.Names) == 0 {
+			continue
+		}
+		for _, p := range c.Ports {
+			if int(p.PublicPort) != d.url.Port {
+				continue
+			}
+			if resource, ok := containerByName(c.Names[0]); ok && resource.Container.ID == c.ID {
+				return resource
+			}
+		}
+	}
+
+	return nil
+}
+
 // isDockerBindError checks errors reported when a Docker port is already allocated.
 func isDockerBindError(err error) bool {
 	bindErrors := []string{
@@ -206,15 +614,38 @@ func (d *testDB) registerDockerResourceCleanup(info *dockerResourceInfo, logDsn
 		info.mu.Lock()
 		defer info.mu.Unlock()
 		info.count--
+		if d.t.Failed() {
+			info.failed = true
+		}
 
 		if info.count != 0 {
 			return
 		}
 
+		if info.resource == nil {
+			// already removed by ShutdownAll
+			return
+		}
+
 		globalDockerMu.Lock()
 		defer globalDockerMu.Unlock()
 
-		delete(globalDockerResources, d.dsn)
+		delete(globalDockerResources, d.dockerResourceKey())
+		purge, unlockLease, err := info.releaseContainerLease()
+		defer unlockLease()
+		if err != nil {
+			d.logger.Info(cleanupCtx, "failed to release the container lease", "component", "docker", "dsn", logDsn,
+				"error", err)
+		}
+		if !purge {
+			d.logger.Info(cleanupCtx, "container is used by another test binary, skip purge",
+				"component", "docker", "dsn", logDsn)
+			return
+		}
+		if info.failed && d.keepOnFailure {
+			d.keepFailedDockerResource(info)
+			return
+		}
 		d.purgeDockerResource(cleanupCtx, info, logDsn)
 	})
 }
@@ -227,8 +658,25 @@ func (d *testDB) purgeDockerResource(ctx context.Context, info *dockerResourceIn
 	)
 	var attempt int
 
+	if info.topology != nil {
+		// the network is removed after the main container, which is still connected to it
+		defer func() {
+			if err := info.topology.purge(globalDockerPool); err != nil {
+				d.logger.Info(ctx, "failed to purge topology", "component", "docker", "dsn", logDsn, "error", err)
+			}
+		}()
+	}
+
+	if d.containerStopTimeout > 0 {
+		if err := stopDockerContainer(ctx, info.resource, d.containerStopTimeout); err != nil {
+			d.logger.Info(ctx, "graceful stop failed", "component", "docker", "dsn", logDsn, "error", err)
+		}
+	}
+
 	operation := func() (struct{}, error) {
-		if purgeErr := globalDockerPool.Purge(info.resource); purgeErr != nil {
+		var noSuch *docker.NoSuchContainer
+		// a stopped container is removed by docker because of AutoRemove
+		if purgeErr := globalDockerPool.Purge(info.resource); purgeErr != nil && !errors.As(purgeErr, &noSuch) {
 			attempt++
 			d.logger.Info(ctx, "purge attempt failed",
 				"component", "docker", "dsn", logDsn, "attempt", attempt, "error", purgeErr)
@@ -239,7 +687,7 @@ func (d *testDB) purgeDockerResource(ctx context.Context, info *dockerResourceIn
 
 	if _, retryErr := backoff.Retry(ctx, operation,
 		backoff.WithBackOff(backoff.NewConstantBackOff(retryTimeout)),
-		backoff.WithMaxElapsedTime(maxTime)); retryErr != nil {
+		backoff.WithMaxElapsedTime(d.untilTestDeadline(maxTime))); retryErr != nil {
 		d.logger.Info(ctx, "purge failed after retries",
 			"component", "docker", "dsn", logDsn, "attempt", attempt, "error", retryErr)
 		return
@@ -0,0 +1,199 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	// dockerBuildRepositoryPrefix is the prefix of the repository of images built by WithDockerBuild.
+	dockerBuildRepositoryPrefix = "testdock/"
+	// dockerLabelBuild is the image label with the content hash of the build context.
+	dockerLabelBuild = "testdock.build"
+	// dockerBuildOutputLines is the number of the last lines of the build output in the build error.
+	dockerBuildOutputLines = 20
+)
+
+// dockerBuildInvalidRe matches characters which are not allowed in the repository name.
+var dockerBuildInvalidRe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// dockerBuildMu serializes image builds, so parallel tests build the same image once.
+var dockerBuildMu sync.Mutex //nolint:gochecknoglobals // builds are shared by all tests of the binary.
+
+// WithDockerBuild builds the docker image from the Dockerfile in the build context directory
+// before the container is started. Used only in RunModeDocker.
+func WithDockerBuild(contextDir, dockerfile string) Option {
+	return func(o *testDB) {
+		o.dockerBuildContext = contextDir
+		o.dockerBuildFile = dockerfile
+	}
+}
+
+// prepareDockerBuildOptions validates WithDockerBuild and sets the repository and the tag of the image
+// from the content hash of the build context.
+func (d *testDB) prepareDockerBuildOptions() error {
+	if d.dockerBuildContext == "" {
+		return nil
+	}
+	if d.dockerBuildFile == "" {
+		d.dockerBuildFile = "Dockerfile"
+	}
+	if !filepath.IsLocal(d.dockerBuildFile) {
+		return fmt.Errorf("dockerfile %s must be inside the build context", d.dockerBuildFile)
+	}
+
+	contextDir, err := filepath.Abs(d.dockerBuildContext)
+	if err != nil {
+		return fmt.Errorf("docker build context %s: %w", d.dockerBuildContext, err)
+	}
+	if _, err = os.Stat(filepath.Join(contextDir, d.dockerBuildFile)); err != nil {
+		return fmt.Errorf("dockerfile of the build context %s: %w", d.dockerBuildContext, err)
+	}
+
+	hash, err := dockerBuildHash(contextDir, d.dockerBuildFile)
+	if err != nil {
+		return err
+	}
+
+	d.dockerBuildContext = contextDir
+	d.dockerRepository = dockerBuildRepository(contextDir)
+	d.dockerImage = hash
+
+	return nil
+}
+
+// dockerBuildRepository returns the repository of the image built from the context directory.
+func dockerBuildRepository(contextDir string) string {
+	name := strings.Trim(dockerBuildInvalidRe.ReplaceAllString(strings.ToLower(filepath.Base(contextDir)), "-"), "._-")
+	if name == "" {
+		name = "image"
+	}
+
+	return dockerBuildRepositoryPrefix + name
+}
+
+// dockerBuildHash returns the content hash of the build context: names, modes and contents of all files
+// and the name of the dockerfile.
+func dockerBuildHash(contextDir, dockerfile string) (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "dockerfile %s\n", dockerfile)
+
+	err := filepath.WalkDir(contextDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%s %s\n", filepath.ToSlash(rel), info.Mode())
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, linkErr := os.Readlink(path)
+			if linkErr != nil {
+				return linkErr
+			}
+			_, _ = io.WriteString(h, target)
+		case info.Mode().IsRegular():
+			f, openErr := os.Open(path) //nolint:gosec // the context directory is configured by the user.
+			if openErr != nil {
+				return openErr
+			}
+			_, err = io.Copy(h, f)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hash docker build context %s: %w", contextDir, err)
+	}
+
+	const tagLen = 16
+
+	return hex.EncodeToString(h.Sum(nil))[:tagLen], nil
+}
+
+// buildDockerImage builds the image of WithDockerBuild if the image with the content hash does not exist.
+func (d *testDB) buildDockerImage(ctx context.Context) error {
+	image := d.dockerRepository + ":" + d.dockerImage
+
+	dockerBuildMu.Lock()
+	defer dockerBuildMu.Unlock()
+
+	_, err := globalDockerPool.Client.InspectImage(image)
+	if err == nil {
+		d.logger.Info(ctx, "using cached image", "component", "docker", "image", image)
+		return nil
+	}
+	if !errors.Is(err, docker.ErrNoSuchImage) {
+		return fmt.Errorf("inspect image %s: %w", image, err)
+	}
+
+	d.logger.Info(ctx, "building image", "component", "docker", "image", image, "context", d.dockerBuildContext)
+
+	var output bytes.Buffer
+	err = globalDockerPool.Client.BuildImage(docker.BuildImageOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Name:           image,
+		Dockerfile:     d.dockerBuildFile,
+		ContextDir:     d.dockerBuildContext,
+		Labels:         map[string]string{dockerLabelBuild: d.dockerImage},
+		RmTmpContainer: true,
+		OutputStream:   &output,
+		AuthConfigs:    d.dockerBuildAuthConfigs(),
+		Context:        ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("build image %s from %s: %w\n%s", image, d.dockerBuildContext, err,
+			lastLines(output.String(), dockerBuildOutputLines))
+	}
+
+	d.logger.Info(ctx, "image built", "component", "docker", "image", image)
+
+	return nil
+}
+
+// dockerBuildAuthConfigs returns the credentials of WithRegistryAuth for pulling the base images of the build.
+func (d *testDB) dockerBuildAuthConfigs() docker.AuthConfigurations {
+	if d.registryAuth == (docker.AuthConfiguration{}) {
+		return docker.AuthConfigurations{}
+	}
+
+	server := d.registryAuth.ServerAddress
+	if server == "" {
+		server = "https://index.docker.io/v1/"
+	}
+
+	return docker.AuthConfigurations{Configs: map[string]docker.AuthConfiguration{server: d.registryAuth}}
+}
+
+// lastLines returns the last n lines of the text.
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package testdock
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithDockerBuild verifies the image name and the content hash of the build context.
+func TestWithDockerBuild(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	dir := filepath.Join(t.TempDir(), "Postgres Ext")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sql"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM postgres:17.2\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sql", "ext.sql"), []byte("SELECT 1;\n"), 0o600))
+
+	prepare := func(opt ...Option) *testDB {
+		db := newDB()
+		opts := append(getPostgresOptions(t, "pgx", DefaultPostgresDSN, opt...), WithMode(RunModeDocker))
+		require.NoError(t, db.prepareOptions(db.driver, opts))
+		return db
+	}
+
+	db := prepare(WithDockerBuild(dir, ""))
+	require.Equal(t, "testdock/postgres-ext", db.dockerRepository)
+	require.Len(t, db.dockerImage, 16)
+	require.Equal(t, "Dockerfile", db.dockerBuildFile)
+	require.Equal(t, db.dockerImage, prepare(WithDockerBuild(dir, "Dockerfile")).dockerImage)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sql", "ext.sql"), []byte("SELECT 2;\n"), 0o600))
+	require.NotEqual(t, db.dockerImage, prepare(WithDockerBuild(dir, "")).dockerImage)
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerBuild(dir, "../Dockerfile"),
+	})
+	require.ErrorContains(t, err, "must be inside the build context")
+
+	err = newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerBuild(dir, "missing.Dockerfile"),
+	})
+	require.ErrorContains(t, err, "dockerfile of the build context")
+
+	require.Equal(t, "b\nc", lastLines("a\nb\nc\n", 2))
+}
+
+func Test_PgxDockerBuild(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"),
+		[]byte("FROM postgres:"+testPostgresImage+"\nRUN echo built > /testdock_build.txt\n"), 0o600))
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5554", 1)
+	pool, _ := GetPgxPool(t, dsn, WithDockerBuild(dir, ""))
+
+	var content string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT pg_read_file('/testdock_build.txt')").Scan(&content))
+	require.Equal(t, "built\n", content)
+}
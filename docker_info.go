@@ -0,0 +1,78 @@
+package testdock
+
+import (
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// DockerInformer gives access to the docker container of the test database for advanced use cases,
+// for example pausing the container to test reconnects, inspecting it or attaching other tooling.
+// The informers returned by the Get... functions implement it, use a type assertion:
+//
+//	dockerInfo := info.(testdock.DockerInformer)
+//	err := dockerInfo.DockerClient().PauseContainer(dockerInfo.ContainerID())
+//
+// The container is shared by the tests with the same DSN and image and is removed by testdock,
+// so it must be left running when the test finishes.
+type DockerInformer interface {
+	Informer
+	// ContainerID returns the ID of the docker container.
+	// Returns an empty string if the database does not run in docker.
+	ContainerID() string
+	// Resource returns the dockertest resource of the docker container.
+	// Returns nil if the database does not run in docker.
+	Resource() *dockertest.Resource
+	// NetworkSettings returns the current network settings of the docker container, for example
+	// the published ports and the IP addresses in the networks of the container.
+	// Returns nil if the database does not run in docker.
+	NetworkSettings() *docker.NetworkSettings
+	// DockerClient returns the client of the docker daemon which runs the container.
+	// Returns nil if the database does not run in docker.
+	DockerClient() *docker.Client
+}
+
+// ContainerID returns the ID of the docker container.
+func (d *testDB) ContainerID() string {
+	if d.resource == nil || d.resource.Container == nil {
+		return ""
+	}
+
+	return d.resource.Container.ID
+}
+
+// Resource returns the dockertest resource of the docker container.
+func (d *testDB) Resource() *dockertest.Resource {
+	return d.resource
+}
+
+// NetworkSettings returns the current network settings of the docker container.
+// If the container can not be inspected, the settings at the start of the container are returned.
+func (d *testDB) NetworkSettings() *docker.NetworkSettings {
+	if d.resource == nil || d.resource.Container == nil {
+		return nil
+	}
+
+	if client := d.DockerClient(); client != nil {
+		if container, err := client.InspectContainer(d.resource.Container.ID); err == nil {
+			return container.NetworkSettings
+		}
+	}
+
+	return d.resource.Container.NetworkSettings
+}
+
+// DockerClient returns the client of the docker daemon which runs the container.
+func (d *testDB) DockerClient() *docker.Client {
+	if d.resource == nil {
+		return nil
+	}
+
+	globalDockerMu.Lock()
+	defer globalDockerMu.Unlock()
+
+	if globalDockerPool == nil {
+		return nil
+	}
+
+	return globalDockerPool.Client
+}
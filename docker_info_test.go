@@ -0,0 +1,54 @@
+package testdock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDockerInformer verifies that the informers implement DockerInformer without a container.
+func TestDockerInformer(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	var info Informer = &pgReplicaInfo{testDB: db} //nolint:exhaustruct // only the embedded testDB is used.
+	dockerInfo, ok := info.(DockerInformer)
+	require.True(t, ok)
+	require.Empty(t, dockerInfo.ContainerID())
+	require.Nil(t, dockerInfo.Resource())
+	require.Nil(t, dockerInfo.NetworkSettings())
+	require.Nil(t, dockerInfo.DockerClient())
+
+	settings := &docker.NetworkSettings{IPAddress: "172.17.0.2"}     //nolint:exhaustruct // only the IP address is checked.
+	db.resource = &dockertest.Resource{Container: &docker.Container{ //nolint:exhaustruct // only the container is set.
+		ID:              "0123456789abcdef",
+		NetworkSettings: settings,
+	}}
+	require.Equal(t, "0123456789abcdef", dockerInfo.ContainerID())
+	require.Same(t, db.resource, dockerInfo.Resource())
+	if dockerInfo.DockerClient() == nil {
+		require.Same(t, settings, dockerInfo.NetworkSettings())
+	}
+}
+
+func Test_PgxDockerInformer(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5551", 1)
+	pool, info := GetPgxPool(t, dsn)
+
+	dockerInfo, ok := info.(DockerInformer)
+	require.True(t, ok)
+	require.NotEmpty(t, dockerInfo.ContainerID())
+	require.Equal(t, dockerInfo.ContainerID(), dockerInfo.Resource().Container.ID)
+	require.Contains(t, dockerInfo.NetworkSettings().Ports, docker.Port("5432/tcp"))
+	require.Equal(t, fmt.Sprint(info.Port()), dockerInfo.NetworkSettings().Ports["5432/tcp"][0].HostPort)
+
+	require.NoError(t, dockerInfo.DockerClient().PauseContainer(dockerInfo.ContainerID()))
+	require.NoError(t, dockerInfo.DockerClient().UnpauseContainer(dockerInfo.ContainerID()))
+	require.NoError(t, pool.Ping(t.Context()))
+}
@@ -0,0 +1,37 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// duckdb driver name registered by github.com/duckdb/duckdb-go and github.com/marcboeker/go-duckdb.
+const duckDBDriverName = "duckdb"
+
+// GetDuckDBConn creates a DuckDB database in a temporary file, applies migrations,
+// and returns sql connection to the database. Docker is not used.
+// The file is removed after the test.
+// Do not forget to import a DuckDB driver package, which registers the "duckdb" driver.
+// Goose does not support DuckDB, use SQLScriptMigrateFactory("duckdb") for migrations.
+func GetDuckDBConn(tb testing.TB, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	ctx := context.Background()
+	tDB := newFileTDB(ctx, tb, duckDBDriverName, "test.duckdb", withDriverDefaults(duckDBDriverName, opt))
+
+	db, err := tDB.connectFileDB(ctx)
+	if err != nil {
+		tb.Fatalf("cannot connect to duckdb: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		if closeErr := closeResourceWithTimeout(tDB.closeTimeout, db.Close, func() string {
+			return tDB.closeTimeoutDetails("duckdb sql connection", nil)
+		}); closeErr != nil {
+			tb.Errorf("%v", closeErr)
+		}
+	})
+
+	return db, tDB
+}
@@ -0,0 +1,21 @@
+package testdock
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileTestDatabaseUsesTempFile verifies that the file test database is created in the test directory.
+func TestFileTestDatabaseUsesTempFile(t *testing.T) {
+	t.Parallel()
+
+	informer := newFileTDB(t.Context(), t, duckDBDriverName, "test.duckdb", nil)
+
+	require.Equal(t, "test.duckdb", filepath.Base(informer.DSN()))
+	require.True(t, filepath.IsAbs(informer.DSN()))
+	require.Equal(t, "test.duckdb", informer.DatabaseName())
+	require.Empty(t, informer.Host())
+	require.Zero(t, informer.Port())
+}
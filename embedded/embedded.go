@@ -0,0 +1,175 @@
+// Package embedded implements testdock.RunModeEmbedded: the PostgreSQL test database runs in an embedded server
+// without Docker (https://github.com/fergusstrange/embedded-postgres). Import the package for its side effect:
+//
+//	import _ "github.com/n-r-w/testdock/v2/embedded"
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+)
+
+// we ensure the start of embedded servers only once for all tests.
+//
+//nolint:gochecknoglobals // used to synchronize access to the same database connection string across tests.
+var (
+	globalMu      sync.Mutex
+	globalServers = make(map[string]*serverInfo)
+)
+
+// The run mode is registered when the package is imported.
+//
+//nolint:gochecknoinits // the package is imported for the registration of the run mode.
+func init() {
+	backend.RegisterServer(int(testdock.RunModeEmbedded), backend.Server{
+		Resource: "embedded server",
+		Start:    createResources,
+		Shutdown: shutdown,
+	})
+}
+
+type serverInfo struct {
+	server *embeddedpostgres.EmbeddedPostgres
+	port   int
+	count  int
+	mu     sync.Mutex
+}
+
+// createResources starts an embedded PostgreSQL server or reuses the running one.
+func createResources(ctx context.Context, d backend.ServerDatabase) error {
+	url := d.URL()
+	key := url.User + ":" + url.Password + "@" + url.Address() + "/" + url.Database
+
+	globalMu.Lock()
+	info, ok := globalServers[key]
+	if !ok {
+		info = &serverInfo{}
+		globalServers[key] = info
+	}
+	globalMu.Unlock()
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.count > 0 {
+		d.SetPort(info.port)
+		d.Logger().Info(ctx, "use existing resources", "component", "embedded", "dsn", d.LogDSN())
+	} else if err := startServer(ctx, d, info); err != nil {
+		return err
+	}
+
+	info.count++
+	d.TB().Cleanup(func() {
+		cleanupCtx := context.Background()
+
+		info.mu.Lock()
+		defer info.mu.Unlock()
+		info.count--
+
+		if info.count != 0 || info.server == nil {
+			// still in use or already stopped by ShutdownAll
+			return
+		}
+
+		if err := info.server.Stop(); err != nil {
+			d.Logger().Info(cleanupCtx, "failed to stop embedded server", "dsn", d.LogDSN(), "error", err)
+			return
+		}
+		d.Logger().Info(cleanupCtx, "embedded server stopped", "dsn", d.LogDSN())
+	})
+
+	return nil
+}
+
+// shutdown takes the servers for testdock.ShutdownAll.
+func shutdown(context.Context) []backend.ShutdownTask {
+	globalMu.Lock()
+	servers := globalServers
+	globalServers = make(map[string]*serverInfo)
+	globalMu.Unlock()
+
+	tasks := make([]backend.ShutdownTask, 0, len(servers))
+	for key, info := range servers {
+		tasks = append(tasks, backend.ShutdownTask{
+			Key: key,
+			Run: func() error {
+				info.mu.Lock()
+				defer info.mu.Unlock()
+
+				var err error
+				if info.server != nil && info.count > 0 {
+					if stopErr := info.server.Stop(); stopErr != nil {
+						err = fmt.Errorf("stop embedded server: %w", stopErr)
+					}
+				}
+				info.server = nil
+				return err
+			},
+		})
+	}
+
+	return tasks
+}
+
+// startServer starts an embedded PostgreSQL server and retries while the port is taken.
+func startServer(ctx context.Context, d backend.ServerDatabase, info *serverInfo) error {
+	const maxPortAttempts = 100
+
+	for range maxPortAttempts {
+		url := d.URL()
+		runtimePath := filepath.Join(os.TempDir(), "testdock-embedded-"+strconv.Itoa(url.Port))
+		config := embeddedpostgres.DefaultConfig().
+			Username(url.User).
+			Password(url.Password).
+			Database(url.Database).
+			Port(uint32(url.Port)). //nolint:gosec // port is validated by the DSN.
+			RuntimePath(runtimePath).
+			StartTimeout(d.StartTimeout()).
+			Logger(&loggerWriter{ctx: ctx, logger: d.Logger()})
+
+		server := embeddedpostgres.NewDatabase(config)
+		err := server.Start()
+		if err == nil {
+			info.server = server
+			info.port = url.Port
+			d.Logger().Info(ctx, "embedded server started", "dsn", d.LogDSN())
+			return nil
+		}
+
+		if !strings.Contains(err.Error(), "already listening on port") {
+			return fmt.Errorf("start embedded postgres: %w", err)
+		}
+
+		d.Logger().Info(ctx, "port is already allocated, trying next port", "dsn", d.LogDSN(), "next_port", url.Port+1)
+		d.SetPort(url.Port + 1)
+	}
+
+	return fmt.Errorf("start embedded postgres: no free port after %d attempts", maxPortAttempts)
+}
+
+// loggerWriter redirects output of external tools to the logger.
+type loggerWriter struct {
+	ctx    context.Context //nolint:containedctx // io.Writer has no context argument.
+	logger ctxlog.ILogger
+}
+
+// Write logs the output line by line.
+func (w *loggerWriter) Write(p []byte) (int, error) {
+	for line := range strings.SplitSeq(strings.TrimRight(string(p), "\n"), "\n") {
+		if line != "" {
+			w.logger.Info(w.ctx, line)
+		}
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,24 @@
+package embedded_test
+
+import (
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	_ "github.com/n-r-w/testdock/v2/embedded"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EmbeddedPostgres(t *testing.T) {
+	t.Parallel()
+
+	db, informer := testdock.GetPgxPool(t,
+		testdock.DefaultPostgresDSN,
+		testdock.WithMigrations("../migrations/pg/goose", testdock.GooseMigrateFactoryPGX),
+		testdock.WithMode(testdock.RunModeEmbedded),
+	)
+	require.NotEqual(t, "postgres", informer.DatabaseName())
+
+	var name string
+	require.NoError(t, db.QueryRow(t.Context(), "SELECT name FROM test_table").Scan(&name))
+	require.Equal(t, "test", name)
+}
@@ -0,0 +1,76 @@
+package testdock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// emulatorProjectIDLength is the length of the project ID generated for Google Cloud emulators.
+const emulatorProjectIDLength = 24
+
+// newEmulatorProjectID returns a unique project ID which satisfies Google Cloud project ID rules.
+func newEmulatorProjectID() string {
+	id := "t-" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	return id[:emulatorProjectIDLength]
+}
+
+// emulatorEndpoint returns the HTTP endpoint of the emulator from the DSN.
+func (d *testDB) emulatorEndpoint() string {
+	return "http://" + d.url.address()
+}
+
+// waitEmulator waits until the emulator responds to HTTP requests.
+func (d *testDB) waitEmulator(ctx context.Context) error {
+	d.logger.Info(ctx, "waiting for emulator", "dsn", d.dsnNoPass)
+
+	err := d.retryConnect(ctx, d.dsnNoPass, func() error {
+		_, err := emulatorRequest(ctx, http.MethodGet, d.emulatorEndpoint()+"/", nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("wait emulator: %w", err)
+	}
+
+	return nil
+}
+
+// emulatorRequest sends the request to the emulator and returns the response body.
+func emulatorRequest(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
+	return serviceRequest(ctx, method, endpoint, nil, body)
+}
+
+// serviceRequest sends the request to an HTTP service and returns the response body.
+// Responses with a status other than 2xx are returned as errors.
+func serviceRequest(ctx context.Context, method, endpoint string, header http.Header, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // body is fully read below.
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return data, nil
+}
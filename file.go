@@ -0,0 +1,87 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// newFileTDB creates a test database stored in a file of the temporary test directory
+// and applies migrations. The file is removed together with the directory after the test.
+func newFileTDB(ctx context.Context, tb testing.TB, driver, fileName string, opt []Option) *testDB {
+	tb.Helper()
+
+	db := newTestDB(tb, driver, "")
+	if err := db.prepareFileOptions(opt); err != nil {
+		tb.Fatalf("cannot create test database: %v", err)
+	}
+
+	if db.fileInMemory {
+		db.databaseName = db.newTestDatabaseName()
+		db.filePath = fmt.Sprintf("file:%s?mode=memory&cache=shared", db.databaseName)
+	} else {
+		db.databaseName = fileName
+		db.filePath = filepath.Join(tb.TempDir(), fileName)
+	}
+	db.dsnNoPass = db.filePath
+
+	db.logger.Info(ctx, "using file test database", "dsn", db.dsnNoPass)
+
+	if db.fileInMemory {
+		// the in-memory database exists while at least one connection is open
+		keepAlive, err := db.connectFileDB(ctx)
+		if err != nil {
+			tb.Fatalf("cannot create test database: %v", err)
+		}
+		tb.Cleanup(func() { _ = keepAlive.Close() })
+	}
+
+	if db.migrationsDir != "" || len(db.extraMigrations) > 0 {
+		if err := db.migrationsUp(ctx); err != nil {
+			tb.Fatalf("cannot create test database: %v", err)
+		}
+	}
+
+	if err := db.checkMigrationChecksums(ctx); err != nil {
+		tb.Fatalf("cannot create test database: %v", err)
+	}
+
+	return db
+}
+
+// prepareFileOptions validates the options of a test database stored in a file.
+func (d *testDB) prepareFileOptions(options []Option) error {
+	for _, o := range options {
+		o(d)
+	}
+	d.applyTestLabels()
+
+	if d.closeTimeout <= 0 {
+		return errors.New("closeTimeout must be greater than 0")
+	}
+	if d.driver == "" {
+		return errors.New("driver is empty")
+	}
+	if err := d.prepareDatabaseNameOptions(); err != nil {
+		return err
+	}
+
+	return d.prepareMigrationOptions()
+}
+
+// connectFileDB connects to the test database stored in a file.
+func (d *testDB) connectFileDB(ctx context.Context) (*sql.DB, error) {
+	db, err := sql.Open(d.driver, d.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("sql open (%s): %w", d.filePath, err)
+	}
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping (%s): %w", d.filePath, err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,86 @@
+package testdock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+)
+
+const (
+	// firestore driver name for separating sql and firestore.
+	firestoreDriverName = "firestore"
+	// firestoreDockerPort is the port of the Firestore emulator inside the docker container.
+	firestoreDockerPort = 8080
+)
+
+// FirestoreInformer extends Informer with the Firestore emulator connection details.
+type FirestoreInformer interface {
+	Informer
+	// EmulatorHost returns the host:port of the emulator, the value for FIRESTORE_EMULATOR_HOST.
+	EmulatorHost() string
+	// ProjectID returns the unique project ID of the test.
+	ProjectID() string
+}
+
+// GetFirestoreEmulator starts the Firestore emulator and returns the connection details
+// with a unique project ID per test, so tests do not see documents of each other.
+// The DSN format is http://host:port, the database part is ignored.
+// Use GetFirestoreClient of the github.com/n-r-w/testdock/v2/gcp module for a client of the project.
+// Documents of the project are deleted after the test in RunModeExternal.
+// Docker image: gcr.io/google.com/cloudsdktool/google-cloud-cli (emulators tag).
+func GetFirestoreEmulator(tb testing.TB, dsn string, opt ...Option) FirestoreInformer {
+	tb.Helper()
+
+	return startFirestoreEmulator(tb, dsn, opt)
+}
+
+// startFirestoreEmulator starts the emulator and generates the project ID of the test.
+func startFirestoreEmulator(tb testing.TB, dsn string, opt []Option) *firestoreInfo {
+	tb.Helper()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("gcr.io/google.com/cloudsdktool/google-cloud-cli"),
+		WithDockerImage("emulators"),
+		WithDockerPort(firestoreDockerPort),
+		WithDockerRunOptions(func(o *dockertest.RunOptions) {
+			o.Cmd = []string{"gcloud", "emulators", "firestore", "start",
+				fmt.Sprintf("--host-port=0.0.0.0:%d", firestoreDockerPort)}
+		}),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults(firestoreDriverName, opt)...)
+
+	return &firestoreInfo{testDB: newTDB(context.Background(), tb, firestoreDriverName, dsn, optPrepared)}
+}
+
+// firestoreInfo implements FirestoreInformer.
+type firestoreInfo struct {
+	*testDB
+}
+
+// EmulatorHost returns the host:port of the emulator.
+func (f *firestoreInfo) EmulatorHost() string {
+	return f.url.address()
+}
+
+// ProjectID returns the unique project ID of the test.
+func (f *firestoreInfo) ProjectID() string {
+	return f.databaseName
+}
+
+// clearFirestoreProject deletes all documents of the test project.
+func (d *testDB) clearFirestoreProject(ctx context.Context) error {
+	d.logger.Info(ctx, "deleting test project documents", "dsn", d.dsnNoPass, "project", d.databaseName)
+
+	_, err := emulatorRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/emulator/v1/projects/%s/databases/(default)/documents",
+		d.emulatorEndpoint(), d.databaseName), nil)
+	if err != nil {
+		return fmt.Errorf("clear firestore project: %w", err)
+	}
+
+	return nil
+}
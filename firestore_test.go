@@ -0,0 +1,39 @@
+package testdock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFirestoreProjectID verifies the generated project ID and the cleanup request of RunModeExternal.
+func TestFirestoreProjectID(t *testing.T) {
+	t.Parallel()
+
+	var deletePath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletePath = r.URL.Path
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newExternalTestDB(t)
+	db.driver = firestoreDriverName
+	db.dsn = srv.URL
+
+	require.NoError(t, db.prepareOptions(firestoreDriverName, nil))
+	require.Regexp(t, regexp.MustCompile(`^[a-z][a-z0-9-]{5,29}$`), db.databaseName)
+	require.NotEqual(t, db.databaseName, newEmulatorProjectID())
+
+	info := &firestoreInfo{testDB: db}
+	require.Equal(t, srv.Listener.Addr().String(), info.EmulatorHost())
+
+	require.NoError(t, db.waitEmulator(t.Context()))
+	require.NoError(t, db.clearFirestoreProject(t.Context()))
+	require.Equal(t, "/emulator/v1/projects/"+db.databaseName+"/databases/(default)/documents", deletePath)
+}
@@ -0,0 +1,190 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fixture file formats.
+const (
+	fixtureFormatCSV     = "csv"
+	fixtureFormatParquet = "parquet"
+)
+
+// LoadDuckDBFixture ingests a CSV (with header) or Parquet file into the existing table
+// with DuckDB read_csv_auto or read_parquet functions. The format is selected by the file extension.
+func LoadDuckDBFixture(tb testing.TB, db *sql.DB, table, path string) {
+	tb.Helper()
+
+	query, err := duckDBFixtureQuery(table, path)
+	if err != nil {
+		tb.Fatalf("cannot load fixture: %v", err)
+	}
+
+	if _, err = db.ExecContext(context.Background(), query); err != nil {
+		tb.Fatalf("cannot load fixture %s: %v", path, err)
+	}
+}
+
+// LoadQuestDBFixture ingests a CSV file (with header) into the table with the QuestDB HTTP import API.
+// The table is created if it does not exist. Parquet files are not supported by the import API.
+func LoadQuestDBFixture(tb testing.TB, informer QuestDBInformer, table, path string) {
+	tb.Helper()
+
+	endpoint := "http://" + net.JoinHostPort(informer.Host(), strconv.Itoa(informer.HTTPPort()))
+	if err := loadQuestDBFixture(context.Background(), endpoint, table, path); err != nil {
+		tb.Fatalf("cannot load fixture %s: %v", path, err)
+	}
+}
+
+// LoadClickHouseFixture ingests a CSV (with header) or Parquet file into the existing table
+// with the ClickHouse HTTP interface (INSERT ... FORMAT CSVWithNames or Parquet).
+// The credentials, host and database are taken from the informer DSN,
+// httpPort is the ClickHouse HTTP port (8123 by default).
+func LoadClickHouseFixture(tb testing.TB, informer Informer, httpPort int, table, path string) {
+	tb.Helper()
+
+	dsn, err := parseURL(informer.DSN())
+	if err != nil {
+		tb.Fatalf("cannot load fixture: parse dsn: %v", err)
+	}
+	dsn.Port = httpPort
+
+	if err = loadClickHouseFixture(context.Background(), dsn, table, path); err != nil {
+		tb.Fatalf("cannot load fixture %s: %v", path, err)
+	}
+}
+
+// fixtureFormat returns the format of the fixture file by its extension.
+func fixtureFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return fixtureFormatCSV, nil
+	case ".parquet", ".pq":
+		return fixtureFormatParquet, nil
+	default:
+		return "", fmt.Errorf("unsupported fixture file %s: use .csv or .parquet", path)
+	}
+}
+
+// duckDBFixtureQuery returns the query which inserts the fixture file into the table.
+func duckDBFixtureQuery(table, path string) (string, error) {
+	format, err := fixtureFormat(path)
+	if err != nil {
+		return "", err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("get absolute path: %w", err)
+	}
+	quotedPath := "'" + strings.ReplaceAll(absPath, "'", "''") + "'"
+
+	if format == fixtureFormatParquet {
+		return fmt.Sprintf("INSERT INTO %s SELECT * FROM read_parquet(%s)", table, quotedPath), nil
+	}
+
+	return fmt.Sprintf("INSERT INTO %s SELECT * FROM read_csv_auto(%s, header = true)", table, quotedPath), nil
+}
+
+// loadQuestDBFixture uploads the CSV file to the QuestDB import API.
+func loadQuestDBFixture(ctx context.Context, endpoint, table, path string) error {
+	format, err := fixtureFormat(path)
+	if err != nil {
+		return err
+	}
+	if format != fixtureFormatCSV {
+		return errors.New("questdb import supports only csv files")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read fixture: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("data", filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("create form file: %w", err)
+	}
+	if _, err = part.Write(data); err != nil {
+		return fmt.Errorf("write form file: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("close form: %w", err)
+	}
+
+	query := url.Values{"name": {table}, "header": {"true"}, "atomicity": {"abort"}, "fmt": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/imp?"+query.Encode(), &body)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return doFixtureRequest(req)
+}
+
+// loadClickHouseFixture sends the fixture file to the ClickHouse HTTP interface.
+func loadClickHouseFixture(ctx context.Context, dsn *dbURL, table, path string) error {
+	format, err := fixtureFormat(path)
+	if err != nil {
+		return err
+	}
+	clickHouseFormat := "CSVWithNames"
+	if format == fixtureFormatParquet {
+		clickHouseFormat = "Parquet"
+	}
+
+	file, err := os.Open(path) //nolint:gosec // fixture path is provided by the test.
+	if err != nil {
+		return fmt.Errorf("open fixture: %w", err)
+	}
+	defer file.Close() //nolint:errcheck // file is only read.
+
+	query := url.Values{"query": {fmt.Sprintf("INSERT INTO %s FORMAT %s", table, clickHouseFormat)}}
+	if dsn.Database != "" {
+		query.Set("database", dsn.Database)
+	}
+	endpoint := fmt.Sprintf("http://%s/?%s", dsn.address(), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, file)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("X-ClickHouse-User", dsn.User)
+	req.Header.Set("X-ClickHouse-Key", dsn.Password)
+
+	return doFixtureRequest(req)
+}
+
+// doFixtureRequest sends the fixture request and checks the response status.
+func doFixtureRequest(req *http.Request) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send fixture: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // body is fully read below.
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
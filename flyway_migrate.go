@@ -0,0 +1,183 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	// flywayLocation is the directory of the mounted migrations inside the flyway container.
+	flywayLocation = "/flyway/sql"
+	// flywayConnectRetries is the number of flyway attempts to connect to the database.
+	flywayConnectRetries = 10
+	// dockerHostGateway is the host name of the docker host inside containers.
+	dockerHostGateway = "host.docker.internal"
+)
+
+// FlywayMigrateFactory runs the flyway/flyway docker image against the test database,
+// see FlywayImageMigrateFactory.
+var FlywayMigrateFactory = FlywayImageMigrateFactory("flyway/flyway", "11")
+
+// FlywayImageMigrateFactory creates a migrator which runs the flyway docker image with the repository and tag
+// against the test database, so projects with Flyway migrations (V1__init.sql, R__views.sql, ...)
+// can use them in Go integration tests. The migrations directory is mounted into the container,
+// so the Docker daemon must have access to the host file system.
+// The container uses the docker pool of testdock, or a new pool when the database is not started in docker.
+// If the database runs in a docker container, flyway shares its network namespace,
+// otherwise connects to the DSN host, with host.docker.internal instead of the loopback address.
+// Supported DSNs: PostgreSQL URLs (postgres://...) and MySQL DSNs (user:password@tcp(host:port)/db).
+func FlywayImageMigrateFactory(repository, tag string) MigrateFactory {
+	return describeMigrateFactory(func(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		dir, err := resolveMigrationsDir(migrationsDir)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkMigrationFiles(dir, "*.sql"); err != nil {
+			return nil, err
+		}
+
+		url, err := parseURL(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("parse dsn: %w", err)
+		}
+
+		pool, err := migratorDockerPool()
+		if err != nil {
+			return nil, err
+		}
+
+		return &flywayMigrator{
+			pool:          pool,
+			repository:    repository,
+			tag:           tag,
+			url:           url,
+			migrationsDir: dir,
+			logger:        logger,
+		}, nil
+	}, "flyway %s:%s", repository, tag)
+}
+
+// flywayMigrator runs flyway in a docker container.
+type flywayMigrator struct {
+	pool          *dockertest.Pool
+	repository    string
+	tag           string
+	url           *dbURL
+	migrationsDir string
+	logger        ctxlog.ILogger
+}
+
+// Up applies all migrations.
+func (m *flywayMigrator) Up(ctx context.Context) error {
+	return m.run(ctx, "migrate")
+}
+
+// UpTo applies migrations up to and including the target version.
+func (m *flywayMigrator) UpTo(ctx context.Context, version int64) error {
+	return m.run(ctx, "migrate", "-target="+strconv.FormatInt(version, 10))
+}
+
+// run starts the flyway container with the arguments, waits for its exit and returns its output on failure.
+func (m *flywayMigrator) run(ctx context.Context, args ...string) error {
+	host, port, networkMode := migrationContainerTarget(m.pool, m.url)
+
+	jdbcURL, err := flywayJDBCURL(m.url, host, port)
+	if err != nil {
+		return err
+	}
+
+	err = runMigrationContainer(ctx, m.pool, &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Repository: m.repository,
+		Tag:        m.tag,
+		Cmd:        args,
+		Env: []string{
+			"FLYWAY_URL=" + jdbcURL,
+			"FLYWAY_USER=" + m.url.User,
+			"FLYWAY_PASSWORD=" + m.url.Password,
+			"FLYWAY_LOCATIONS=filesystem:" + flywayLocation,
+			fmt.Sprintf("FLYWAY_CONNECT_RETRIES=%d", flywayConnectRetries),
+		},
+		Mounts: []string{m.migrationsDir + ":" + flywayLocation + ":ro"},
+	}, host, networkMode)
+	if err != nil {
+		return fmt.Errorf("flyway %s: %w", strings.Join(args, " "), err)
+	}
+
+	m.logger.Info(ctx, "flyway migrations applied", "dir", m.migrationsDir)
+
+	return nil
+}
+
+// runMigrationContainer starts the container of a migration tool, waits for its exit
+// and returns its output on failure.
+func runMigrationContainer(ctx context.Context, pool *dockertest.Pool, options *dockertest.RunOptions,
+	host, networkMode string,
+) error {
+	options.Labels = map[string]string{dockerLabelSession: dockerSessionID}
+	resource, err := pool.RunWithOptions(options, func(config *docker.HostConfig) {
+		config.NetworkMode = networkMode
+		if host == dockerHostGateway {
+			config.ExtraHosts = []string{dockerHostGateway + ":host-gateway"}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("run %s container: %w", options.Repository, err)
+	}
+	defer pool.Purge(resource) //nolint:errcheck // the container has exited, keep the migration result.
+
+	exitCode, err := pool.Client.WaitContainerWithContext(resource.Container.ID, ctx)
+	if err != nil {
+		return fmt.Errorf("wait %s container: %w", options.Repository, err)
+	}
+
+	var output bytes.Buffer
+	_ = pool.Client.Logs(docker.LogsOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:      ctx,
+		Container:    resource.Container.ID,
+		OutputStream: &output,
+		ErrorStream:  &output,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if exitCode != 0 {
+		return fmt.Errorf("exited with code %d: %s", exitCode, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+// migrationContainerTarget returns the host and the port of the database for the container of a migration tool
+// and the docker network mode. For databases in testdock containers the tool joins the network namespace
+// of the container.
+func migrationContainerTarget(pool *dockertest.Pool, url *dbURL) (string, int, string) {
+	if id, port, ok := findTestdockContainer(pool, url.Port); ok {
+		return "127.0.0.1", port, "container:" + id
+	}
+
+	if isLoopbackHost(url.Host) {
+		return dockerHostGateway, url.Port, ""
+	}
+
+	return url.Host, url.Port, ""
+}
+
+// flywayJDBCURL returns the JDBC URL of the database from the DSN with the host and the port.
+func flywayJDBCURL(url *dbURL, host string, port int) (string, error) {
+	switch {
+	case url.Protocol == "postgres" || url.Protocol == "postgresql":
+		return fmt.Sprintf("jdbc:postgresql://%s/%s", net.JoinHostPort(host, strconv.Itoa(port)), url.Database), nil
+	case url.Protocol == "mysql" || url.Transport == "tcp":
+		return fmt.Sprintf("jdbc:mysql://%s/%s", net.JoinHostPort(host, strconv.Itoa(port)), url.Database), nil
+	default:
+		return "", fmt.Errorf("flyway does not support dsn %s", url.string(true))
+	}
+}
@@ -0,0 +1,89 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// pgSyntaxErrorCode is the PostgreSQL SQLSTATE of syntax_error, returned for WITH (FORCE) before PostgreSQL 13.
+const pgSyntaxErrorCode = "42601"
+
+// dropTestDatabase removes the test database, even if the code under test leaked connections to it.
+// PostgreSQL 13+ closes the connections with DROP DATABASE ... WITH (FORCE), older versions terminate them
+// with pg_terminate_backend before the drop. MySQL compatible databases kill the connections first.
+func (d *testDB) dropTestDatabase(ctx context.Context, db *sql.DB) error {
+	query := d.dropDatabaseQuery()
+
+	switch {
+	case d.schemaName != "":
+		// the connections to the database are used by other tests
+	case isPostgresDriver(d.driver):
+		_, err := db.ExecContext(ctx, query+" WITH (FORCE)")
+		if err == nil || !isPostgresSyntaxError(err) {
+			return err
+		}
+		if err = disconnectUsers(db, d.databaseName); err != nil {
+			return fmt.Errorf("disconnect users: %w", err)
+		}
+	case d.driver == "mysql":
+		if err := killMySQLConnections(ctx, db, d.databaseName); err != nil {
+			d.logger.Info(ctx, "failed to kill connections", "dsn", d.dsnNoPass, "error", err)
+		}
+	}
+
+	_, err := db.ExecContext(ctx, query)
+
+	return err
+}
+
+// isPostgresSyntaxError reports that PostgreSQL rejected the statement as a syntax error.
+func isPostgresSyntaxError(err error) bool {
+	var (
+		pgErr *pgconn.PgError
+		pqErr *pq.Error
+	)
+
+	switch {
+	case errors.As(err, &pgErr):
+		return pgErr.Code == pgSyntaxErrorCode
+	case errors.As(err, &pqErr):
+		return pqErr.Code == pgSyntaxErrorCode
+	default:
+		return false
+	}
+}
+
+// killMySQLConnections kills the connections of other sessions to the database,
+// which otherwise block DROP DATABASE with metadata locks of their open transactions.
+// Connections which exit in the meantime are ignored.
+func killMySQLConnections(ctx context.Context, db *sql.DB, database string) error {
+	rows, err := db.QueryContext(ctx, `SELECT id FROM information_schema.processlist
+		WHERE db = ? AND id <> CONNECTION_ID()`, database)
+	if err != nil {
+		return fmt.Errorf("list connections: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err reports the iteration errors.
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return fmt.Errorf("scan connection: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("list connections: %w", err)
+	}
+
+	for _, id := range ids {
+		_, _ = db.ExecContext(ctx, fmt.Sprintf("KILL %d", id))
+	}
+
+	return nil
+}
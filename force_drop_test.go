@@ -0,0 +1,74 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// dropTestConnector records the executed statements and rejects WITH (FORCE) like PostgreSQL before 13.
+// With exists it fails CREATE DATABASE like for a database of another test.
+type dropTestConnector struct {
+	mu      sync.Mutex
+	queries []string
+	noForce bool
+	exists  bool
+}
+
+func (c *dropTestConnector) Connect(context.Context) (driver.Conn, error) {
+	return dropTestConn{c: c}, nil
+}
+
+func (c *dropTestConnector) Open(string) (driver.Conn, error) { return dropTestConn{c: c}, nil }
+
+func (c *dropTestConnector) Driver() driver.Driver { return c }
+
+type dropTestConn struct {
+	c *dropTestConnector
+}
+
+func (c dropTestConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+
+func (c dropTestConn) Close() error { return nil }
+
+func (c dropTestConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+func (c dropTestConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.c.mu.Lock()
+	defer c.c.mu.Unlock()
+
+	c.c.queries = append(c.c.queries, query)
+	if c.c.noForce && strings.HasSuffix(query, "WITH (FORCE)") {
+		return nil, &pgconn.PgError{Code: pgSyntaxErrorCode} //nolint:exhaustruct // only the code is checked.
+	}
+	if c.c.exists && strings.HasPrefix(query, "CREATE DATABASE") {
+		return nil, &pgconn.PgError{Code: pgDuplicateDatabaseCode} //nolint:exhaustruct // only the code is checked.
+	}
+
+	return driver.RowsAffected(0), nil
+}
+
+// TestDropTestDatabase verifies the forced drop of PostgreSQL databases and the fallback before PostgreSQL 13.
+func TestDropTestDatabase(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.databaseName = "t_force"
+
+	connector := &dropTestConnector{}
+	require.NoError(t, db.dropTestDatabase(t.Context(), sql.OpenDB(connector)))
+	require.Equal(t, []string{"DROP DATABASE t_force WITH (FORCE)"}, connector.queries)
+
+	connector = &dropTestConnector{noForce: true}
+	require.NoError(t, db.dropTestDatabase(t.Context(), sql.OpenDB(connector)))
+	require.Len(t, connector.queries, 3)
+	require.Contains(t, connector.queries[1], "pg_terminate_backend")
+	require.Equal(t, "DROP DATABASE t_force", connector.queries[2])
+}
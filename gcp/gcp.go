@@ -0,0 +1,111 @@
+// Package gcp returns the Google Cloud clients of the emulators started by testdock.
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/spanner"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"google.golang.org/api/option"
+	"google.golang.org/api/option/internaloption"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// GetSpannerClient starts the Cloud Spanner emulator like testdock.GetSpannerDatabase and returns
+// the spanner.Client of the test database. The client is closed after the test.
+func GetSpannerClient(tb testing.TB, dsn string, opt ...testdock.Option) (*spanner.Client, testdock.SpannerInformer) {
+	tb.Helper()
+
+	informer := testdock.GetSpannerDatabase(tb, dsn, opt...)
+
+	//nolint:exhaustruct // defaults of the spanner client.
+	client, err := spanner.NewClientWithConfig(context.Background(), informer.DatabasePath(),
+		spanner.ClientConfig{DisableNativeMetrics: true},
+		option.WithEndpoint("passthrough:///"+informer.EmulatorHost()),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithoutAuthentication(),
+		internaloption.SkipDialSettingsValidation(),
+	)
+	if err != nil {
+		tb.Fatalf("cannot create spanner client: %v", err)
+	}
+
+	backend.FromInformer(informer).CloseAfterTest("spanner client", func() error {
+		client.Close()
+		return nil
+	})
+
+	return client, informer
+}
+
+// GetFirestoreClient starts the Firestore emulator like testdock.GetFirestoreEmulator and returns
+// the firestore.Client of the test project. The client is connected to the emulator directly,
+// so FIRESTORE_EMULATOR_HOST is not needed, and is closed after the test.
+func GetFirestoreClient(tb testing.TB, dsn string, opt ...testdock.Option,
+) (*firestore.Client, testdock.FirestoreInformer) {
+	tb.Helper()
+
+	informer := testdock.GetFirestoreEmulator(tb, dsn, opt...)
+
+	conn, err := grpc.NewClient(informer.EmulatorHost(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(firestoreEmulatorCredentials{}))
+	if err != nil {
+		tb.Fatalf("cannot connect to firestore emulator: %v", err)
+	}
+
+	client, err := firestore.NewClient(context.Background(), informer.ProjectID(), option.WithGRPCConn(conn))
+	if err != nil {
+		_ = conn.Close()
+		tb.Fatalf("cannot create firestore client: %v", err)
+	}
+	client.UsesEmulator = true
+
+	// the client closes the connection
+	backend.FromInformer(informer).CloseAfterTest("firestore client", client.Close)
+
+	return client, informer
+}
+
+// firestoreEmulatorCredentials authorizes the requests as the owner, which the emulator accepts
+// as the admin and which bypasses the security rules.
+type firestoreEmulatorCredentials struct{}
+
+// GetRequestMetadata returns the authorization header of the owner.
+func (firestoreEmulatorCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+
+// RequireTransportSecurity allows the insecure connection to the emulator.
+func (firestoreEmulatorCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// GetPubSubClient starts the Pub/Sub emulator like testdock.GetPubSubEmulator and returns
+// the pubsub.Client of the test project. The client is connected to the emulator directly,
+// so PUBSUB_EMULATOR_HOST is not needed, and is closed after the test.
+func GetPubSubClient(tb testing.TB, dsn string, opt ...testdock.Option) (*pubsub.Client, testdock.PubSubInformer) {
+	tb.Helper()
+
+	informer := testdock.GetPubSubEmulator(tb, dsn, opt...)
+
+	client, err := pubsub.NewClient(context.Background(), informer.ProjectID(),
+		option.WithEndpoint(informer.EmulatorHost()),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithoutAuthentication(),
+		option.WithTelemetryDisabled(),
+		internaloption.SkipDialSettingsValidation(),
+	)
+	if err != nil {
+		tb.Fatalf("cannot create pubsub client: %v", err)
+	}
+
+	backend.FromInformer(informer).CloseAfterTest("pubsub client", client.Close)
+
+	return client, informer
+}
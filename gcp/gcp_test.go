@@ -0,0 +1,67 @@
+package gcp
+
+import (
+	"testing"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/spanner"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Spanner(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetSpannerClient(t,
+		testdock.DefaultSpannerDSN,
+		testdock.WithMigrations("../migrations/spanner", testdock.SpannerMigrateFactory),
+		testdock.WithMode(testdock.RunModeDocker),
+	)
+
+	_, err := client.Apply(t.Context(), []*spanner.Mutation{
+		spanner.Insert("test_table", []string{"id", "name"}, []any{int64(1), "name"}),
+	})
+	require.NoError(t, err)
+
+	row, err := client.Single().ReadRow(t.Context(), "test_table", spanner.Key{int64(1)}, []string{"name"})
+	require.NoError(t, err)
+	var name string
+	require.NoError(t, row.Column(0, &name))
+	require.Equal(t, "name", name)
+	require.Contains(t, informer.DatabasePath(), informer.DatabaseName())
+}
+
+func Test_Firestore(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetFirestoreClient(t, testdock.DefaultFirestoreDSN, testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, informer.DatabaseName(), informer.ProjectID())
+	require.NotEmpty(t, informer.EmulatorHost())
+
+	_, err := client.Collection("test").Doc("doc").Set(t.Context(), map[string]any{"name": "name"})
+	require.NoError(t, err)
+
+	snapshot, err := client.Collection("test").Doc("doc").Get(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "name", snapshot.Data()["name"])
+}
+
+func Test_PubSub(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetPubSubClient(t, testdock.DefaultPubSubDSN, testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, informer.DatabaseName(), informer.ProjectID())
+	require.NotEmpty(t, informer.EmulatorHost())
+
+	topic, err := client.TopicAdminClient.CreateTopic(t.Context(), &pubsubpb.Topic{ //nolint:exhaustruct // test topic.
+		Name: "projects/" + informer.ProjectID() + "/topics/test",
+	})
+	require.NoError(t, err)
+
+	publisher := client.Publisher(topic.GetName())
+	defer publisher.Stop()
+	result := publisher.Publish(t.Context(), &pubsub.Message{Data: []byte("data")}) //nolint:exhaustruct // test message.
+	_, err = result.Get(t.Context())
+	require.NoError(t, err)
+}
@@ -0,0 +1,322 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// ImagePullPolicy defines when the docker image of the container is pulled.
+type ImagePullPolicy int
+
+const (
+	// ImagePullIfNotPresent - pull the image only if it is not present locally. The default.
+	ImagePullIfNotPresent ImagePullPolicy = 0
+	// ImagePullAlways - pull the image before each container is created, for example for moving tags like latest.
+	ImagePullAlways ImagePullPolicy = 1
+	// ImagePullNever - never pull the image, it must be present locally, for example in offline CI.
+	ImagePullNever ImagePullPolicy = 2
+)
+
+// String returns the name of the policy.
+func (p ImagePullPolicy) String() string {
+	switch p {
+	case ImagePullIfNotPresent:
+		return "ImagePullIfNotPresent"
+	case ImagePullAlways:
+		return "ImagePullAlways"
+	case ImagePullNever:
+		return "ImagePullNever"
+	default:
+		return fmt.Sprintf("ImagePullPolicy(%d)", int(p))
+	}
+}
+
+// WithImagePullPolicy sets when the docker image is pulled. The default is ImagePullIfNotPresent.
+// Used only in RunModeDocker.
+func WithImagePullPolicy(policy ImagePullPolicy) Option {
+	return func(o *testDB) {
+		o.imagePullPolicy = policy
+	}
+}
+
+// WithRegistryAuth sets the credentials of a private docker registry or an internal mirror, which are used
+// to pull the image, for example WithRegistryAuth("ci", os.Getenv("REGISTRY_TOKEN"), "registry.example.com").
+// The server is the registry host, empty for Docker Hub. Without the option credential helpers
+// of the docker configuration are used for images with a registry host. Used only in RunModeDocker.
+func WithRegistryAuth(username, password, server string) Option {
+	return func(o *testDB) {
+		o.registryAuth = docker.AuthConfiguration{ //nolint:exhaustruct // optional SDK fields use zero values.
+			Username:      username,
+			Password:      password,
+			ServerAddress: server,
+		}
+	}
+}
+
+// prepareImagePullOptions validates WithImagePullPolicy.
+func (d *testDB) prepareImagePullOptions() error {
+	switch d.imagePullPolicy {
+	case ImagePullIfNotPresent, ImagePullAlways, ImagePullNever:
+		return nil
+	default:
+		return fmt.Errorf("unknown image pull policy %s", d.imagePullPolicy)
+	}
+}
+
+// pullDockerImage pulls the image of the container according to the pull policy.
+func (d *testDB) pullDockerImage(ctx context.Context) error {
+	auth := d.dockerRegistryAuth()
+	err := pullImage(ctx, globalDockerPool, d.logger, d.dockerRepository, d.dockerImage, d.imagePullPolicy, auth)
+	if err != nil && errors.Is(err, errImagePull) {
+		if d.registryAuth == (docker.AuthConfiguration{}) {
+			return fmt.Errorf("%w (use WithRegistryAuth for private registries)", err)
+		}
+		return fmt.Errorf("%w from %s as %s", err, d.registryAuth.ServerAddress, d.registryAuth.Username)
+	}
+
+	return err
+}
+
+// errImagePull is the error of the pull request of the image.
+var errImagePull = errors.New("pull image")
+
+// pullImage pulls the image according to the pull policy and logs the progress of the pull.
+func pullImage(ctx context.Context, pool *dockertest.Pool, logger ctxlog.ILogger, repository, tag string,
+	policy ImagePullPolicy, auth docker.AuthConfiguration,
+) error {
+	image := repository + ":" + tag
+
+	if policy != ImagePullAlways {
+		_, err := pool.Client.InspectImage(image)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, docker.ErrNoSuchImage) {
+			return fmt.Errorf("inspect image %s: %w", image, err)
+		}
+		if policy == ImagePullNever {
+			return fmt.Errorf("image %s is not present locally and the pull policy is %s", image, policy)
+		}
+	}
+
+	logger.Info(ctx, "pulling image", "component", "docker", "image", image, "policy", policy.String())
+	started := time.Now()
+	progress := newPullProgress(ctx, logger, image)
+	err := pool.Client.PullImage(docker.PullImageOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Repository:    repository,
+		Tag:           tag,
+		OutputStream:  progress,
+		RawJSONStream: true,
+		Context:       ctx,
+	}, auth)
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", errImagePull, image, err)
+	}
+
+	logger.Info(ctx, "image pulled", "component", "docker", "image", image,
+		"size", formatBytes(progress.total()), "duration", time.Since(started).Round(time.Millisecond).String())
+
+	return nil
+}
+
+// PullImages pulls the docker images which are not present locally, for example in TestMain or in a CI step
+// which warms the image cache, so the first tests do not spend their timeouts on image pulls.
+// Images have the repository:tag form, the default tag is latest. The docker daemon is taken from DOCKER_HOST,
+// credential helpers of the docker configuration are used for images with a registry host.
+// The progress is logged with the ctxlog logger of ctx or with the default ctxlog logger.
+// All images are pulled, the error lists the images which failed.
+func PullImages(ctx context.Context, images ...string) error {
+	pool, err := migratorDockerPool()
+	if err != nil {
+		return err
+	}
+
+	var logger ctxlog.ILogger
+	if l, ok := ctxlog.TryFromContext(ctx); ok {
+		logger = l
+	} else if logger, err = ctxlog.New(); err != nil {
+		return fmt.Errorf("create logger: %w", err)
+	}
+
+	var errs []error
+	for _, image := range images {
+		repository, tag := docker.ParseRepositoryTag(image)
+		if tag == "" {
+			tag = "latest"
+		}
+		if err = pullImage(ctx, pool, logger, repository, tag, ImagePullIfNotPresent,
+			registryAuthFromHelpers(repository)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pullProgressInterval is the interval of the progress messages of an image pull.
+const pullProgressInterval = 5 * time.Second
+
+// pullMessage is a JSON message of the image pull stream of the docker daemon.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+}
+
+// pullLayer is the download progress of an image layer.
+type pullLayer struct {
+	current int64
+	size    int64
+	done    bool
+}
+
+// pullProgress decodes the JSON stream of an image pull and periodically logs the downloaded size,
+// so a long pull does not look like a hang.
+type pullProgress struct {
+	ctx     context.Context //nolint:containedctx // the writer is used only during the pull.
+	logger  ctxlog.ILogger
+	image   string
+	buf     []byte
+	layers  map[string]*pullLayer
+	lastLog time.Time
+}
+
+// newPullProgress creates the progress writer of the image pull.
+func newPullProgress(ctx context.Context, logger ctxlog.ILogger, image string) *pullProgress {
+	return &pullProgress{
+		ctx:     ctx,
+		logger:  logger,
+		image:   image,
+		buf:     nil,
+		layers:  make(map[string]*pullLayer),
+		lastLog: time.Now(),
+	}
+}
+
+// Write implements io.Writer.
+func (p *pullProgress) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+
+	dec := json.NewDecoder(bytes.NewReader(p.buf))
+	var offset int64
+	for {
+		var msg pullMessage
+		if err := dec.Decode(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// not a JSON stream, the progress is unknown
+				offset = int64(len(p.buf))
+			}
+			break
+		}
+		offset = dec.InputOffset()
+		p.handle(msg)
+	}
+	p.buf = bytes.TrimLeft(p.buf[offset:], " \t\r\n")
+
+	if time.Since(p.lastLog) >= pullProgressInterval {
+		p.lastLog = time.Now()
+		var done int
+		for _, layer := range p.layers {
+			if layer.done {
+				done++
+			}
+		}
+		p.logger.Info(p.ctx, "pulling image", "component", "docker", "image", p.image,
+			"downloaded", formatBytes(p.downloaded())+" / "+formatBytes(p.total()),
+			"layers", fmt.Sprintf("%d/%d", done, len(p.layers)))
+	}
+
+	return len(data), nil
+}
+
+// handle updates the progress of the layer of the message.
+func (p *pullProgress) handle(msg pullMessage) {
+	if msg.ID == "" {
+		return
+	}
+	layer, ok := p.layers[msg.ID]
+	if !ok {
+		layer = &pullLayer{current: 0, size: 0, done: false}
+		p.layers[msg.ID] = layer
+	}
+
+	switch msg.Status {
+	case "Downloading":
+		layer.current = msg.ProgressDetail.Current
+		layer.size = msg.ProgressDetail.Total
+	case "Download complete", "Pull complete", "Already exists":
+		layer.current = layer.size
+		layer.done = true
+	}
+}
+
+// downloaded returns the downloaded size of all layers.
+func (p *pullProgress) downloaded() int64 {
+	var n int64
+	for _, layer := range p.layers {
+		n += layer.current
+	}
+
+	return n
+}
+
+// total returns the size of the layers whose size is known.
+func (p *pullProgress) total() int64 {
+	var n int64
+	for _, layer := range p.layers {
+		n += layer.size
+	}
+
+	return n
+}
+
+// formatBytes formats the size in bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// dockerRegistryAuth returns the credentials of WithRegistryAuth or of the credential helper
+// of the registry host of the repository.
+func (d *testDB) dockerRegistryAuth() docker.AuthConfiguration {
+	if d.registryAuth != (docker.AuthConfiguration{}) {
+		return d.registryAuth
+	}
+
+	return registryAuthFromHelpers(d.dockerRepository)
+}
+
+// registryAuthFromHelpers returns the credentials of the credential helper of the registry host
+// of the repository, the same way as dockertest does.
+func registryAuthFromHelpers(repository string) docker.AuthConfiguration {
+	if parts := strings.SplitN(repository, "/", 3); len(parts) == 3 { //nolint:mnd // host, namespace and name.
+		if auth, err := docker.NewAuthConfigurationsFromCredsHelpers(parts[0]); err == nil {
+			return *auth
+		}
+	}
+
+	return docker.AuthConfiguration{}
+}
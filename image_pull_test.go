@@ -0,0 +1,85 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithImagePullPolicy verifies validation of the policy and the registry credentials.
+func TestWithImagePullPolicy(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("registry.example.com/db/postgres"),
+		WithImagePullPolicy(ImagePullNever), WithRegistryAuth("ci", "secret", "registry.example.com"),
+	}))
+	require.Equal(t, ImagePullNever, db.imagePullPolicy)
+	require.Equal(t, "ImagePullNever", db.imagePullPolicy.String())
+	require.Equal(t, docker.AuthConfiguration{Username: "ci", Password: "secret", ServerAddress: "registry.example.com"}, //nolint:exhaustruct // only credentials are set.
+		db.dockerRegistryAuth())
+
+	db = newDB()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithMode(RunModeDocker), WithDockerRepository("postgres")}))
+	require.Equal(t, ImagePullIfNotPresent, db.imagePullPolicy)
+	require.Equal(t, docker.AuthConfiguration{}, db.dockerRegistryAuth())
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithImagePullPolicy(ImagePullPolicy(5)),
+	})
+	require.ErrorContains(t, err, "unknown image pull policy ImagePullPolicy(5)")
+}
+
+func Test_PgxImagePullAlways(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5549", 1)
+	pool, _ := GetPgxPool(t, dsn, WithDockerImage("17"), WithImagePullPolicy(ImagePullAlways))
+	require.NoError(t, pool.Ping(t.Context()))
+}
+
+// TestPullProgress verifies decoding of the pull stream split into arbitrary chunks.
+func TestPullProgress(t *testing.T) {
+	t.Parallel()
+
+	progress := newPullProgress(t.Context(), ctxlog.Must(ctxlog.WithTesting(t)), "postgres:17")
+	stream := `{"status":"Pulling from library/postgres","id":"17"}` + "\r\n" +
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"a"}` + "\r\n" +
+		`{"status":"Already exists","progressDetail":{},"id":"b"}` + "\r\n" +
+		`{"status":"Downloading","progressDetail":{"current":1024,"total":4096},"id":"a"}` + "\r\n" +
+		`{"status":"Downloading","progressDetail":{"current":3072,"total":4096},"id":"a"}` + "\r\n"
+	for i := 0; i < len(stream); i += 7 {
+		n, err := progress.Write([]byte(stream[i:min(i+7, len(stream))]))
+		require.NoError(t, err)
+		require.Positive(t, n)
+	}
+	require.Equal(t, int64(3072), progress.downloaded())
+	require.Equal(t, int64(4096), progress.total())
+	require.False(t, progress.layers["a"].done)
+	require.True(t, progress.layers["b"].done)
+	require.Empty(t, progress.buf)
+
+	_, err := progress.Write([]byte(`{"status":"Pull complete","progressDetail":{},"id":"a"}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(4096), progress.downloaded())
+
+	require.Equal(t, "512B", formatBytes(512))
+	require.Equal(t, "1.5KiB", formatBytes(1536))
+	require.Equal(t, "2.0GiB", formatBytes(2<<30))
+}
+
+func Test_PullImages(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, PullImages(t.Context(), "postgres:"+testPostgresImage, "alpine"))
+	require.Error(t, PullImages(t.Context(), "testdock/does-not-exist:none"))
+}
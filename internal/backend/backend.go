@@ -0,0 +1,192 @@
+// Package backend connects the backends of the testdock submodules to the test databases of the testdock package.
+// The submodules hold the backends with heavy dependencies, so the testdock module keeps its own dependency set.
+// The testdock package sets the hooks of this package in init, the submodules use them and register their
+// run mode servers.
+package backend
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/ctxlog"
+)
+
+// URL is the parsed connection string of the test database.
+type URL struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	Database string // database of the DSN or of WithConnectDatabase
+}
+
+// Address returns the host:port of the server.
+func (u URL) Address() string {
+	return net.JoinHostPort(u.Host, strconv.Itoa(u.Port))
+}
+
+// Database is the test database seen by a backend. It implements testdock.Informer.
+type Database interface {
+	// DSN returns the real database connection string.
+	DSN() string
+	// Host returns the host of the database server.
+	Host() string
+	// Port returns the port of the database server.
+	Port() int
+	// DatabaseName returns the database name for testing.
+	DatabaseName() string
+	// URL returns the parsed connection string with the port of the running server.
+	URL() URL
+	// LogDSN returns the connection string without the password for logs and errors.
+	LogDSN() string
+	// Logger returns the logger of the test.
+	Logger() ctxlog.ILogger
+	// Retry retries the operation with the retry options of the test.
+	Retry(ctx context.Context, op func() error) error
+	// CloseAfterTest closes the resource after the test with the close timeout of the test.
+	CloseAfterTest(resource string, close func() error)
+	// PublishedPort returns the host port of the container port, false without a docker container.
+	PublishedPort(port int) (int, bool)
+}
+
+// Engine creates and removes the test databases of a driver without database/sql.
+type Engine struct {
+	// Create creates the test database or waits until the server is ready.
+	Create func(ctx context.Context, d Database) error
+	// Remove removes the test database from a server which outlives the test.
+	Remove func(ctx context.Context, d Database) error
+}
+
+// Preset describes the test database started by a Get function of a submodule.
+type Preset struct {
+	Driver   string // driver name of the test database
+	Defaults string // name of the defaults of testdock.RegisterDriverDefaults
+	DataDir  string // data directory of the engine in the docker image for WithTmpfsData
+	Options  any    // []testdock.Option of the docker image, the defaults and the options of the test are applied after
+	Engine   *Engine
+}
+
+// Script is a migration file with a numeric version prefix.
+type Script struct {
+	Version int64
+	Path    string
+}
+
+// Migrator is the testdock.Migrator with testdock.VersionedMigrator.
+type Migrator interface {
+	Up(ctx context.Context) error
+	UpTo(ctx context.Context, version int64) error
+}
+
+// Container describes the docker container of the test database for the servers of other run modes.
+type Container struct {
+	Key             string            // the same key is used by the tests sharing the server
+	Image           string            // repository:tag
+	PullPolicy      string            // Always, Never or IfNotPresent
+	Entrypoint      []string          // nil keeps the entrypoint of the image
+	Cmd             []string          // nil keeps the command of the image
+	Env             []string          // KEY=value
+	Port            int               // port of the database inside the container
+	CPUs            float64           // zero means no limit
+	Memory          int64             // bytes, zero means no limit
+	Privileged      bool              // run the container in privileged mode
+	StopTimeout     time.Duration     // timeout of the graceful stop
+	Labels          map[string]string // labels of the docker containers
+	SessionLabels   map[string]string // labels of the test binary with values which fit any label restrictions
+	KeepOnFailure   bool              // keep the container running if a test which used it failed
+	FailureLogLines int               // number of log lines attached to a failed test
+	Namespace       string            // kubernetes namespace, empty for the kubeconfig default
+	KubeContext     string            // kubeconfig context, empty for the current context
+}
+
+// ServerDatabase is the test database seen by the server of a run mode.
+type ServerDatabase interface {
+	Database
+	// TB returns the test.
+	TB() testing.TB
+	// Container returns the docker container of the test database.
+	Container() Container
+	// SetPort sets the port of the running server.
+	SetPort(port int)
+	// StartTimeout returns the time limit of the start of the server.
+	StartTimeout() time.Duration
+	// CloseTimeout returns the time limit of the removal of the server.
+	CloseTimeout() time.Duration
+	// RunStartHooks executes the functions of the test database which run once after the server is started.
+	RunStartHooks(ctx context.Context) error
+}
+
+// Server starts the database servers of a run mode.
+type Server struct {
+	// Resource is the name of the resources of the server in errors, for example "kubernetes pod".
+	Resource string
+	// Start starts the server of the test database or reuses the server of another test.
+	Start func(ctx context.Context, d ServerDatabase) error
+	// Shutdown takes the running servers for testdock.ShutdownAll.
+	Shutdown func(ctx context.Context) []ShutdownTask
+}
+
+// ShutdownTask removes one server.
+type ShutdownTask struct {
+	Key string // key of the server, errors name the server by its hash
+	Run func() error
+}
+
+// hooks are set by the testdock package.
+//
+//nolint:gochecknoglobals // the testdock package can not be imported by this package.
+var (
+	// Start creates the test database of the preset with the []testdock.Option of the test.
+	Start func(tb testing.TB, dsn string, preset Preset, opt any) Database
+	// FromInformer returns the test database of the informer returned by a Get function of testdock.
+	FromInformer func(informer any) Database
+	// ParseDSN parses the connection string.
+	ParseDSN func(dsn string) (URL, error)
+	// LoadScripts finds the migration files by the pattern and sorts them by versions.
+	LoadScripts func(migrationsDir, pattern string) ([]Script, error)
+	// NewScriptMigrator returns the migrator which executes the scripts one by one and closes the connection.
+	NewScriptMigrator func(scripts []Script, exec func(ctx context.Context, script string) error, close func() error,
+		logger ctxlog.ILogger) Migrator
+)
+
+// we ensure the servers of the run modes are registered by the submodules.
+//
+//nolint:gochecknoglobals // used to register the servers in init of the submodules.
+var (
+	serversMu sync.RWMutex
+	servers   = make(map[int]Server)
+)
+
+// RegisterServer registers the server of the run mode.
+func RegisterServer(mode int, server Server) {
+	serversMu.Lock()
+	defer serversMu.Unlock()
+
+	servers[mode] = server
+}
+
+// LookupServer returns the server of the run mode.
+func LookupServer(mode int) (Server, bool) {
+	serversMu.RLock()
+	defer serversMu.RUnlock()
+
+	server, ok := servers[mode]
+	return server, ok
+}
+
+// Servers returns the registered servers.
+func Servers() []Server {
+	serversMu.RLock()
+	defer serversMu.RUnlock()
+
+	result := make([]Server, 0, len(servers))
+	for _, server := range servers {
+		result = append(result, server)
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+// Package backendtest provides the test database for the tests of the engines of the submodules.
+package backendtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+)
+
+// Database implements backend.Database for a server of the test without retries.
+type Database struct {
+	tb   testing.TB
+	url  backend.URL
+	name string
+}
+
+// New returns the test database with the name on the server of the URL.
+func New(tb testing.TB, url backend.URL, name string) *Database {
+	return &Database{tb: tb, url: url, name: name}
+}
+
+// DSN returns the address of the server.
+func (d *Database) DSN() string {
+	return d.url.Address()
+}
+
+// Host returns the host of the server.
+func (d *Database) Host() string {
+	return d.url.Host
+}
+
+// Port returns the port of the server.
+func (d *Database) Port() int {
+	return d.url.Port
+}
+
+// DatabaseName returns the name of the test database.
+func (d *Database) DatabaseName() string {
+	return d.name
+}
+
+// URL returns the URL of the server.
+func (d *Database) URL() backend.URL {
+	return d.url
+}
+
+// LogDSN returns the address of the server.
+func (d *Database) LogDSN() string {
+	return d.url.Address()
+}
+
+// Logger returns the logger of the test.
+func (d *Database) Logger() ctxlog.ILogger {
+	return ctxlog.Must(ctxlog.WithTesting(d.tb))
+}
+
+// Retry executes the operation once.
+func (d *Database) Retry(_ context.Context, op func() error) error {
+	return op()
+}
+
+// CloseAfterTest closes the resource after the test.
+func (d *Database) CloseAfterTest(_ string, closeResource func() error) {
+	d.tb.Cleanup(func() { _ = closeResource() })
+}
+
+// PublishedPort reports that there is no docker container.
+func (d *Database) PublishedPort(int) (int, bool) {
+	return 0, false
+}
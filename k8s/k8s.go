@@ -0,0 +1,442 @@
+// Package k8s implements testdock.RunModeKubernetes: the test database runs in a pod of a Kubernetes cluster
+// instead of local Docker. Import the package for its side effect:
+//
+//	import _ "github.com/n-r-w/testdock/v2/k8s"
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// managedByLabel is the standard label of the pods created by testdock.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	// containerName is the name of the database container in the pod.
+	containerName = "database"
+	// pollInterval is the interval of the pod status checks.
+	pollInterval = 500 * time.Millisecond
+	// shutdownTimeout is the timeout of the removal of a pod by testdock.ShutdownAll.
+	shutdownTimeout = 30 * time.Second
+)
+
+// we ensure the start of kubernetes pods only once for all tests.
+//
+//nolint:gochecknoglobals // used to synchronize access to the same database connection string across tests.
+var (
+	globalMu   sync.Mutex
+	globalPods = make(map[string]*podInfo)
+)
+
+// The run mode is registered when the package is imported.
+//
+//nolint:gochecknoinits // the package is imported for the registration of the run mode.
+func init() {
+	backend.RegisterServer(int(testdock.RunModeKubernetes), backend.Server{
+		Resource: "kubernetes pod",
+		Start:    createResources,
+		Shutdown: shutdown,
+	})
+}
+
+type podInfo struct {
+	name      string
+	namespace string
+	config    *rest.Config
+	client    kubernetes.Interface
+	forward   chan struct{} // closing the channel stops the port forwarding
+	port      int           // local port of the port forwarding
+	count     int
+	mu        sync.Mutex
+}
+
+// createResources creates the pod of the database or reuses the pod of another test with the same DSN.
+func createResources(ctx context.Context, d backend.ServerDatabase) error {
+	c := d.Container()
+	key := c.Key + "|" + c.KubeContext + "/" + c.Namespace
+
+	globalMu.Lock()
+	info, ok := globalPods[key]
+	if !ok {
+		info = &podInfo{}
+		globalPods[key] = info
+	}
+	globalMu.Unlock()
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.count > 0 {
+		d.SetPort(info.port)
+		d.Logger().Info(ctx, "use existing resources", "component", "kubernetes", "dsn", d.LogDSN(), "pod", info.name)
+	} else {
+		if info.client == nil {
+			if err := info.connect(c); err != nil {
+				return err
+			}
+		}
+		if err := startPod(ctx, d, info); err != nil {
+			return err
+		}
+	}
+
+	tb := d.TB()
+	info.count++
+	tb.Cleanup(func() {
+		cleanupCtx := context.Background()
+
+		info.mu.Lock()
+		defer info.mu.Unlock()
+		info.count--
+
+		if info.count != 0 || info.name == "" {
+			// still in use or already removed by ShutdownAll
+			return
+		}
+
+		if c.KeepOnFailure && tb.Failed() {
+			keepPod(d, info)
+			return
+		}
+		if err := info.remove(cleanupCtx, d.CloseTimeout()); err != nil {
+			d.Logger().Info(cleanupCtx, "failed to remove kubernetes pod", "dsn", d.LogDSN(), "error", err)
+			return
+		}
+		d.Logger().Info(cleanupCtx, "kubernetes pod removed", "dsn", d.LogDSN())
+	})
+
+	return nil
+}
+
+// shutdown takes the pods for testdock.ShutdownAll.
+func shutdown(ctx context.Context) []backend.ShutdownTask {
+	globalMu.Lock()
+	pods := globalPods
+	globalPods = make(map[string]*podInfo)
+	globalMu.Unlock()
+
+	tasks := make([]backend.ShutdownTask, 0, len(pods))
+	for key, info := range pods {
+		tasks = append(tasks, backend.ShutdownTask{
+			Key: key,
+			Run: func() error {
+				info.mu.Lock()
+				defer info.mu.Unlock()
+
+				if info.name == "" || info.count == 0 {
+					return nil
+				}
+				return info.remove(ctx, shutdownTimeout)
+			},
+		})
+	}
+
+	return tasks
+}
+
+// connect loads the kubeconfig with the context and the namespace of the container
+// and creates the client of the pod. Without a kubeconfig the in-cluster configuration is used.
+func (p *podInfo) connect(c backend.Container) error {
+	//nolint:exhaustruct // optional overrides.
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: c.KubeContext}
+	overrides.Context.Namespace = c.Namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), overrides)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("load kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return fmt.Errorf("kubernetes namespace: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("create kubernetes client: %w", err)
+	}
+
+	p.config = config
+	p.client = client
+	p.namespace = namespace
+
+	return nil
+}
+
+// startPod creates the pod, waits until it is ready and forwards its port.
+func startPod(ctx context.Context, d backend.ServerDatabase, info *podInfo) error {
+	c := d.Container()
+	name := "testdock-" + strings.ReplaceAll(uuid.NewString(), "-", "")[:16]
+
+	_, err := info.client.CoreV1().Pods(info.namespace).Create(ctx, newPod(c, name), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("create kubernetes pod: %w", err)
+	}
+	info.name = name
+	d.Logger().Info(ctx, "kubernetes pod created", "dsn", d.LogDSN(), "pod", name, "namespace", info.namespace)
+
+	err = info.wait(ctx, d.StartTimeout())
+	if err == nil {
+		err = forwardPort(ctx, d, info)
+	}
+	if err == nil {
+		err = d.RunStartHooks(ctx)
+	}
+	if err != nil {
+		logPod(ctx, d, info)
+		if c.KeepOnFailure {
+			keepPod(d, info)
+		} else if removeErr := info.remove(context.Background(), d.CloseTimeout()); removeErr != nil {
+			d.Logger().Info(ctx, "failed to remove kubernetes pod", "dsn", d.LogDSN(), "error", removeErr)
+		}
+		info.name = ""
+		return err
+	}
+
+	return nil
+}
+
+// newPod returns the pod of the database.
+// The labels of the docker containers do not fit the restrictions of kubernetes label values,
+// so they are added as annotations.
+func newPod(c backend.Container, name string) *corev1.Pod {
+	env := make([]corev1.EnvVar, 0, len(c.Env))
+	for _, e := range c.Env {
+		key, value, _ := strings.Cut(e, "=")
+		env = append(env, corev1.EnvVar{Name: key, Value: value}) //nolint:exhaustruct // plain value.
+	}
+
+	limits := corev1.ResourceList{}
+	if c.CPUs > 0 {
+		limits[corev1.ResourceCPU] = *resource.NewMilliQuantity(int64(c.CPUs*1000), resource.DecimalSI)
+	}
+	if c.Memory > 0 {
+		limits[corev1.ResourceMemory] = *resource.NewQuantity(c.Memory, resource.BinarySI)
+	}
+
+	container := corev1.Container{ //nolint:exhaustruct // optional container fields use zero values.
+		Name:            containerName,
+		Image:           c.Image,
+		ImagePullPolicy: corev1.PullPolicy(c.PullPolicy),
+		Command:         c.Entrypoint,
+		Args:            c.Cmd,
+		Env:             env,
+		Ports: []corev1.ContainerPort{
+			{ContainerPort: int32(c.Port)}, //nolint:exhaustruct,gosec // the port is validated by the DSN.
+		},
+		Resources: corev1.ResourceRequirements{Limits: limits}, //nolint:exhaustruct // only limits.
+	}
+	if c.Privileged {
+		privileged := true
+		container.SecurityContext = &corev1.SecurityContext{Privileged: &privileged} //nolint:exhaustruct // only privileged.
+	}
+
+	labels := maps.Clone(c.SessionLabels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[managedByLabel] = "testdock"
+
+	gracePeriod := int64(c.StopTimeout / time.Second)
+
+	return &corev1.Pod{ //nolint:exhaustruct // status is set by kubernetes.
+		ObjectMeta: metav1.ObjectMeta{ //nolint:exhaustruct // optional metadata.
+			Name:        name,
+			Labels:      labels,
+			Annotations: c.Labels,
+		},
+		Spec: corev1.PodSpec{ //nolint:exhaustruct // optional pod fields use zero values.
+			Containers:                    []corev1.Container{container},
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &gracePeriod,
+		},
+	}
+}
+
+// wait waits until the pod is ready. The image is pulled by the node, so the timeout
+// must include the pull time.
+func (p *podInfo) wait(ctx context.Context, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true,
+		func(ctx context.Context) (bool, error) {
+			pod, err := p.client.CoreV1().Pods(p.namespace).Get(ctx, p.name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			switch pod.Status.Phase {
+			case corev1.PodFailed, corev1.PodSucceeded:
+				return false, fmt.Errorf("pod %s: %s %s", pod.Status.Phase, pod.Status.Reason, pod.Status.Message)
+			default:
+			}
+
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodReady {
+					return condition.Status == corev1.ConditionTrue, nil
+				}
+			}
+
+			return false, nil
+		})
+	if err != nil {
+		return fmt.Errorf("wait for kubernetes pod %s: %w", p.name, err)
+	}
+
+	return nil
+}
+
+// forwardPort forwards the port of the DSN to the pod.
+// If the port is taken, a random free port is used.
+func forwardPort(ctx context.Context, d backend.ServerDatabase, info *podInfo) error {
+	for _, localPort := range []int{d.URL().Port, 0} {
+		stop, port, err := startPortForward(ctx, d, info, localPort)
+		if err == nil {
+			info.forward = stop
+			info.port = port
+			d.SetPort(port)
+			d.Logger().Info(ctx, "kubernetes port forwarded", "dsn", d.LogDSN(), "pod", info.name)
+			return nil
+		}
+		if localPort == 0 || !strings.Contains(err.Error(), "unable to listen") {
+			return err
+		}
+
+		d.Logger().Info(ctx, "port is already allocated, using a random free port", "dsn", d.LogDSN(),
+			"port", localPort)
+	}
+
+	return nil
+}
+
+// startPortForward forwards the local port to the pod and returns the channel which stops
+// the forwarding and the local port. Zero local port selects a random free port.
+func startPortForward(ctx context.Context, d backend.ServerDatabase, info *podInfo, localPort int,
+) (chan struct{}, int, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(info.config)
+	if err != nil {
+		return nil, 0, fmt.Errorf("kubernetes port forward transport: %w", err)
+	}
+	url := info.client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(info.namespace).Name(info.name).SubResource("portforward").URL()
+	//nolint:exhaustruct // only the transport.
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	// the forwarding lives as long as the pod, so it is not bound to the context of the test
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{d.URL().Host},
+		[]string{strconv.Itoa(localPort) + ":" + strconv.Itoa(d.Container().Port)}, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return nil, 0, fmt.Errorf("kubernetes port forward: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- forwarder.ForwardPorts()
+	}()
+
+	fail := func(err error) (chan struct{}, int, error) {
+		close(stop)
+		return nil, 0, fmt.Errorf("kubernetes port forward: %w", err)
+	}
+
+	select {
+	case <-ready:
+	case err = <-done:
+		if err == nil {
+			err = errors.New("port forwarding stopped")
+		}
+		return nil, 0, fmt.Errorf("kubernetes port forward: %w", err)
+	case <-time.After(d.StartTimeout()):
+		return fail(errors.New("timeout"))
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		return fail(fmt.Errorf("forwarded ports: %w", err))
+	}
+
+	return stop, int(ports[0].Local), nil
+}
+
+// logPod logs the state and the last log lines of the pod which did not start.
+func logPod(ctx context.Context, d backend.ServerDatabase, info *podInfo) {
+	tb := d.TB()
+	pods := info.client.CoreV1().Pods(info.namespace)
+	if pod, err := pods.Get(ctx, info.name, metav1.GetOptions{}); err == nil {
+		tb.Logf("testdock kubernetes pod %s: phase %s, reason %q, message %q",
+			info.name, pod.Status.Phase, pod.Status.Reason, pod.Status.Message)
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil {
+				tb.Logf("testdock kubernetes container %s is waiting: %s %s", status.Name, waiting.Reason, waiting.Message)
+			}
+		}
+	}
+
+	tailLines := int64(d.Container().FailureLogLines)
+	//nolint:exhaustruct // optional log options.
+	logs, err := pods.GetLogs(info.name, &corev1.PodLogOptions{TailLines: &tailLines}).DoRaw(ctx)
+	if err == nil {
+		tb.Logf("testdock kubernetes pod %s logs:\n%s", info.name, strings.TrimSpace(string(logs)))
+	}
+}
+
+// keepPod leaves the pod running and tells how to remove it.
+// The port forwarding is stopped, use kubectl port-forward to connect to the database.
+func keepPod(d backend.ServerDatabase, info *podInfo) {
+	info.stopForward()
+
+	kubectl := "kubectl --namespace " + info.namespace
+	if kubeContext := d.Container().KubeContext; kubeContext != "" {
+		kubectl += " --context " + kubeContext
+	}
+	d.TB().Logf("testdock kubernetes pod %s is kept for inspection (%s), remove it with: %s delete pod %s",
+		info.name, d.LogDSN(), kubectl, info.name)
+}
+
+// stopForward stops the port forwarding.
+func (p *podInfo) stopForward() {
+	if p.forward != nil {
+		close(p.forward)
+		p.forward = nil
+	}
+}
+
+// remove stops the port forwarding and deletes the pod without waiting for its termination.
+func (p *podInfo) remove(ctx context.Context, timeout time.Duration) error {
+	p.stopForward()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := p.client.CoreV1().Pods(p.namespace).Delete(ctx, p.name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete kubernetes pod %s: %w", p.name, err)
+	}
+	p.name = ""
+
+	return nil
+}
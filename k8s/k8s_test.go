@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestNewPod verifies the pod created from the docker container.
+func TestNewPod(t *testing.T) {
+	t.Parallel()
+
+	pod := newPod(backend.Container{ //nolint:exhaustruct // only the fields of the pod.
+		Image:         "postgres:17",
+		PullPolicy:    "Always",
+		Cmd:           []string{"postgres", "-c", "fsync=off"},
+		Env:           []string{"POSTGRES_PASSWORD=secret"},
+		Port:          5432,
+		CPUs:          1.5,
+		Memory:        1 << 30,
+		StopTimeout:   10 * time.Second,
+		Labels:        map[string]string{"testdock.key": "value"},
+		SessionLabels: map[string]string{"testdock.session": "session"},
+	}, "testdock-pod")
+	require.Equal(t, "testdock-pod", pod.Name)
+	require.Equal(t, map[string]string{managedByLabel: "testdock", "testdock.session": "session"}, pod.Labels)
+	require.Equal(t, "value", pod.Annotations["testdock.key"])
+	require.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	require.Equal(t, int64(10), *pod.Spec.TerminationGracePeriodSeconds)
+	require.Len(t, pod.Spec.Containers, 1)
+
+	container := pod.Spec.Containers[0]
+	require.Equal(t, "postgres:17", container.Image)
+	require.Equal(t, corev1.PullAlways, container.ImagePullPolicy)
+	require.Equal(t, []string{"postgres", "-c", "fsync=off"}, container.Args)
+	require.Equal(t, []corev1.EnvVar{{Name: "POSTGRES_PASSWORD", Value: "secret"}}, container.Env)
+	require.Len(t, container.Ports, 1)
+	require.Equal(t, int32(5432), container.Ports[0].ContainerPort)
+	require.Equal(t, "1500m", container.Resources.Limits.Cpu().String())
+	require.Equal(t, "1Gi", container.Resources.Limits.Memory().String())
+	require.Nil(t, container.SecurityContext)
+}
+
+// TestPodWaitAndRemove verifies the readiness check and the removal of the pod.
+func TestPodWaitAndRemove(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pod := &corev1.Pod{ //nolint:exhaustruct // test pod.
+		ObjectMeta: metav1.ObjectMeta{Name: "testdock-pod", Namespace: "ci"}, //nolint:exhaustruct // test pod.
+	}
+	client := fake.NewClientset(pod)
+	info := &podInfo{name: pod.Name, namespace: pod.Namespace, client: client}
+
+	// not ready yet
+	require.ErrorContains(t, info.wait(ctx, time.Second), "wait for kubernetes pod testdock-pod")
+
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	_, err := client.CoreV1().Pods("ci").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, info.wait(ctx, time.Second))
+
+	pod.Status.Phase = corev1.PodFailed
+	pod.Status.Reason = "Evicted"
+	_, err = client.CoreV1().Pods("ci").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.ErrorContains(t, info.wait(ctx, time.Second), "Evicted")
+
+	require.NoError(t, info.remove(ctx, time.Second))
+	require.Empty(t, info.name)
+	_, err = client.CoreV1().Pods("ci").Get(ctx, pod.Name, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err))
+
+	// the pod was removed by somebody else
+	info.name = pod.Name
+	require.NoError(t, info.remove(ctx, time.Second))
+}
@@ -0,0 +1,63 @@
+package testdock
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithKubernetesNamespace sets the namespace of the pod in RunModeKubernetes.
+// The default is the namespace of the current kubeconfig context.
+// The service account of the tests needs permissions to create, delete and port-forward pods in the namespace.
+func WithKubernetesNamespace(namespace string) Option {
+	return func(o *testDB) {
+		o.kubeNamespace = namespace
+	}
+}
+
+// WithKubernetesContext sets the kubeconfig context of RunModeKubernetes.
+// The default is the current context of KUBECONFIG or ~/.kube/config, without a kubeconfig the in-cluster
+// configuration is used.
+func WithKubernetesContext(kubeContext string) Option {
+	return func(o *testDB) {
+		o.kubeContext = kubeContext
+	}
+}
+
+// prepareKubernetesOptions validates the options of RunModeKubernetes.
+// Options which depend on the local docker daemon are rejected.
+func (d *testDB) prepareKubernetesOptions(p *dbURL) error {
+	if d.dockerRepository == "" {
+		return errors.New("dockerRepository is empty")
+	}
+	if !isLoopbackHost(p.Host) {
+		return fmt.Errorf("RunModeKubernetes forwards the port to the local host, the DSN host %s is not a loopback address",
+			p.Host)
+	}
+	if d.dockerCPUs < 0 || d.dockerMemory < 0 {
+		return errors.New("docker resources must not be negative")
+	}
+
+	switch {
+	case d.dockerBuildContext != "":
+		return errors.New("WithDockerBuild is not supported in RunModeKubernetes")
+	case len(d.dockerMounts) > 0 || d.initScripts != "":
+		return errors.New("docker mounts and init scripts are not supported in RunModeKubernetes")
+	case d.tmpfsData:
+		return errors.New("WithTmpfsData is not supported in RunModeKubernetes")
+	case d.dockerNetwork != "":
+		return errors.New("WithDockerNetwork is not supported in RunModeKubernetes")
+	case d.reuseContainer != "" || d.containerName != "":
+		return errors.New("WithReuseContainer and WithContainerName are not supported in RunModeKubernetes")
+	case d.topologyRole != "" || d.mongoReplicaSet:
+		return errors.New("replica sets and multi-container topologies are not supported in RunModeKubernetes")
+	}
+
+	if d.dockerImage == "" {
+		d.dockerImage = "latest"
+	}
+	if d.dockerPort <= 0 {
+		d.dockerPort = p.Port
+	}
+
+	return nil
+}
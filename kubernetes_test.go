@@ -0,0 +1,98 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestKubernetesContainer verifies the container of the pod created from the docker options.
+func TestKubernetesContainer(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeKubernetes), WithDockerRepository("postgres"), WithDockerImage("17"),
+		WithDockerEnv([]string{"POSTGRES_PASSWORD=secret"}), WithDockerCmd("postgres", "-c", "fsync=off"),
+		WithDockerResources(1.5, 1<<30, 0), WithContainerStopTimeout(10 * time.Second),
+		WithImagePullPolicy(ImagePullAlways), WithKubernetesNamespace("ci"), WithKubernetesContext("kind"),
+	}))
+
+	container := backendDB{testDB: db}.Container()
+	require.Equal(t, db.dockerResourceKey(), container.Key)
+	require.Equal(t, "postgres:17", container.Image)
+	require.Equal(t, "Always", container.PullPolicy)
+	require.Equal(t, []string{"postgres", "-c", "fsync=off"}, container.Cmd)
+	require.Equal(t, []string{"POSTGRES_PASSWORD=secret"}, container.Env)
+	require.Equal(t, 5432, container.Port)
+	require.InDelta(t, 1.5, container.CPUs, 0)
+	require.Equal(t, int64(1<<30), container.Memory)
+	require.Equal(t, 10*time.Second, container.StopTimeout)
+	require.Equal(t, db.dockerResourceLabel(), container.Labels[dockerLabelKey])
+	require.Equal(t, map[string]string{dockerLabelSession: dockerSessionID}, container.SessionLabels)
+	require.Equal(t, "ci", container.Namespace)
+	require.Equal(t, "kind", container.KubeContext)
+}
+
+// TestKubernetesModuleRequired verifies that the run modes of the submodules require their import.
+func TestKubernetesModuleRequired(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.mode = RunModeKubernetes
+	require.ErrorContains(t, db.createServerResources(t.Context()), "RunModeKubernetes requires the import of "+
+		"github.com/n-r-w/testdock/v2/k8s")
+
+	db.mode = RunModeEmbedded
+	require.ErrorContains(t, db.createServerResources(t.Context()), "RunModeEmbedded requires the import of "+
+		"github.com/n-r-w/testdock/v2/embedded")
+}
+
+// TestPrepareKubernetesOptions verifies that options of the local docker daemon are rejected.
+func TestPrepareKubernetesOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []Option
+		wantErr string
+	}{
+		{
+			name:    "init scripts",
+			options: []Option{WithInitScripts("testdata")},
+			wantErr: "docker mounts and init scripts are not supported",
+		},
+		{
+			name:    "mounts",
+			options: []Option{WithDockerMounts("/tmp:/data")},
+			wantErr: "docker mounts and init scripts are not supported",
+		},
+		{
+			name:    "tmpfs",
+			options: []Option{WithTmpfsData()},
+			wantErr: "WithTmpfsData is not supported",
+		},
+		{
+			name:    "container name",
+			options: []Option{WithContainerName("testdock-db")},
+			wantErr: "WithContainerName are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := newExternalTestDB(t)
+			options := append([]Option{WithMode(RunModeKubernetes), WithDockerRepository("postgres")}, tt.options...)
+			require.ErrorContains(t, db.prepareOptions(db.driver, options), tt.wantErr)
+		})
+	}
+
+	db := newExternalTestDB(t)
+	db.dsn = strings.Replace(DefaultPostgresDSN, "127.0.0.1", "db.example", 1)
+	err := db.prepareOptions(db.driver, []Option{WithMode(RunModeKubernetes), WithDockerRepository("postgres")})
+	require.ErrorContains(t, err, "is not a loopback address")
+}
@@ -0,0 +1,146 @@
+package testdock
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/n-r-w/ctxlog"
+)
+
+// container labels of the test owner, the package and the binary are set for all containers,
+// the test and the team are set by WithTestLabelPropagation.
+const (
+	dockerLabelTest    = "testdock.test"
+	dockerLabelPackage = "testdock.package"
+	dockerLabelTeam    = "testdock.team"
+	dockerLabelBinary  = "testdock.binary"
+)
+
+// dockerLabelPrefix is the prefix of the labels reserved by testdock.
+const dockerLabelPrefix = "testdock."
+
+// WithDockerLabels adds the labels to the created docker containers.
+// Keys with the "testdock." prefix are reserved.
+func WithDockerLabels(labels map[string]string) Option {
+	return func(o *testDB) {
+		if o.dockerLabels == nil {
+			o.dockerLabels = make(map[string]string, len(labels))
+		}
+		maps.Copy(o.dockerLabels, labels)
+	}
+}
+
+// prepareDockerLabels validates WithDockerLabels.
+func (d *testDB) prepareDockerLabels() error {
+	for key := range d.dockerLabels {
+		if key == "" || strings.HasPrefix(key, dockerLabelPrefix) {
+			return fmt.Errorf("docker label %q is empty or reserved by testdock", key)
+		}
+	}
+
+	return nil
+}
+
+// containerLabels returns the labels of the containers of the test database.
+// Labels of testdock take precedence over the custom labels.
+func (d *testDB) containerLabels() map[string]string {
+	labels := maps.Clone(d.dockerLabels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[dockerLabelKey] = d.dockerResourceLabel()
+	labels[dockerLabelSession] = dockerSessionID
+	labels[dockerLabelPackage] = testPackageName()
+	labels[dockerLabelBinary] = filepath.Base(os.Args[0])
+	maps.Copy(labels, d.testLabelMap())
+	if d.reuseContainer != "" {
+		labels[dockerLabelReuse] = d.reuseContainer
+	}
+	if d.keepOnFailure {
+		labels[dockerLabelKeep] = "true"
+	}
+
+	return labels
+}
+
+// WithTestLabelPropagation adds the test name, the package and the optional team
+// to the labels of the created docker containers and to the log fields. The default is disabled.
+func WithTestLabelPropagation(team string) Option {
+	return func(o *testDB) {
+		o.testLabels = true
+		o.testLabelTeam = team
+	}
+}
+
+// testLabelMap returns the container labels with the test owner information.
+func (d *testDB) testLabelMap() map[string]string {
+	if !d.testLabels {
+		return nil
+	}
+
+	labels := map[string]string{
+		dockerLabelPackage: testPackageName(),
+	}
+	if d.t != nil {
+		labels[dockerLabelTest] = d.t.Name()
+	}
+	if d.testLabelTeam != "" {
+		labels[dockerLabelTeam] = d.testLabelTeam
+	}
+
+	return labels
+}
+
+// applyTestLabels adds the test owner information to the log fields.
+func (d *testDB) applyTestLabels() {
+	labels := d.testLabelMap()
+	if len(labels) == 0 || d.logger == nil {
+		return
+	}
+
+	args := make([]any, 0, len(labels)*2) //nolint:mnd // key and value.
+	for _, key := range []string{dockerLabelTest, dockerLabelPackage, dockerLabelTeam} {
+		if value, ok := labels[key]; ok {
+			args = append(args, strings.TrimPrefix(key, "testdock."), value)
+		}
+	}
+
+	d.logger = &labeledLogger{logger: d.logger, args: args}
+}
+
+// testPackageName returns the name of the test binary without the ".test" suffix.
+func testPackageName() string {
+	name := filepath.Base(os.Args[0])
+	name = strings.TrimSuffix(name, ".exe")
+	return strings.TrimSuffix(name, ".test")
+}
+
+// labeledLogger adds fixed fields to every log record.
+type labeledLogger struct {
+	logger ctxlog.ILogger
+	args   []any
+}
+
+// Debug logs a message with the fixed fields.
+func (l *labeledLogger) Debug(ctx context.Context, msg string, args ...any) {
+	l.logger.Debug(ctx, msg, append(args, l.args...)...)
+}
+
+// Info logs a message with the fixed fields.
+func (l *labeledLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.logger.Info(ctx, msg, append(args, l.args...)...)
+}
+
+// Warn logs a message with the fixed fields.
+func (l *labeledLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.logger.Warn(ctx, msg, append(args, l.args...)...)
+}
+
+// Error logs a message with the fixed fields.
+func (l *labeledLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.logger.Error(ctx, msg, append(args, l.args...)...)
+}
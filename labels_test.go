@@ -0,0 +1,63 @@
+package testdock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithTestLabelPropagation verifies container labels and log fields of the test owner.
+func TestWithTestLabelPropagation(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithTestLabelPropagation("billing")}))
+
+	labels := db.testLabelMap()
+	require.Equal(t, t.Name(), labels[dockerLabelTest])
+	require.Equal(t, testPackageName(), labels[dockerLabelPackage])
+	require.Equal(t, "billing", labels[dockerLabelTeam])
+
+	logger, ok := db.logger.(*labeledLogger)
+	require.True(t, ok)
+	require.Equal(t, []any{"test", t.Name(), "package", testPackageName(), "team", "billing"}, logger.args)
+}
+
+// TestTestLabelPropagationDisabled verifies that labels are not added by default.
+func TestTestLabelPropagationDisabled(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+
+	require.NoError(t, db.prepareOptions(db.driver, nil))
+	require.Nil(t, db.testLabelMap())
+
+	_, ok := db.logger.(*labeledLogger)
+	require.False(t, ok)
+}
+
+// TestWithDockerLabels verifies custom and default container labels and the reserved prefix.
+func TestWithDockerLabels(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithDockerLabels(map[string]string{"ci.job": "42"}),
+		WithDockerLabels(map[string]string{"ci.pipeline": "7"}),
+	}))
+
+	labels := db.containerLabels()
+	require.Equal(t, "42", labels["ci.job"])
+	require.Equal(t, "7", labels["ci.pipeline"])
+	require.Equal(t, testPackageName(), labels[dockerLabelPackage])
+	require.Equal(t, filepath.Base(os.Args[0]), labels[dockerLabelBinary])
+	require.Equal(t, dockerSessionID, labels[dockerLabelSession])
+	require.NotContains(t, labels, dockerLabelTest)
+
+	db = newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithDockerLabels(map[string]string{dockerLabelSession: "x"})})
+	require.ErrorContains(t, err, "reserved by testdock")
+}
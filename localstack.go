@@ -0,0 +1,127 @@
+package testdock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	// localstack driver name for separating sql and localstack.
+	localStackDriverName = "localstack"
+	// localStackDockerPort is the edge port of LocalStack inside the docker container.
+	localStackDockerPort = 4566
+)
+
+// LocalStackInformer extends Informer with the LocalStack connection details.
+type LocalStackInformer interface {
+	Informer
+	// Endpoint returns the edge endpoint for all services, for example http://127.0.0.1:4566.
+	// Use it as the base endpoint of the AWS SDK clients.
+	Endpoint() string
+	// Region returns the AWS region from the DSN.
+	Region() string
+	// AccessKeyID returns the access key ID accepted by LocalStack.
+	AccessKeyID() string
+	// SecretAccessKey returns the secret access key accepted by LocalStack.
+	SecretAccessKey() string
+}
+
+// GetLocalStack starts LocalStack with the services enabled (for example "s3", "sqs", "sns")
+// and waits until all of them are available. All services are enabled if services is empty.
+// The DSN format is http://host:port/region, for example DefaultLocalStackDSN.
+// Create the AWS SDK clients from AWSConfig of the github.com/n-r-w/testdock/v2/localstack module.
+// LocalStack has no namespaces, so tests share the resources and must use unique resource names.
+// Tests with the same DSN share one container, so they should request the same services.
+// Docker image: https://hub.docker.com/r/localstack/localstack.
+func GetLocalStack(tb testing.TB, dsn string, services []string, opt ...Option) LocalStackInformer {
+	tb.Helper()
+
+	ctx := context.Background()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("localstack/localstack"),
+		WithDockerImage("4.4"),
+		WithDockerPort(localStackDockerPort),
+		withoutTestDatabase(),
+	)
+	if len(services) > 0 {
+		optPrepared = append(optPrepared, WithDockerEnv([]string{"SERVICES=" + strings.Join(services, ",")}))
+	}
+
+	optPrepared = append(optPrepared, withDriverDefaults(localStackDriverName, opt)...)
+
+	tDB := newTDB(ctx, tb, localStackDriverName, dsn, optPrepared)
+
+	if err := tDB.retryConnect(ctx, tDB.dsnNoPass, func() error {
+		return tDB.checkLocalStackServices(ctx, services)
+	}); err != nil {
+		tb.Fatalf("localstack services are not available: %v", err)
+	}
+
+	return &localStackInfo{testDB: tDB}
+}
+
+// localStackInfo implements LocalStackInformer.
+type localStackInfo struct {
+	*testDB
+}
+
+// Endpoint returns the edge endpoint for all services.
+func (l *localStackInfo) Endpoint() string {
+	return l.emulatorEndpoint()
+}
+
+// Region returns the AWS region from the DSN.
+func (l *localStackInfo) Region() string {
+	return l.connectDatabase
+}
+
+// AccessKeyID returns the access key ID accepted by LocalStack.
+func (l *localStackInfo) AccessKeyID() string {
+	return "test"
+}
+
+// SecretAccessKey returns the secret access key accepted by LocalStack.
+func (l *localStackInfo) SecretAccessKey() string {
+	return "test"
+}
+
+// checkLocalStackServices checks that the requested services are available.
+func (d *testDB) checkLocalStackServices(ctx context.Context, services []string) error {
+	data, err := emulatorRequest(ctx, http.MethodGet, d.emulatorEndpoint()+"/_localstack/health", nil)
+	if err != nil {
+		return err
+	}
+
+	var health struct {
+		Services map[string]string `json:"services"`
+	}
+	if err = json.Unmarshal(data, &health); err != nil {
+		return fmt.Errorf("unmarshal health: %w", err)
+	}
+	if len(health.Services) == 0 {
+		return errors.New("localstack reports no services")
+	}
+
+	var notReady []string
+	for _, service := range services {
+		state, ok := health.Services[service]
+		if !ok {
+			state = "unknown service"
+		}
+		if state != "available" && state != "running" {
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", service, state))
+		}
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("services are not ready: %s", strings.Join(notReady, ", "))
+	}
+
+	return nil
+}
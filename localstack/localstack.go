@@ -0,0 +1,26 @@
+// Package localstack returns the AWS SDK configuration of the LocalStack instances started by testdock.
+package localstack
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/n-r-w/testdock/v2"
+)
+
+// AWSConfig returns the configuration of the AWS SDK clients with the region, the credentials
+// and the endpoint of LocalStack. Enable UsePathStyle of the S3 client, the endpoint has no bucket subdomains.
+func AWSConfig(informer testdock.LocalStackInformer) aws.Config {
+	return aws.Config{ //nolint:exhaustruct // defaults of the AWS SDK.
+		Region:       informer.Region(),
+		BaseEndpoint: aws.String(informer.Endpoint()),
+		Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(
+			func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{ //nolint:exhaustruct // static credentials do not expire.
+					AccessKeyID:     informer.AccessKeyID(),
+					SecretAccessKey: informer.SecretAccessKey(),
+					Source:          "testdock",
+				}, nil
+			})),
+	}
+}
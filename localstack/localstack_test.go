@@ -0,0 +1,44 @@
+package localstack
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LocalStack(t *testing.T) {
+	t.Parallel()
+
+	informer := testdock.GetLocalStack(t, testdock.DefaultLocalStackDSN, []string{"s3", "sqs"},
+		testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, informer.Endpoint(), aws.ToString(AWSConfig(informer).BaseEndpoint))
+}
+
+// TestAWSConfig verifies the region, the endpoint and the credentials of the AWS SDK clients.
+func TestAWSConfig(t *testing.T) {
+	t.Parallel()
+
+	config := AWSConfig(fakeInformer{})
+	require.Equal(t, "us-east-1", config.Region)
+	require.Equal(t, "http://127.0.0.1:4566", aws.ToString(config.BaseEndpoint))
+
+	credentials, err := config.Credentials.Retrieve(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "test", credentials.AccessKeyID)
+	require.Equal(t, "test", credentials.SecretAccessKey)
+	require.False(t, credentials.CanExpire)
+}
+
+// fakeInformer implements testdock.LocalStackInformer for DefaultLocalStackDSN.
+type fakeInformer struct{}
+
+func (fakeInformer) DSN() string             { return testdock.DefaultLocalStackDSN }
+func (fakeInformer) Host() string            { return "127.0.0.1" }
+func (fakeInformer) Port() int               { return 4566 } //nolint:mnd // port of the DSN.
+func (fakeInformer) DatabaseName() string    { return "" }
+func (fakeInformer) Endpoint() string        { return "http://127.0.0.1:4566" }
+func (fakeInformer) Region() string          { return "us-east-1" }
+func (fakeInformer) AccessKeyID() string     { return "test" }
+func (fakeInformer) SecretAccessKey() string { return "test" }
@@ -0,0 +1,43 @@
+package testdock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LocalStack(t *testing.T) {
+	t.Parallel()
+
+	informer := GetLocalStack(t, DefaultLocalStackDSN, []string{"s3", "sqs"}, WithMode(RunModeDocker))
+	require.Equal(t, "us-east-1", informer.Region())
+	require.NotEmpty(t, informer.Endpoint())
+}
+
+// TestCheckLocalStackServices verifies the health check of the requested services.
+func TestCheckLocalStackServices(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_localstack/health", r.URL.Path)
+		_, _ = w.Write([]byte(`{"services":{"s3":"running","sqs":"available","sns":"initializing"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newExternalTestDB(t)
+	db.driver = localStackDriverName
+	db.dsn = srv.URL + "/us-east-1"
+	require.NoError(t, db.prepareOptions(localStackDriverName, []Option{withoutTestDatabase()}))
+
+	info := &localStackInfo{testDB: db}
+	require.Equal(t, "us-east-1", info.Region())
+	require.Equal(t, srv.URL, info.Endpoint())
+
+	require.NoError(t, db.checkLocalStackServices(t.Context(), []string{"s3", "sqs"}))
+	require.NoError(t, db.checkLocalStackServices(t.Context(), nil))
+
+	err := db.checkLocalStackServices(t.Context(), []string{"sns", "dynamodb"})
+	require.ErrorContains(t, err, "sns (initializing), dynamodb (unknown service)")
+}
@@ -0,0 +1,44 @@
+package testdock
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+// mariaDBDockerPort is the port of MariaDB inside the docker container.
+const mariaDBDockerPort = 3306
+
+// GetMariaDBConn inits a test MariaDB database, applies migrations,
+// and returns sql connection (mysql driver) to the database.
+// Use user root for docker test database.
+// The container port is always 3306, so the DSN port only defines the host port.
+// Docker image: https://hub.docker.com/_/mariadb.
+func GetMariaDBConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	url, err := parseURL(dsn)
+	if err != nil {
+		tb.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	optPrepared := make([]Option, 0, len(opt))
+
+	optPrepared = append(optPrepared,
+		WithDockerRepository("mariadb"),
+		withDockerDataDir("/var/lib/mysql"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("mariadbd", "--innodb-flush-log-at-trx-commit=0"),
+		WithDockerImage("11.4"),
+		WithDockerPort(mariaDBDockerPort),
+		WithDockerEnv([]string{
+			fmt.Sprintf("MARIADB_ROOT_PASSWORD=%s", url.Password),
+			fmt.Sprintf("MARIADB_DATABASE=%s", url.Database),
+		}),
+		WithWaitStrategy(WaitForLog(mysqlReadyLog, 1)),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults("mariadb", opt)...)
+
+	return getSQLConn(tb, "mysql", dsn, optPrepared...)
+}
@@ -0,0 +1,22 @@
+package testdock
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_MariaDB(t *testing.T) {
+	t.Parallel()
+
+	db, informer := GetMariaDBConn(t,
+		DefaultMariaDBDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryMariaDB),
+		WithMode(RunModeDocker),
+		WithRetryTimeout(time.Second*5),
+		WithTotalRetryDuration(time.Second*60),
+	)
+
+	checkInformer(t, DefaultMariaDBDSN, informer)
+
+	testSQLHelper(t, db)
+}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -18,6 +21,12 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
+// migrationSet is a migrations directory with the factory of its migrator.
+type migrationSet struct {
+	dir     string
+	factory MigrateFactory
+}
+
 // MigrateFactory creates a new migrator.
 type MigrateFactory func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error)
 
@@ -33,6 +42,13 @@ type VersionedMigrator interface {
 	UpTo(ctx context.Context, version int64) error
 }
 
+// ReversibleMigrator is the contract for migration factories used with WithMigrationRoundTripCheck.
+type ReversibleMigrator interface {
+	Migrator
+	// Down rolls back all applied migrations.
+	Down(ctx context.Context) error
+}
+
 // ApplyMigrations applies all pending migrations to an existing test database.
 // The helper fails tb on invalid input, migrator creation errors, or migration errors.
 func ApplyMigrations(tb testing.TB, dsn, migrationsDir string, migrateFactory MigrateFactory) {
@@ -45,6 +61,13 @@ func ApplyMigrations(tb testing.TB, dsn, migrationsDir string, migrateFactory Mi
 	}
 }
 
+// ApplyMigrations applies all pending migrations of the directory to the test database.
+func (d *testDB) ApplyMigrations(tb testing.TB, migrationsDir string, migrateFactory MigrateFactory) {
+	tb.Helper()
+
+	ApplyMigrations(tb, d.DSN(), migrationsDir, migrateFactory)
+}
+
 // ApplyMigrationsToVersion applies pending migrations up to and including the target version.
 // The version is the numeric file prefix before "_", including timestamp prefixes.
 // Custom factories must return a migrator that implements VersionedMigrator.
@@ -93,8 +116,8 @@ func migrateUpToVersion(ctx context.Context, migrator Migrator, version int64) e
 
 	versionedMigrator, ok := migrator.(VersionedMigrator)
 	if !ok {
-		return errors.New("WithMigrationsToVersion and ApplyMigrationsToVersion require " +
-			"migrator to implement VersionedMigrator")
+		return fmt.Errorf("WithMigrationsToVersion and ApplyMigrationsToVersion require "+
+			"migrator to implement VersionedMigrator, %T does not support a target version", migrator)
 	}
 
 	return versionedMigrator.UpTo(ctx, version)
@@ -117,13 +140,25 @@ var (
 	GooseMigrateFactoryPQ = GooseMigrateFactory(goose.DialectPostgres, "postgres")
 	// GooseMigrateFactoryMySQL is a migrator for https://github.com/pressly/goose with mysql driver.
 	GooseMigrateFactoryMySQL = GooseMigrateFactory(goose.DialectMySQL, "mysql")
+	// GooseMigrateFactoryMariaDB is a migrator for https://github.com/pressly/goose with mysql driver for MariaDB.
+	// Goose has no separate MariaDB dialect, the MySQL dialect is compatible with it.
+	GooseMigrateFactoryMariaDB = GooseMigrateFactory(goose.DialectMySQL, "mysql")
+	// GooseMigrateFactorySQLite is a migrator for https://github.com/pressly/goose with github.com/mattn/go-sqlite3 driver.
+	// For modernc.org/sqlite driver use GooseMigrateFactory(goose.DialectSQLite3, "sqlite").
+	GooseMigrateFactorySQLite = GooseMigrateFactory(goose.DialectSQLite3, "sqlite3")
+	// GooseMigrateFactoryTiDB is a migrator for https://github.com/pressly/goose with mysql driver for TiDB.
+	GooseMigrateFactoryTiDB = GooseMigrateFactory(goose.DialectTiDB, "mysql")
 )
 
 // GooseMigrateFactory creates a new migrator for https://github.com/pressly/goose.
-func GooseMigrateFactory(dialect goose.Dialect, driver string) MigrateFactory {
-	return func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
-		return newGooseMigrator(t, dialect, driver, dsn, migrationsDir, logger)
-	}
+// Go migrations registered with goose.AddMigrationContext and similar functions are applied
+// for the *.go files of the migrations directory, together with SQL files in the order of versions.
+// The options are passed to goose.NewProvider after the default ones, for example
+// goose.WithTableName for a non-default version table, goose.WithAllowOutofOrder or goose.WithSessionLocker.
+func GooseMigrateFactory(dialect goose.Dialect, driver string, opts ...goose.ProviderOption) MigrateFactory {
+	return describeMigrateFactory(func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		return newGooseMigrator(t, dialect, driver, dsn, migrationsDir, logger, opts)
+	}, "goose %s %s %s", dialect, driver, gooseOptionsDescription(dialect, opts))
 }
 
 // gooseMigrator is a migrator for goose.
@@ -137,16 +172,32 @@ func newGooseMigrator(
 	dialect goose.Dialect,
 	driver, dsn, migrationsDir string,
 	logger ctxlog.ILogger,
+	opts []goose.ProviderOption,
 ) (*gooseMigrator, error) {
+	migrationsDir, err := resolveMigrationsDir(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMigrationFiles(migrationsDir, "*.sql", "*.go"); err != nil {
+		return nil, err
+	}
+
+	goMigrations, err := gooseGoMigrations(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("sql open url (%s): %w", dsn, err)
 	}
 
-	p, err := goose.NewProvider(dialect, conn, os.DirFS(migrationsDir),
+	p, err := goose.NewProvider(dialect, conn, os.DirFS(migrationsDir), append([]goose.ProviderOption{
 		goose.WithLogger(NewGooseLogger(t, logger)),
 		goose.WithVerbose(true),
-	)
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithGoMigrations(goMigrations...),
+	}, opts...)...)
 	if err != nil {
 		_ = conn.Close()
 		return nil, fmt.Errorf("new goose provider: %w", err)
@@ -157,6 +208,51 @@ func newGooseMigrator(
 	}, nil
 }
 
+// gooseRegistryMu serializes reading of the global goose registry, because goose.CollectMigrations
+// links the registered migrations with each other.
+var gooseRegistryMu sync.Mutex
+
+// gooseGoMigrations returns the Go migrations registered with goose.AddMigrationContext and similar functions
+// for the *.go files of the migrations directory, matched by versions like goose does.
+// Migrations registered for other directories of the test binary are not applied.
+// The provider modifies its migrations, so parallel tests get copies of the registered ones.
+func gooseGoMigrations(migrationsDir string) ([]*goose.Migration, error) {
+	goFiles, err := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("list go migrations: %w", err)
+	}
+	if len(goFiles) == 0 {
+		return nil, nil
+	}
+
+	gooseRegistryMu.Lock()
+	defer gooseRegistryMu.Unlock()
+
+	collected, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("collect go migrations: %w", err)
+	}
+
+	var migrations []*goose.Migration
+	for _, m := range collected {
+		if m.Type != goose.TypeGo || !m.Registered {
+			// unregistered files are reported by the provider
+			continue
+		}
+
+		mode := goose.TransactionDisabled
+		if m.UseTx {
+			mode = goose.TransactionEnabled
+		}
+		migrations = append(migrations, goose.NewGoMigration(m.Version,
+			&goose.GoFunc{RunTx: m.UpFnContext, RunDB: m.UpFnNoTxContext, Mode: mode},
+			&goose.GoFunc{RunTx: m.DownFnContext, RunDB: m.DownFnNoTxContext, Mode: mode},
+		))
+	}
+
+	return migrations, nil
+}
+
 func (m *gooseMigrator) Up(ctx context.Context) error {
 	defer m.p.Close() //nolint:errcheck // Close only releases resources; keep migration result.
 
@@ -172,7 +268,19 @@ func (m *gooseMigrator) UpTo(ctx context.Context, version int64) error {
 	return err
 }
 
+// Down rolls back all applied goose migrations.
+func (m *gooseMigrator) Down(ctx context.Context) error {
+	defer m.p.Close() //nolint:errcheck // Close only releases resources; keep migration result.
+
+	_, err := m.p.DownTo(ctx, 0)
+	return err
+}
+
 // GolangMigrateFactory creates a new migrator for https://github.com/golang-migrate/migrate.
+// The migrations directory may also be a golang-migrate source URL, for example
+// github://owner/repo/path or s3://bucket/path, to use shared migrations fetched at test time.
+// Source drivers except file:// must be imported by the test, for example
+// _ "github.com/golang-migrate/migrate/v4/source/github". Use WithMigrationsFS for embedded migrations.
 func GolangMigrateFactory(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
 	return newGolangMigrateMigrator(dsn, migrationsDir, logger)
 }
@@ -184,15 +292,12 @@ type golangMigrateMigrator struct {
 
 // newGolangMigrateMigrator creates a new migrator for https://github.com/golang-migrate/migrate.
 func newGolangMigrateMigrator(dsn, migrationsDir string, logger ctxlog.ILogger) (*golangMigrateMigrator, error) {
-	if !filepath.IsAbs(migrationsDir) {
-		var err error
-		migrationsDir, err = filepath.Abs(migrationsDir)
-		if err != nil {
-			return nil, fmt.Errorf("get absolute path: %w", err)
-		}
+	sourceURL, err := golangMigrateSourceURL(migrationsDir)
+	if err != nil {
+		return nil, err
 	}
 
-	m, err := migrate.New("file://"+migrationsDir, dsn)
+	m, err := migrate.New(sourceURL, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("new migrate: %w", err)
 	}
@@ -202,6 +307,36 @@ func newGolangMigrateMigrator(dsn, migrationsDir string, logger ctxlog.ILogger)
 	return &golangMigrateMigrator{m: m}, nil
 }
 
+// golangMigrateSourceURL returns the golang-migrate source URL of the migrations directory.
+// Source URLs are returned as is, the files of local directories are checked.
+func golangMigrateSourceURL(migrationsDir string) (string, error) {
+	if isMigrationsSourceURL(migrationsDir) {
+		return migrationsDir, nil
+	}
+
+	dir, err := resolveMigrationsDir(migrationsDir)
+	if err != nil {
+		return "", err
+	}
+	if err = checkMigrationFiles(dir, "*.up.*"); err != nil {
+		return "", err
+	}
+
+	return migrationsSourceURL(dir), nil
+}
+
+// isMigrationsSourceURL checks if the migrations directory is a source URL like github://owner/repo/path.
+// Single-letter schemes are Windows drive letters.
+func isMigrationsSourceURL(migrationsDir string) bool {
+	scheme, _, ok := strings.Cut(migrationsDir, "://")
+	if !ok || len(scheme) < 2 { //nolint:mnd // drive letters have one character.
+		return false
+	}
+
+	u, err := url.Parse(migrationsDir)
+	return err == nil && u.Scheme != ""
+}
+
 func (m *golangMigrateMigrator) Up(_ context.Context) error {
 	return m.m.Up()
 }
@@ -216,6 +351,15 @@ func (m *golangMigrateMigrator) UpTo(_ context.Context, version int64) error {
 	return m.m.Migrate(migrationVersion)
 }
 
+// Down rolls back all applied golang-migrate migrations.
+func (m *golangMigrateMigrator) Down(_ context.Context) error {
+	if err := m.m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
 // migrationVersionToUint validates that the public int64 version fits golang-migrate.
 func migrationVersionToUint(version int64) (uint, error) {
 	if err := validateMigrationVersion(version); err != nil {
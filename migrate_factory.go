@@ -0,0 +1,158 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"unsafe"
+
+	"github.com/pressly/goose/v3"
+)
+
+// we ensure the migrations hash tells apart the factories created with different parameters.
+//
+//nolint:gochecknoglobals // used to describe the factories, which are functions and can not be compared.
+var (
+	migrateFactoryMu           sync.Mutex
+	migrateFactoryDescriptions = make(map[unsafe.Pointer]string)
+)
+
+// describeMigrateFactory records the description of a factory created by a constructor of the package,
+// for example the goose dialect, driver and options.
+func describeMigrateFactory(factory MigrateFactory, format string, args ...any) MigrateFactory {
+	migrateFactoryMu.Lock()
+	migrateFactoryDescriptions[migrateFactoryID(factory)] = fmt.Sprintf(format, args...)
+	migrateFactoryMu.Unlock()
+
+	return factory
+}
+
+// migrateFactoryID returns the closure of the factory, which is different for every created factory.
+func migrateFactoryID(factory MigrateFactory) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&factory)) //nolint:gosec // a func value points to its closure.
+}
+
+// migrateFactoryDescription returns the description of the factory for the migrations hash:
+// the parameters of a factory of the package or the name of the function of another factory.
+func migrateFactoryDescription(factory MigrateFactory) string {
+	if factory == nil {
+		return ""
+	}
+
+	migrateFactoryMu.Lock()
+	description, ok := migrateFactoryDescriptions[migrateFactoryID(factory)]
+	migrateFactoryMu.Unlock()
+	if ok {
+		return description
+	}
+
+	return funcName(factory)
+}
+
+// migrateFactoriesDescription returns the descriptions of the factories separated by commas.
+func migrateFactoriesDescription(factories []MigrateFactory) string {
+	descriptions := make([]string, 0, len(factories))
+	for _, factory := range factories {
+		descriptions = append(descriptions, migrateFactoryDescription(factory))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// funcName returns the name of the function, which is the same in every build of the code.
+func funcName(f any) string {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", f)
+	}
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return ""
+}
+
+// gooseOptionsDescription describes the goose options by the names of their constructors and by the statements
+// the provider runs with them, which contain the version table of goose.WithTableName.
+func gooseOptionsDescription(dialect goose.Dialect, opts []goose.ProviderOption) string {
+	if len(opts) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(opts))
+	for _, opt := range opts {
+		names = append(names, funcName(opt))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorder := &statementRecorder{statements: nil, cancel: cancel}
+	db := sql.OpenDB(recorder)
+	defer db.Close() //nolint:errcheck // the recorder has nothing to close.
+
+	fsys := fstest.MapFS{"1_probe.sql": &fstest.MapFile{Data: []byte("-- +goose Up\n")}}
+	p, err := goose.NewProvider(dialect, db, fsys, append([]goose.ProviderOption{
+		goose.WithDisableGlobalRegistry(true),
+	}, opts...)...)
+	if err == nil {
+		// the recorder fails the first statement and stops the provider
+		_, _ = p.HasPending(ctx)
+	}
+
+	return fmt.Sprintf("%v %q", names, recorder.statements)
+}
+
+// errStatementRecorded is returned by statementRecorder for every statement.
+var errStatementRecorded = errors.New("statement recorded")
+
+// statementRecorder is a database/sql connector which records the statements and fails them.
+type statementRecorder struct {
+	statements []string
+	cancel     context.CancelFunc // stops the retries of the failed statement
+}
+
+func (r *statementRecorder) Connect(context.Context) (driver.Conn, error) { return r, nil }
+
+func (r *statementRecorder) Driver() driver.Driver { return r }
+
+func (r *statementRecorder) Open(string) (driver.Conn, error) { return r, nil }
+
+func (r *statementRecorder) Prepare(query string) (driver.Stmt, error) {
+	return nil, r.record(query, nil)
+}
+
+func (r *statementRecorder) Close() error { return nil }
+
+func (r *statementRecorder) Begin() (driver.Tx, error) { return nil, errStatementRecorded }
+
+func (r *statementRecorder) QueryContext(
+	_ context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	return nil, r.record(query, args)
+}
+
+func (r *statementRecorder) ExecContext(
+	_ context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	return nil, r.record(query, args)
+}
+
+// record adds the statement with its arguments.
+func (r *statementRecorder) record(query string, args []driver.NamedValue) error {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	r.statements = append(r.statements, fmt.Sprint(strings.Join(strings.Fields(query), " "), values))
+	r.cancel()
+
+	return errStatementRecorded
+}
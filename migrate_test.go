@@ -2,8 +2,14 @@ package testdock
 
 import (
 	"context"
+	"database/sql"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-r-w/ctxlog"
+	"github.com/pressly/goose/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,6 +19,8 @@ const (
 )
 
 // TestWithMigrationsToVersionRejectsInvalidVersion verifies early validation before migrations run.
+//
+//nolint:exhaustruct // the fields added after the fixture keep their zero values.
 func TestWithMigrationsToVersionRejectsInvalidVersion(t *testing.T) {
 	t.Parallel()
 
@@ -66,3 +74,269 @@ type upOnlyMigrator struct{}
 func (upOnlyMigrator) Up(_ context.Context) error {
 	return nil
 }
+
+// TestMigrationRoundTripCheck verifies that migrations are applied, rolled back and applied again.
+func TestMigrationRoundTripCheck(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	factory := func(_ testing.TB, _, _ string, _ ctxlog.ILogger) (Migrator, error) {
+		return &recordingMigrator{calls: &calls}, nil
+	}
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMigrations("migrations", factory),
+		WithMigrationRoundTripCheck(),
+	}))
+
+	require.NoError(t, db.migrationsUp(context.Background()))
+	require.Equal(t, []string{"up", "down", "up"}, calls)
+}
+
+// TestMigrationRoundTripCheckRequiresReversibleMigrator verifies the custom factory contract.
+func TestMigrationRoundTripCheckRequiresReversibleMigrator(t *testing.T) {
+	t.Parallel()
+
+	factory := func(_ testing.TB, _, _ string, _ ctxlog.ILogger) (Migrator, error) {
+		return upOnlyMigrator{}, nil
+	}
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMigrations("migrations", factory),
+		WithMigrationRoundTripCheck(),
+	}))
+
+	err := db.migrationsUp(context.Background())
+	require.ErrorContains(t, err, "ReversibleMigrator")
+}
+
+// TestMigrationRoundTripCheckRequiresMigrations verifies early validation of the option.
+func TestMigrationRoundTripCheckRequiresMigrations(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithMigrationRoundTripCheck()})
+	require.ErrorContains(t, err, "migration round trip check requires migrationsDir")
+
+	err = newExternalTestDB(t).prepareOptions(db.driver, []Option{WithMigrationValidation()})
+	require.ErrorContains(t, err, "migration round trip check requires migrationsDir")
+}
+
+// TestExtraMigrations verifies that extra migrations are applied in order after the round trip check.
+func TestExtraMigrations(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	factory := func(_ testing.TB, _, migrationsDir string, _ ctxlog.ILogger) (Migrator, error) {
+		calls = append(calls, migrationsDir)
+		return &recordingMigrator{calls: &calls}, nil
+	}
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithExtraMigrations("service", factory),
+		WithMigrations("platform", factory),
+		WithMigrationRoundTripCheck(),
+		WithExtraMigrations("feature", factory),
+	}))
+
+	require.NoError(t, db.migrationsUp(context.Background()))
+	require.Equal(t, []string{"platform", "up", "platform", "down", "platform", "up", "service", "up", "feature", "up"},
+		calls)
+
+	other := newExternalTestDB(t)
+	err := other.prepareOptions(other.driver, []Option{WithExtraMigrations("service", nil)})
+	require.ErrorContains(t, err, "extra migrations require migrationsDir and MigrateFactory")
+}
+
+func Test_PgxGooseProviderOptions(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose",
+			GooseMigrateFactory(goose.DialectPostgres, "pgx", goose.WithTableName("platform_db_version"))),
+		WithDockerImage(testPostgresImage),
+	)
+
+	var exists bool
+	require.NoError(t, db.QueryRow(t.Context(),
+		"SELECT to_regclass('platform_db_version') IS NOT NULL AND to_regclass('goose_db_version') IS NULL").Scan(&exists))
+	require.True(t, exists)
+}
+
+// TestGooseMigrateFactoryOptions verifies that the options are passed to the goose provider.
+func TestGooseMigrateFactoryOptions(t *testing.T) {
+	t.Parallel()
+
+	registerScriptTestDriver()
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+
+	_, err := GooseMigrateFactory(goose.DialectPostgres, "testdock_script",
+		goose.WithTableName("platform_db_version"), goose.WithAllowOutofOrder(true),
+	)(t, "goose", "migrations/pg/goose", logger)
+	require.NoError(t, err)
+
+	_, err = GooseMigrateFactory(goose.DialectPostgres, "testdock_script",
+		goose.WithTableName(""))(t, "goose", "migrations/pg/goose", logger)
+	require.ErrorContains(t, err, "table name must not be empty")
+}
+
+func Test_PgxInformerApplyMigrations(t *testing.T) {
+	t.Parallel()
+
+	db, informer := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDockerImage(testPostgresImage),
+	)
+
+	dbInfo, ok := informer.(DatabaseInformer)
+	require.True(t, ok)
+	dbInfo.ApplyMigrations(t, "migrations/pg/seed", ScriptMigrateFactory("pgx"))
+
+	requireTestTableNames(t, db, "test", "seed")
+}
+
+// TestInformerApplyMigrations verifies that the migrations are applied to the test database.
+func TestInformerApplyMigrations(t *testing.T) {
+	t.Parallel()
+
+	var dsn string
+	factory := func(_ testing.TB, migrationDSN, _ string, _ ctxlog.ILogger) (Migrator, error) {
+		dsn = migrationDSN
+		return upOnlyMigrator{}, nil
+	}
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, nil))
+	db.databaseName = "t_scratch"
+
+	db.ApplyMigrations(t, "migrations", factory)
+	require.Equal(t, db.DSN(), dsn)
+	require.Contains(t, dsn, "t_scratch")
+}
+
+func Test_PgxExtraMigrations(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithExtraMigrations("migrations/pg/seed", ScriptMigrateFactory("pgx")),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireTestTableNames(t, db, "test", "seed")
+}
+
+// TestGolangMigrateSourceURL verifies that source URLs are passed to golang-migrate as is.
+func TestGolangMigrateSourceURL(t *testing.T) {
+	t.Parallel()
+
+	for _, dir := range []string{"github://owner/repo/migrations", "s3://bucket/migrations", "file:///tmp/migrations"} {
+		sourceURL, err := golangMigrateSourceURL(dir)
+		require.NoError(t, err)
+		require.Equal(t, dir, sourceURL)
+	}
+
+	sourceURL, err := golangMigrateSourceURL("migrations/mongodb")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sourceURL, "file://"))
+
+	require.False(t, isMigrationsSourceURL(`C://migrations`))
+	require.False(t, isMigrationsSourceURL("migrations/pg"))
+
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+	_, err = GolangMigrateFactory(t, DefaultPostgresDSN, "testdock-unknown://owner/repo", logger)
+	require.ErrorContains(t, err, "unknown driver")
+}
+
+// recordingMigrator records the called migration stages.
+type recordingMigrator struct {
+	calls *[]string
+}
+
+// Up records the up stage.
+func (m *recordingMigrator) Up(_ context.Context) error {
+	*m.calls = append(*m.calls, "up")
+	return nil
+}
+
+// Down records the down stage.
+func (m *recordingMigrator) Down(_ context.Context) error {
+	*m.calls = append(*m.calls, "down")
+	return nil
+}
+
+// registerTestGooseGoMigration registers the Go migration of migrations/pg/goose_go once per test binary.
+var registerTestGooseGoMigration = sync.OnceFunc(func() {
+	goose.AddNamedMigrationContext("0002_add_go_row.go",
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO test_table (name) VALUES ('go')")
+			return err
+		},
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM test_table WHERE name = 'go'")
+			return err
+		},
+	)
+})
+
+func Test_PgxGooseGoMigrations(t *testing.T) {
+	t.Parallel()
+
+	registerTestGooseGoMigration()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose_go", GooseMigrateFactoryPGX),
+		WithMigrationRoundTripCheck(),
+		WithDockerImage(testPostgresImage),
+	)
+
+	testPgxHelper(t, db)
+	requireTestTableNames(t, db, "test", "go")
+}
+
+// requireTestTableNames checks the names in test_table.
+func requireTestTableNames(t *testing.T, db *pgxpool.Pool, want ...string) {
+	t.Helper()
+
+	rows, err := db.Query(t.Context(), "SELECT name FROM test_table ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, want, names)
+}
+
+// TestGooseGoMigrations verifies that only Go migrations of the directory files are applied.
+func TestGooseGoMigrations(t *testing.T) {
+	t.Parallel()
+
+	registerTestGooseGoMigration()
+
+	dir, err := resolveMigrationsDir("migrations/pg/goose_go")
+	require.NoError(t, err)
+	migrations, err := gooseGoMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	require.Equal(t, int64(2), migrations[0].Version)
+	require.NotNil(t, migrations[0].UpFnContext)
+	require.NotNil(t, migrations[0].DownFnContext)
+
+	dir, err = resolveMigrationsDir("migrations/pg/goose")
+	require.NoError(t, err)
+	migrations, err = gooseGoMigrations(dir)
+	require.NoError(t, err)
+	require.Empty(t, migrations)
+}
@@ -0,0 +1,200 @@
+package testdock
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// migrationChecksumTable is the table of the checksums of applied migration files in the test database.
+const migrationChecksumTable = "testdock_migration_checksums"
+
+// WithMigrationChecksums fails the test if a migration file has changed after it was applied.
+// Only databases with a database/sql driver are supported.
+func WithMigrationChecksums() Option {
+	return func(o *testDB) {
+		o.migrationChecksums = true
+	}
+}
+
+// prepareMigrationChecksumOptions validates WithMigrationChecksums.
+func (d *testDB) prepareMigrationChecksumOptions() error {
+	if !d.migrationChecksums {
+		return nil
+	}
+	if d.migrationsDir == "" && len(d.extraMigrations) == 0 {
+		return errors.New("migration checksums require migrationsDir and MigrateFactory")
+	}
+	if !isSQLDriver(d.driver) {
+		return fmt.Errorf("migration checksums are not supported for driver %s", d.driver)
+	}
+
+	return nil
+}
+
+// checkMigrationChecksums compares the migration files with the recorded checksums and records new files.
+func (d *testDB) checkMigrationChecksums(ctx context.Context) error {
+	if !d.migrationChecksums {
+		return nil
+	}
+
+	current, err := d.migrationChecksumsOfFiles()
+	if err != nil {
+		return fmt.Errorf("migration checksums: %w", err)
+	}
+
+	db, err := sql.Open(d.driver, d.DSN())
+	if err != nil {
+		return fmt.Errorf("sql open url (%s): %w", d.dsnNoPass, err)
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep check result.
+
+	if _, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationChecksumTable+
+		" (name VARCHAR(255) PRIMARY KEY, checksum VARCHAR(64) NOT NULL)"); err != nil {
+		return fmt.Errorf("create migration checksums table: %w", err)
+	}
+
+	recorded, err := readMigrationChecksums(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err = compareMigrationChecksums(recorded, current); err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := recorded[name]; ok {
+			continue
+		}
+		if _, err = db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (name, checksum) VALUES ('%s', '%s')",
+			migrationChecksumTable, strings.ReplaceAll(name, "'", "''"), current[name])); err != nil {
+			return fmt.Errorf("record migration checksum: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrationChecksumsOfFiles returns the checksums of the migration files by their names.
+// Files of WithExtraMigrations are prefixed with their directory, source URLs are skipped.
+func (d *testDB) migrationChecksumsOfFiles() (map[string]string, error) {
+	checksums := make(map[string]string)
+
+	if d.migrationsDir != "" {
+		fsys, err := d.migrationsDirFS()
+		if err != nil {
+			return nil, err
+		}
+		if err = addMigrationChecksums(checksums, fsys, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, set := range d.extraMigrations {
+		if isMigrationsSourceURL(set.dir) {
+			continue
+		}
+		dir, err := resolveMigrationsDir(set.dir)
+		if err != nil {
+			return nil, err
+		}
+		if err = addMigrationChecksums(checksums, os.DirFS(dir), path.Clean(set.dir)+"/"); err != nil {
+			return nil, err
+		}
+	}
+
+	return checksums, nil
+}
+
+// migrationsDirFS returns the file system of the migrations of WithMigrations or WithMigrationsFS.
+func (d *testDB) migrationsDirFS() (fs.FS, error) {
+	if d.migrationsFS != nil {
+		sub, err := fs.Sub(d.migrationsFS, d.migrationsDir)
+		if err != nil {
+			return nil, fmt.Errorf("migrations file system: %w", err)
+		}
+		return sub, nil
+	}
+
+	dir, err := resolveMigrationsDir(d.migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.DirFS(dir), nil
+}
+
+// addMigrationChecksums adds the checksums of the files in the root of the file system.
+func addMigrationChecksums(checksums map[string]string, fsys fs.FS, prefix string) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("read migrations directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		data, readErr := fs.ReadFile(fsys, entry.Name())
+		if readErr != nil {
+			return fmt.Errorf("read migration: %w", readErr)
+		}
+		sum := sha256.Sum256(data)
+		checksums[prefix+entry.Name()] = hex.EncodeToString(sum[:])
+	}
+
+	return nil
+}
+
+// readMigrationChecksums returns the recorded checksums by the names of the files.
+func readMigrationChecksums(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, checksum FROM "+migrationChecksumTable)
+	if err != nil {
+		return nil, fmt.Errorf("read migration checksums: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // read-only query.
+
+	recorded := make(map[string]string)
+	for rows.Next() {
+		var name, checksum string
+		if err = rows.Scan(&name, &checksum); err != nil {
+			return nil, fmt.Errorf("read migration checksums: %w", err)
+		}
+		recorded[name] = checksum
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read migration checksums: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// compareMigrationChecksums returns an error which lists the recorded files which were changed or removed.
+func compareMigrationChecksums(recorded, current map[string]string) error {
+	var changes []string
+	for _, name := range slices.Sorted(maps.Keys(recorded)) {
+		checksum, ok := current[name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("  %s: removed, applied sha256 %s", name, recorded[name]))
+		case checksum != recorded[name]:
+			changes = append(changes, fmt.Sprintf("  %s: applied sha256 %s, now %s", name, recorded[name], checksum))
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("migration files changed after they were applied, "+
+		"revert the changes and add new migrations instead:\n%s", strings.Join(changes, "\n"))
+}
@@ -0,0 +1,92 @@
+package testdock
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxMigrationChecksums(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithMigrationChecksums(),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireMigrationChecksumNames(t, db, "0001_test_migration.sql")
+}
+
+// requireMigrationChecksumNames checks the names of the files with recorded checksums.
+func requireMigrationChecksumNames(t *testing.T, db *pgxpool.Pool, want ...string) {
+	t.Helper()
+
+	rows, err := db.Query(t.Context(), "SELECT name FROM "+migrationChecksumTable+" ORDER BY name")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, want, names)
+}
+
+// TestCompareMigrationChecksums verifies that changed and removed files are reported.
+func TestCompareMigrationChecksums(t *testing.T) {
+	t.Parallel()
+
+	recorded := map[string]string{"0001_init.sql": "aaa", "0002_users.sql": "bbb", "0003_old.sql": "ccc"}
+	require.NoError(t, compareMigrationChecksums(recorded,
+		map[string]string{"0001_init.sql": "aaa", "0002_users.sql": "bbb", "0003_old.sql": "ccc", "0004_new.sql": "ddd"}))
+
+	err := compareMigrationChecksums(recorded, map[string]string{"0001_init.sql": "aaa", "0002_users.sql": "eee"})
+	require.ErrorContains(t, err, "migration files changed after they were applied")
+	require.ErrorContains(t, err, "0002_users.sql: applied sha256 bbb, now eee")
+	require.ErrorContains(t, err, "0003_old.sql: removed, applied sha256 ccc")
+	require.NotContains(t, err.Error(), "0001_init.sql")
+}
+
+// TestMigrationChecksumsOfFiles verifies the names of the files of all migration sources.
+func TestMigrationChecksumsOfFiles(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMigrationsFS(fstest.MapFS{
+			"sql/0001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE a (id INT);")},
+			"sql/.keep":         &fstest.MapFile{},
+		}, "sql", GooseMigrateFactoryPGX),
+		WithExtraMigrations("migrations/pg/seed", ScriptMigrateFactory("pgx")),
+		WithExtraMigrations("github://owner/repo/migrations", GolangMigrateFactory),
+		WithMigrationChecksums(),
+	}))
+
+	checksums, err := db.migrationChecksumsOfFiles()
+	require.NoError(t, err)
+	require.Len(t, checksums, 2)
+	require.Equal(t, "68c72ccd0cc5a7f8c8937c2debc79aff2d9b864d71f63d48fd571c5f61faf5d4", checksums["0001_init.sql"])
+	require.Len(t, checksums["migrations/pg/seed/0001_names.sql"], 64)
+}
+
+// TestPrepareMigrationChecksumOptions verifies early validation of the option.
+func TestPrepareMigrationChecksumOptions(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithMigrationChecksums()})
+	require.ErrorContains(t, err, "migration checksums require migrationsDir")
+
+	db = newExternalTestDB(t)
+	db.driver = redisDriverName
+	WithMigrations("migrations", GolangMigrateFactory)(db)
+	WithMigrationChecksums()(db)
+	require.ErrorContains(t, db.prepareMigrationChecksumOptions(), "not supported for driver redis")
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE test_table (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL
+);
+
+INSERT INTO test_table (id, name) VALUES (1, 'test');
+
+-- +goose Down
+DROP TABLE test_table;
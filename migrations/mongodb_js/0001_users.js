@@ -0,0 +1,2 @@
+db.createCollection("users");
+db.users.createIndex({ email: 1 }, { unique: true });
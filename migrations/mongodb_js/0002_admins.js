@@ -0,0 +1,3 @@
+["alice", "bob"].forEach((name) => {
+  db.users.insertOne({ _id: name, email: `${name}@example.com`, role: "admin" });
+});
@@ -0,0 +1,5 @@
+-- Create "test_table" table
+CREATE TABLE test_table (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL
+);
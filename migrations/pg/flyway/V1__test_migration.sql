@@ -0,0 +1,6 @@
+CREATE TABLE test_table (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL
+);
+
+INSERT INTO test_table (name) VALUES ('test');
@@ -0,0 +1,5 @@
+//go:build ignore
+
+// The migration is registered with goose.AddNamedMigrationContext in migrate_test.go of the testdock package,
+// this file only marks its version in the migrations directory.
+package migrations
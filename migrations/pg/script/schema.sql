@@ -0,0 +1,4 @@
+CREATE TABLE test_table (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL
+);
@@ -0,0 +1 @@
+INSERT INTO test_table (name) VALUES ('seed');
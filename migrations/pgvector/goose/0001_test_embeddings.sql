@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE test_embeddings (
+    id SERIAL PRIMARY KEY,
+    embedding VECTOR(3) NOT NULL
+);
+
+INSERT INTO test_embeddings (embedding) VALUES ('[1,0,0]'), ('[0,1,0]');
+
+-- +goose Down
+DROP TABLE test_embeddings;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE test_places (
+    id SERIAL PRIMARY KEY,
+    location GEOMETRY(POINT, 4326) NOT NULL
+);
+
+INSERT INTO test_places (location) VALUES (ST_SetSRID(ST_MakePoint(37.6173, 55.7558), 4326));
+
+-- +goose Down
+DROP TABLE test_places;
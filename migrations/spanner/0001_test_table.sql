@@ -0,0 +1,4 @@
+CREATE TABLE test_table (
+  id INT64 NOT NULL,
+  name STRING(MAX) NOT NULL
+) PRIMARY KEY (id);
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE test_metrics (
+    ts TIMESTAMPTZ NOT NULL,
+    value DOUBLE PRECISION NOT NULL
+);
+
+SELECT create_hypertable('test_metrics', 'ts');
+
+-- +goose Down
+DROP TABLE test_metrics;
@@ -0,0 +1,154 @@
+package testdock
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxListedFiles limits the number of files listed in migrations directory errors.
+const maxListedFiles = 20
+
+// resolveMigrationsDir returns the absolute migrations directory with the case of the filesystem.
+// Backslash separators are accepted on all platforms, so the same path works on Windows and Unix.
+func resolveMigrationsDir(migrationsDir string) (string, error) {
+	dir := filepath.Clean(filepath.FromSlash(migrationsDir))
+	if _, err := os.Stat(dir); err != nil && filepath.Separator != '\\' && strings.Contains(migrationsDir, `\`) {
+		dir = filepath.Clean(strings.ReplaceAll(migrationsDir, `\`, "/"))
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("migrations directory %s: get absolute path: %w", migrationsDir, err)
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("migrations directory %s: %w (%s)", abs, err, listDirFiles(filepath.Dir(abs)))
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("migrations directory %s is not a directory", abs)
+	}
+
+	return matchPathCase(abs), nil
+}
+
+// checkMigrationFiles returns an error with the directory content if no file matches any of the patterns.
+func checkMigrationFiles(dir string, patterns ...string) error {
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return fmt.Errorf("list migrations: %w", err)
+		}
+		if len(paths) > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no migrations matching %s in %s (%s)", strings.Join(patterns, ", "), dir, listDirFiles(dir))
+}
+
+// migrationsSourceURL returns the golang-migrate file:// URL for the absolute migrations directory.
+// Windows drive paths become file://C:/dir, which golang-migrate restores by joining host and path.
+func migrationsSourceURL(absDir string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(absDir)} //nolint:exhaustruct // only scheme and path are used.
+	return u.String()
+}
+
+// matchPathCase replaces each path element with the name stored on the filesystem.
+// On case-insensitive filesystems (Windows, macOS) it gives the same path for any letter case.
+func matchPathCase(path string) string {
+	volume := filepath.VolumeName(path)
+	current := volume + string(filepath.Separator)
+	for _, name := range strings.Split(strings.TrimPrefix(path[len(volume):], string(filepath.Separator)), string(filepath.Separator)) {
+		if name == "" {
+			continue
+		}
+		current = filepath.Join(current, matchEntryCase(current, name))
+	}
+
+	return current
+}
+
+// matchEntryCase returns the name of the directory entry which equals name ignoring case.
+func matchEntryCase(dir, name string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return name
+	}
+
+	folded := ""
+	for _, e := range entries {
+		if e.Name() == name {
+			return name
+		}
+		if folded == "" && strings.EqualFold(e.Name(), name) {
+			folded = e.Name()
+		}
+	}
+	if folded != "" {
+		return folded
+	}
+
+	return name
+}
+
+// listDirFiles describes the directory content for error messages.
+func listDirFiles(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Sprintf("cannot list %s: %v", dir, err)
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("%s is empty", dir)
+	}
+
+	names := make([]string, 0, min(len(entries), maxListedFiles))
+	for _, e := range entries[:min(len(entries), maxListedFiles)] {
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if len(entries) > maxListedFiles {
+		names = append(names, fmt.Sprintf("and %d more", len(entries)-maxListedFiles))
+	}
+
+	return fmt.Sprintf("found in %s: %s", dir, strings.Join(names, ", "))
+}
+
+// extractMigrationsFS copies the migrations of WithMigrationsFS to a temporary directory,
+// which is used as the migrations directory and removed after the test.
+func (d *testDB) extractMigrationsFS() error {
+	if d.migrationsFS == nil {
+		return nil
+	}
+
+	sub, err := fs.Sub(d.migrationsFS, d.migrationsDir)
+	if err != nil {
+		return fmt.Errorf("migrations file system: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "testdock-migrations-")
+	if err != nil {
+		return fmt.Errorf("create migrations directory: %w", err)
+	}
+	d.t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	if err = os.CopyFS(dir, sub); err != nil {
+		return fmt.Errorf("copy migrations file system: %w", err)
+	}
+
+	d.migrationsDir = dir
+	d.migrationsFS = nil
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package testdock
+
+import (
+	"embed"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed migrations/pg/goose
+var testGooseMigrationsFS embed.FS
+
+func Test_PgxGooseFS(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrationsFS(testGooseMigrationsFS, "migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDockerImage(testPostgresImage),
+	)
+
+	testPgxHelper(t, db)
+}
+
+// TestWithMigrationsFS verifies that the root of the file system is validated and copied to a directory.
+func TestWithMigrationsFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"db/migrations/0001_init.sql":      {Data: []byte("CREATE TABLE a (id INT);")},
+		"db/migrations/seed/0002_seed.sql": {Data: []byte("INSERT INTO a VALUES (1);")},
+	}
+
+	db := newExternalTestDB(t)
+	WithMigrationsFS(fsys, "db/migrations/", GooseMigrateFactoryPGX)(db)
+	require.NoError(t, db.prepareMigrationOptions())
+	require.NoError(t, db.extractMigrationsFS())
+	require.Nil(t, db.migrationsFS)
+
+	data, err := os.ReadFile(filepath.Join(db.migrationsDir, "seed", "0002_seed.sql"))
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO a VALUES (1);", string(data))
+
+	WithMigrationsFS(fsys, "db/missing", GooseMigrateFactoryPGX)(db)
+	require.ErrorContains(t, db.prepareMigrationOptions(), "migrations file system")
+	WithMigrationsFS(fsys, "db/migrations/0001_init.sql", GooseMigrateFactoryPGX)(db)
+	require.ErrorContains(t, db.prepareMigrationOptions(), "is not a directory")
+}
+
+// TestResolveMigrationsDir verifies absolute paths and backslash separators.
+func TestResolveMigrationsDir(t *testing.T) {
+	t.Parallel()
+
+	want, err := filepath.Abs(filepath.Join("migrations", "pg", "goose"))
+	require.NoError(t, err)
+
+	for _, dir := range []string{"migrations/pg/goose", `migrations\pg\goose`, "./migrations/pg/goose/", want} {
+		got, err := resolveMigrationsDir(dir)
+		require.NoError(t, err, dir)
+		require.Equal(t, want, got, dir)
+	}
+}
+
+// TestResolveMigrationsDirErrors verifies that errors list the files which were found.
+func TestResolveMigrationsDirErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := resolveMigrationsDir("migrations/pg/missing")
+	require.ErrorContains(t, err, "found in")
+	require.ErrorContains(t, err, "goose/")
+
+	_, err = resolveMigrationsDir("migrations/pg/goose/0001_test_migration.sql")
+	require.ErrorContains(t, err, "is not a directory")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_init.txt"), nil, 0o600))
+	err = checkMigrationFiles(dir, "*.up.*")
+	require.ErrorContains(t, err, "no migrations matching *.up.*")
+	require.ErrorContains(t, err, "0001_init.txt")
+	require.NoError(t, checkMigrationFiles(dir, "*.sql", "*.txt"))
+}
+
+// TestMatchPathCase verifies that the path gets the letter case stored on the filesystem.
+func TestMatchPathCase(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "Migrations"), 0o700))
+
+	require.Equal(t, filepath.Join(dir, "Migrations"), matchPathCase(filepath.Join(dir, "migrations")))
+	require.Equal(t, filepath.Join(dir, "other"), matchPathCase(filepath.Join(dir, "other")))
+}
+
+// TestMigrationsSourceURL verifies that golang-migrate restores the directory from the URL.
+func TestMigrationsSourceURL(t *testing.T) {
+	t.Parallel()
+
+	dirs := []string{"/tmp/my migrations#1"}
+	if runtime.GOOS == "windows" {
+		dirs = []string{`C:\my migrations\pg`}
+	}
+
+	for _, dir := range dirs {
+		u, err := url.Parse(migrationsSourceURL(dir))
+		require.NoError(t, err)
+		require.Equal(t, "file", u.Scheme)
+		// golang-migrate source/file joins host and path.
+		require.Equal(t, filepath.ToSlash(dir), u.Host+u.Path)
+	}
+
+	u, err := url.Parse(migrationsSourceURL("C:/my migrations/pg"))
+	require.NoError(t, err)
+	require.Equal(t, "C:/my migrations/pg", u.Host+u.Path)
+}
@@ -0,0 +1,283 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// mongoIndexesFile is the file of the fixtures directory with the indexes of the collections.
+const mongoIndexesFile = "indexes.json"
+
+// WithMongoFixtures loads the document fixtures of the directory into the test MongoDB database after migrations.
+// Can be used multiple times, directories are loaded in order.
+func WithMongoFixtures(dir string) Option {
+	return func(o *testDB) {
+		o.mongoFixtures = append(o.mongoFixtures, dir)
+	}
+}
+
+// prepareMongoFixturesOptions validates WithMongoFixtures.
+func (d *testDB) prepareMongoFixturesOptions() error {
+	if len(d.mongoFixtures) > 0 && d.driver != mongoDriverName {
+		return fmt.Errorf("WithMongoFixtures is not supported for driver %s", d.driver)
+	}
+
+	return nil
+}
+
+// loadMongoFixtures loads the fixtures directories into the test database.
+func (d *testDB) loadMongoFixtures(ctx context.Context) error {
+	if len(d.mongoFixtures) == 0 {
+		return nil
+	}
+
+	d.logger.Info(ctx, "loading mongo fixtures", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	client, err := d.connectMongoDBv2(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(ctx) //nolint:errcheck // Disconnect only releases setup connection; keep load result.
+
+	db := client.Database(d.databaseName)
+	for _, dir := range d.mongoFixtures {
+		if err = loadMongoFixturesDir(ctx, db, dir); err != nil {
+			return fmt.Errorf("load mongo fixtures %s: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// loadMongoFixturesDir creates the indexes and inserts the documents of the fixtures directory.
+func loadMongoFixturesDir(ctx context.Context, db *mongo.Database, dir string) error {
+	dir, err := resolveMigrationsDir(dir)
+	if err != nil {
+		return err
+	}
+
+	indexes, err := readMongoIndexes(filepath.Join(dir, mongoIndexesFile))
+	if err != nil {
+		return err
+	}
+	for _, collection := range indexes {
+		if err = db.RunCommand(ctx, bson.D{
+			{Key: "createIndexes", Value: collection.Key},
+			{Key: "indexes", Value: collection.Value},
+		}).Err(); err != nil {
+			return fmt.Errorf("create indexes of collection %s: %w", collection.Key, err)
+		}
+	}
+
+	paths, err := mongoFixtureFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		docs, readErr := readMongoFixture(path)
+		if readErr != nil {
+			return readErr
+		}
+		if len(docs) == 0 {
+			continue
+		}
+
+		collection := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, err = db.Collection(collection).InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("insert documents of %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	return nil
+}
+
+// mongoFixtureFiles returns the fixture files of the directory in the lexical order of names.
+func mongoFixtureFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read fixtures directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == mongoIndexesFile || strings.HasSuffix(name, ".metadata.json") {
+			continue
+		}
+		if ext := filepath.Ext(name); ext == ".json" || ext == ".bson" {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.json or *.bson fixtures found in %s", dir)
+	}
+	slices.Sort(paths)
+
+	return paths, nil
+}
+
+// readMongoFixture returns the documents of the fixture file.
+func readMongoFixture(path string) ([]any, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+
+	var docs []any
+	if filepath.Ext(path) == ".bson" {
+		docs, err = parseMongoBSON(data)
+	} else {
+		docs, err = parseMongoExtJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", filepath.Base(path), err)
+	}
+
+	return docs, nil
+}
+
+// parseMongoExtJSON parses an array of Extended JSON documents or documents one after another.
+func parseMongoExtJSON(data []byte) ([]any, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var wrapper struct {
+			Documents []bson.D `bson:"documents"`
+		}
+		if err := bson.UnmarshalExtJSON(
+			slices.Concat([]byte(`{"documents":`), data, []byte("}")), false, &wrapper); err != nil {
+			return nil, err
+		}
+
+		docs := make([]any, 0, len(wrapper.Documents))
+		for _, doc := range wrapper.Documents {
+			docs = append(docs, doc)
+		}
+		return docs, nil
+	}
+
+	var (
+		docs    []any
+		decoder = json.NewDecoder(bytes.NewReader(data))
+	)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(raw, false, &doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// parseMongoBSON splits concatenated BSON documents.
+func parseMongoBSON(data []byte) ([]any, error) {
+	var docs []any
+	for len(data) > 0 {
+		if len(data) < 4 { //nolint:mnd // int32 length prefix of a document.
+			return nil, errors.New("truncated bson document")
+		}
+
+		size := int(binary.LittleEndian.Uint32(data))
+		if size < 5 || size > len(data) { //nolint:mnd // the smallest document is the length and the terminator.
+			return nil, fmt.Errorf("invalid bson document size %d", size)
+		}
+
+		doc := bson.Raw(data[:size])
+		if err := doc.Validate(); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+		data = data[size:]
+	}
+
+	return docs, nil
+}
+
+// readMongoIndexes returns the index specifications of the collections from indexes.json.
+// It returns nil if the file does not exist.
+func readMongoIndexes(path string) (bson.D, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // fixture files are provided by the test.
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", mongoIndexesFile, err)
+	}
+
+	var raw map[string][]bson.D
+	if err = bson.UnmarshalExtJSON(data, false, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", mongoIndexesFile, err)
+	}
+
+	collections := make([]string, 0, len(raw))
+	for collection := range raw {
+		collections = append(collections, collection)
+	}
+	slices.Sort(collections)
+
+	indexes := make(bson.D, 0, len(raw))
+	for _, collection := range collections {
+		specs := raw[collection]
+		for i, spec := range specs {
+			if specs[i], err = mongoIndexSpec(spec); err != nil {
+				return nil, fmt.Errorf("%s: index %d of collection %s: %w", mongoIndexesFile, i+1, collection, err)
+			}
+		}
+		indexes = append(indexes, bson.E{Key: collection, Value: specs})
+	}
+
+	return indexes, nil
+}
+
+// mongoIndexSpec validates the index specification and adds the name generated from the keys
+// the way the MongoDB drivers do, for example {"email": 1, "age": -1} is named email_1_age_-1.
+func mongoIndexSpec(spec bson.D) (bson.D, error) {
+	var (
+		keys    bson.D
+		hasName bool
+	)
+	for _, e := range spec {
+		switch e.Key {
+		case "key":
+			k, ok := e.Value.(bson.D)
+			if !ok || len(k) == 0 {
+				return nil, errors.New("key must be a non-empty document")
+			}
+			keys = k
+		case "name":
+			hasName = true
+		}
+	}
+	if keys == nil {
+		return nil, errors.New("key is required")
+	}
+	if hasName {
+		return spec, nil
+	}
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", k.Key, k.Value))
+	}
+
+	return append(spec, bson.E{Key: "name", Value: strings.Join(parts, "_")}), nil
+}
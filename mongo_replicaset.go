@@ -0,0 +1,143 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ory/dockertest/v3"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// mongoReplicaSetName is the name of the replica set started by WithMongoReplicaSet.
+	mongoReplicaSetName = "rs0"
+	// mongoKeyFile is the key file for the internal authentication of the replica set inside the container.
+	mongoKeyFile = "/data/testdock-keyfile"
+	// mongoAlreadyInitializedCode is the error code of replSetInitiate for an initialized replica set.
+	mongoAlreadyInitializedCode = 23
+)
+
+// WithMongoReplicaSet starts MongoDB as a single-node replica set, which is required for multi-document transactions.
+// The default is a standalone server.
+func WithMongoReplicaSet() Option {
+	return func(o *testDB) {
+		o.mongoReplicaSet = true
+	}
+}
+
+// prepareMongoReplicaSetOptions validates WithMongoReplicaSet and configures the docker container.
+func (d *testDB) prepareMongoReplicaSetOptions() error {
+	if !d.mongoReplicaSet {
+		return nil
+	}
+	if d.driver != mongoDriverName {
+		return fmt.Errorf("WithMongoReplicaSet is not supported for driver %s", d.driver)
+	}
+	if d.mode != RunModeDocker {
+		return nil
+	}
+
+	// the member is not reachable by its address inside the container, so clients must not discover the topology
+	d.url.Options["directConnection"] = "true"
+	d.dsnNoPass = d.url.string(true)
+
+	script := fmt.Sprintf("exec docker-entrypoint.sh mongod --replSet %s --port %d --bind_ip_all",
+		mongoReplicaSetName, d.dockerPort)
+	if d.url.User != "" {
+		// with authentication enabled members of a replica set require a key file
+		script = fmt.Sprintf("head -c 756 /dev/urandom | base64 > %[1]s && chmod 400 %[1]s && "+
+			"chown mongodb:mongodb %[1]s && %[2]s --keyFile %[1]s", mongoKeyFile, script)
+	}
+
+	d.dockerRunOptions = append(d.dockerRunOptions, func(o *dockertest.RunOptions) {
+		o.Entrypoint = []string{"bash", "-c", script}
+		o.Cmd = nil
+	})
+	d.dockerStartHooks = append(d.dockerStartHooks, func(ctx context.Context, d *testDB) error {
+		return d.initiateMongoReplicaSet(ctx, d.url, mongoReplicaSetConfig(mongoReplicaSetName,
+			fmt.Sprintf("localhost:%d", d.dockerPort), false))
+	})
+
+	return nil
+}
+
+// mongoReplicaSetConfig returns the configuration of a replica set with the only member.
+func mongoReplicaSetConfig(name, member string, configServer bool) bson.D {
+	config := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "members", Value: bson.A{
+			bson.D{{Key: "_id", Value: 0}, {Key: "host", Value: member}},
+		}},
+	}
+	if configServer {
+		config = append(config, bson.E{Key: "configsvr", Value: true})
+	}
+
+	return config
+}
+
+// initiateMongoReplicaSet runs replSetInitiate on the server from the url, an initialized replica set is not an error.
+func (d *testDB) initiateMongoReplicaSet(ctx context.Context, url *dbURL, config bson.D) error {
+	d.logger.Info(ctx, "initiating mongo replica set", "dsn", url.string(true))
+
+	err := d.runMongoAdminCommand(ctx, url, func(admin *mongo.Database) error {
+		err := admin.RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: config}}).Err()
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(mongoAlreadyInitializedCode) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("initiate mongo replica set: %w", err)
+	}
+
+	return nil
+}
+
+// waitMongoPrimary waits until the server from the url is the writable primary of a replica set.
+func (d *testDB) waitMongoPrimary(ctx context.Context, url *dbURL) error {
+	d.logger.Info(ctx, "waiting for mongo primary", "dsn", url.string(true))
+
+	err := d.runMongoAdminCommand(ctx, url, func(admin *mongo.Database) error {
+		var hello struct {
+			SetName           string `bson:"setName"`
+			IsWritablePrimary bool   `bson:"isWritablePrimary"`
+		}
+		if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			return err
+		}
+		if hello.SetName == "" {
+			return errors.New("server is not a replica set member")
+		}
+		if !hello.IsWritablePrimary {
+			return fmt.Errorf("server is not primary of replica set %s yet", hello.SetName)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("wait mongo primary: %w", err)
+	}
+
+	return nil
+}
+
+// runMongoAdminCommand connects directly to the server from the url and runs f on the admin database with retries.
+func (d *testDB) runMongoAdminCommand(ctx context.Context, url *dbURL, f func(admin *mongo.Database) error) error {
+	logDsn := url.string(true)
+	url = url.clone()
+	url.Options["directConnection"] = "true"
+
+	return d.retryConnect(ctx, logDsn, func() error {
+		client, err := mongo.Connect(options.Client().ApplyURI(url.string(false)))
+		if err != nil {
+			return fmt.Errorf("mongo connect: %w", err)
+		}
+		defer client.Disconnect(ctx) //nolint:errcheck // the client is only used for the command.
+
+		return f(client.Database("admin"))
+	})
+}
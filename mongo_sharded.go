@@ -0,0 +1,125 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ory/dockertest/v3"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// mongoRouterRole is the role of the mongos container, which is the main container of the sharded cluster.
+	mongoRouterRole = "mongos"
+	// mongoConfigServerRole is the role and the replica set name of the config server.
+	mongoConfigServerRole = "configsvr"
+	// mongoShardRole is the role and the replica set name of the only shard.
+	mongoShardRole = "shard0"
+	// mongoClusterPort is the port of mongod inside the config server and shard containers.
+	mongoClusterPort = 27017
+)
+
+// WithMongoShardedCluster starts a sharded MongoDB cluster and connects the client to mongos.
+// The default is a standalone server.
+func WithMongoShardedCluster() Option {
+	return func(o *testDB) {
+		o.mongoSharded = true
+	}
+}
+
+// prepareMongoShardedOptions validates WithMongoShardedCluster and configures the docker containers.
+func (d *testDB) prepareMongoShardedOptions() error {
+	if !d.mongoSharded {
+		return nil
+	}
+	if d.driver != mongoDriverName {
+		return fmt.Errorf("WithMongoShardedCluster is not supported for driver %s", d.driver)
+	}
+	if d.mongoReplicaSet {
+		return errors.New("WithMongoShardedCluster and WithMongoReplicaSet cannot be used together")
+	}
+	if d.mode != RunModeDocker {
+		return nil
+	}
+	if d.url.User != "" {
+		return errors.New("WithMongoShardedCluster does not support credentials in the DSN")
+	}
+
+	d.topologyRole = mongoRouterRole
+	d.dockerRunOptions = append(d.dockerRunOptions, func(o *dockertest.RunOptions) {
+		// mongos waits until the config server is started by the hook
+		o.Entrypoint = nil
+		o.Cmd = []string{
+			"mongos",
+			"--configdb", fmt.Sprintf("%s/%s:%d", mongoConfigServerRole,
+				d.topology.containerName(mongoConfigServerRole), mongoClusterPort),
+			"--port", strconv.Itoa(d.dockerPort),
+			"--bind_ip_all",
+		}
+	})
+	d.dockerStartHooks = append(d.dockerStartHooks, func(ctx context.Context, d *testDB) error {
+		return d.startMongoShards(ctx)
+	})
+
+	return nil
+}
+
+// startMongoShards starts the config server and the shard, initiates their replica sets
+// and adds the shard to the cluster.
+func (d *testDB) startMongoShards(ctx context.Context) error {
+	servers := []struct {
+		role string
+		flag string
+	}{
+		{role: mongoConfigServerRole, flag: "--configsvr"},
+		{role: mongoShardRole, flag: "--shardsvr"},
+	}
+
+	for _, server := range servers {
+		_, err := d.runTopologyContainer(server.role, &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+			Repository: d.dockerRepository,
+			Tag:        d.dockerImage,
+			Cmd: []string{
+				"mongod", server.flag,
+				"--replSet", server.role,
+				"--port", strconv.Itoa(mongoClusterPort),
+				"--bind_ip_all",
+			},
+			ExposedPorts: []string{fmt.Sprintf("%d/tcp", mongoClusterPort)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, server := range servers {
+		url, err := d.topologyURL(server.role, mongoClusterPort)
+		if err != nil {
+			return err
+		}
+
+		member := fmt.Sprintf("%s:%d", d.topology.containerName(server.role), mongoClusterPort)
+		config := mongoReplicaSetConfig(server.role, member, server.role == mongoConfigServerRole)
+		if err = d.initiateMongoReplicaSet(ctx, url, config); err != nil {
+			return err
+		}
+		if err = d.waitMongoPrimary(ctx, url); err != nil {
+			return err
+		}
+	}
+
+	shard := fmt.Sprintf("%s/%s:%d", mongoShardRole, d.topology.containerName(mongoShardRole), mongoClusterPort)
+	d.logger.Info(ctx, "adding mongo shard", "dsn", d.dsnNoPass, "shard", shard)
+
+	err := d.runMongoAdminCommand(ctx, d.url, func(admin *mongo.Database) error {
+		return admin.RunCommand(ctx, bson.D{{Key: "addShard", Value: shard}}).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("add mongo shard: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMongoDBShardedCluster(t *testing.T) {
+	t.Parallel()
+
+	dsn := "mongodb://127.0.0.1:27217/testdb"
+	db, _ := GetMongoDatabaseV2(t, dsn, WithDockerImage("8.0"), WithMongoShardedCluster())
+
+	admin := db.Client().Database("admin")
+	require.NoError(t, admin.RunCommand(t.Context(), bson.D{
+		{Key: "shardCollection", Value: db.Name() + ".events"},
+		{Key: "key", Value: bson.D{{Key: "tenant", Value: "hashed"}}},
+	}).Err())
+
+	_, err := db.Collection("events").InsertOne(t.Context(), bson.M{"tenant": "a"})
+	require.NoError(t, err)
+}
+
+// TestPrepareMongoShardedOptions verifies the validation and the mongos command of the sharded cluster.
+func TestPrepareMongoShardedOptions(t *testing.T) {
+	t.Parallel()
+
+	newDB := func(dsn string) *testDB {
+		db := newExternalTestDB(t)
+		db.driver = mongoDriverName
+		db.dsn = dsn
+		return db
+	}
+	dockerOptions := []Option{WithMode(RunModeDocker), WithDockerRepository("mongo"), WithDockerImage("8.0")}
+
+	db := newDB("mongodb://127.0.0.1:27217/testdb")
+	require.NoError(t, db.prepareOptions(mongoDriverName, append(dockerOptions, WithMongoShardedCluster())))
+	require.Equal(t, mongoRouterRole, db.topologyRole)
+	require.Len(t, db.dockerStartHooks, 1)
+
+	db.topology = &dockerTopology{name: "testdock-abc"} //nolint:exhaustruct // only the name is used.
+	runOptions := &dockertest.RunOptions{}              //nolint:exhaustruct // only the command is used.
+	for _, f := range db.dockerRunOptions {
+		f(runOptions)
+	}
+	require.Equal(t, "mongos --configdb configsvr/testdock-abc-configsvr:27017 --port 27217 --bind_ip_all",
+		strings.Join(runOptions.Cmd, " "))
+
+	err := newDB(DefaultMongoDSN).prepareOptions(mongoDriverName, append(dockerOptions, WithMongoShardedCluster()))
+	require.ErrorContains(t, err, "does not support credentials")
+
+	err = newDB("mongodb://127.0.0.1:27217/testdb").prepareOptions(mongoDriverName,
+		append(dockerOptions, WithMongoShardedCluster(), WithMongoReplicaSet()))
+	require.ErrorContains(t, err, "cannot be used together")
+
+	external := newDB("mongodb://127.0.0.1:27217/testdb")
+	require.NoError(t, external.prepareOptions(mongoDriverName, []Option{WithMode(RunModeExternal), WithMongoShardedCluster()}))
+	require.Empty(t, external.topologyRole)
+}
@@ -25,6 +25,9 @@ func GetMongoDatabase(tb testing.TB, dsn string, opt ...Option) (*mongov1.Databa
 	optPrepared := make([]Option, 0, len(opt))
 	optPrepared = append(optPrepared,
 		WithDockerRepository("mongo"),
+		withDockerDataDir("/data/db"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("mongod", "--setParameter", "journalCommitInterval=500"),
 		WithDockerImage("latest"),
 	)
 	if url.User != "" {
@@ -35,7 +38,7 @@ func GetMongoDatabase(tb testing.TB, dsn string, opt ...Option) (*mongov1.Databa
 			}))
 	}
 
-	optPrepared = append(optPrepared, opt...)
+	optPrepared = append(optPrepared, withDriverDefaults(mongoDriverName, opt)...)
 
 	tDB := newTDB(ctx, tb, mongoDriverName, dsn, optPrepared)
 
@@ -45,7 +48,7 @@ func GetMongoDatabase(tb testing.TB, dsn string, opt ...Option) (*mongov1.Databa
 	}
 
 	tb.Cleanup(func() {
-		if tDB.mode != RunModeDocker {
+		if tDB.mode != RunModeDocker && !tDB.noTestDatabase {
 			// protect against closing connection during tests
 			clientClean, connectErr := tDB.connectMongoDB(ctx)
 			if connectErr != nil {
@@ -28,6 +28,9 @@ func GetMongoDatabaseV2(tb testing.TB, dsn string, opt ...Option) (*mongo.Databa
 	optPrepared := make([]Option, 0, len(opt))
 	optPrepared = append(optPrepared,
 		WithDockerRepository("mongo"),
+		withDockerDataDir("/data/db"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("mongod", "--setParameter", "journalCommitInterval=500"),
 		WithDockerImage("latest"),
 	)
 	if url.User != "" {
@@ -38,7 +41,7 @@ func GetMongoDatabaseV2(tb testing.TB, dsn string, opt ...Option) (*mongo.Databa
 			}))
 	}
 
-	optPrepared = append(optPrepared, opt...)
+	optPrepared = append(optPrepared, withDriverDefaults(mongoDriverName, opt)...)
 
 	tDB := newTDB(ctx, tb, mongoDriverName, dsn, optPrepared)
 
@@ -48,7 +51,7 @@ func GetMongoDatabaseV2(tb testing.TB, dsn string, opt ...Option) (*mongo.Databa
 	}
 
 	tb.Cleanup(func() {
-		if tDB.mode != RunModeDocker {
+		if tDB.mode != RunModeDocker && !tDB.noTestDatabase {
 			// protect against closing connection during tests
 			clientClean, connectErr := tDB.connectMongoDBv2(ctx)
 			if connectErr != nil {
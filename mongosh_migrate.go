@@ -0,0 +1,166 @@
+package testdock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/ctxlog"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// mongoshScriptDir is the directory of the uploaded migration scripts inside the MongoDB container.
+const mongoshScriptDir = "/tmp"
+
+// MongoshMigrateFactory creates a migrator for imperative MongoDB JavaScript migrations executed with mongosh.
+// The migrations directory must contain *.js files with a numeric prefix before "_", for example 0001_init.js.
+// Files are applied once in the order of their versions with the db variable bound to the test database.
+// If the database runs in a testdock container, mongosh of the container is used with docker exec
+// (the mongo image 6.0 and later), otherwise mongosh must be installed and found in PATH.
+func MongoshMigrateFactory(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+	url, err := parseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+
+	migrations, err := loadScriptMigrations(migrationsDir, "*.js")
+	if err != nil {
+		return nil, err
+	}
+
+	runner := &mongoshRunner{url: url}
+	if pool, poolErr := migratorDockerPool(); poolErr == nil {
+		if id, port, ok := findTestdockContainer(pool, url.Port); ok {
+			runner.pool = pool
+			runner.containerID = id
+			runner.url = url.clone()
+			runner.url.Host = "127.0.0.1"
+			runner.url.Port = port
+		}
+	}
+	if runner.containerID == "" {
+		if _, err = exec.LookPath("mongosh"); err != nil {
+			return nil, fmt.Errorf("the database is not in a testdock container and mongosh is not found: %w", err)
+		}
+	}
+
+	return &scriptMigrator{
+		exec:       runner.exec,
+		begin:      nil,
+		close:      func() error { return nil },
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// mongoshRunner executes scripts with mongosh in the container of the database or from PATH.
+type mongoshRunner struct {
+	pool        *dockertest.Pool
+	containerID string
+	url         *dbURL
+}
+
+// exec executes the script and returns the output of mongosh on failure.
+func (r *mongoshRunner) exec(ctx context.Context, script string) error {
+	if r.containerID != "" {
+		return r.execInContainer(ctx, script)
+	}
+
+	f, err := os.CreateTemp("", "testdock-mongosh-*.js")
+	if err != nil {
+		return fmt.Errorf("create script file: %w", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // temporary file.
+
+	if _, err = f.WriteString(script); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write script file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("close script file: %w", err)
+	}
+
+	args := mongoshArgs(r.url, f.Name())
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput() //nolint:gosec // mongosh with the test DSN.
+	if err != nil {
+		return fmt.Errorf("mongosh: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// execInContainer uploads the script into the container and executes it with docker exec.
+func (r *mongoshRunner) execInContainer(ctx context.Context, script string) error {
+	name := "testdock-mongosh-" + uuid.NewString() + ".js"
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	if err := tw.WriteHeader(&tar.Header{ //nolint:exhaustruct // optional header fields use zero values.
+		Name: name,
+		Mode: 0o644, //nolint:mnd // the script is read by the mongodb user of the container.
+		Size: int64(len(script)),
+	}); err != nil {
+		return fmt.Errorf("write script archive: %w", err)
+	}
+	if _, err := tw.Write([]byte(script)); err != nil {
+		return fmt.Errorf("write script archive: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write script archive: %w", err)
+	}
+
+	if err := r.pool.Client.UploadToContainer(r.containerID, docker.UploadToContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:     ctx,
+		InputStream: &archive,
+		Path:        mongoshScriptDir,
+	}); err != nil {
+		return fmt.Errorf("upload script: %w", err)
+	}
+
+	file := path.Join(mongoshScriptDir, name)
+	// the script is removed in the same exec, the exit code of mongosh is kept
+	cmd := append([]string{"sh", "-c", `"$@"; code=$?; rm -f "` + file + `"; exit $code`, "sh"}, mongoshArgs(r.url, file)...)
+
+	execution, err := r.pool.Client.CreateExec(docker.CreateExecOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:      ctx,
+		Container:    r.containerID,
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("create exec: %w", err)
+	}
+
+	var output bytes.Buffer
+	if err = r.pool.Client.StartExec(execution.ID, docker.StartExecOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:      ctx,
+		OutputStream: &output,
+		ErrorStream:  &output,
+	}); err != nil {
+		return fmt.Errorf("start exec: %w", err)
+	}
+
+	inspect, err := r.pool.Client.InspectExec(execution.ID)
+	if err != nil {
+		return fmt.Errorf("inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("mongosh exited with code %d: %s", inspect.ExitCode, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+// mongoshArgs returns the mongosh command which executes the script file against the database.
+func mongoshArgs(url *dbURL, file string) []string {
+	return []string{"mongosh", "--quiet", url.string(false), "--file", file}
+}
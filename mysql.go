@@ -8,6 +8,10 @@ import (
 	_ "github.com/go-sql-driver/mysql" // mysql driver
 )
 
+// mysqlReadyLog matches the log line of the MySQL, MariaDB and Percona server which accepts TCP connections.
+// The temporary server of the docker entrypoint, which initializes the database, listens on port 0.
+const mysqlReadyLog = `ready for connections\.\s+Version: .*port: [1-9]`
+
 // GetMySQLConn inits a test mysql database, applies migrations.
 // Use user root for docker test database.
 func GetMySQLConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
@@ -22,14 +26,18 @@ func GetMySQLConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer)
 
 	optPrepared = append(optPrepared,
 		WithDockerRepository("mysql"),
+		withDockerDataDir("/var/lib/mysql"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("mysqld", "--innodb-flush-log-at-trx-commit=0", "--skip-log-bin"),
 		WithDockerImage("9.1.0"),
 		WithDockerEnv([]string{
 			fmt.Sprintf("MYSQL_ROOT_PASSWORD=%s", url.Password),
 			fmt.Sprintf("MYSQL_DATABASE=%s", url.Database),
 		}),
+		WithWaitStrategy(WaitForLog(mysqlReadyLog, 1)),
 	)
 
-	optPrepared = append(optPrepared, opt...)
+	optPrepared = append(optPrepared, withDriverDefaults("mysql", opt)...)
 
-	return GetSQLConn(tb, "mysql", dsn, optPrepared...)
+	return getSQLConn(tb, "mysql", dsn, optPrepared...)
 }
@@ -0,0 +1,432 @@
+package testdock
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithNoCreateDatabase verifies that tests use the database from the DSN.
+func TestWithNoCreateDatabase(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithNoCreateDatabase()}))
+	require.Equal(t, "postgres", db.DatabaseName())
+	require.Equal(t, DefaultPostgresDSN, db.DSN())
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithNoCreateDatabase(), WithConnectDatabase("shared")}))
+	require.Equal(t, "shared", db.DatabaseName())
+
+	db = newExternalTestDB(t)
+	db.driver = oracleDriverName
+	db.dsn = DefaultOracleDSN
+	err := db.prepareOptions(oracleDriverName, []Option{WithNoCreateDatabase()})
+	require.ErrorContains(t, err, "not supported for driver oracle")
+}
+
+// TestWithDockerNetwork verifies validation of the network options and that containers
+// in different networks are not shared.
+func TestWithDockerNetwork(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	key := db.dockerResourceKey()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"),
+		WithDockerNetwork("ci"), WithNetworkAlias("db"), WithNetworkAlias("postgres"),
+	}))
+	require.Equal(t, "ci", db.dockerNetwork)
+	require.Equal(t, []string{"db", "postgres"}, db.dockerNetworkAliases)
+	require.NotEqual(t, key, db.dockerResourceKey())
+	require.True(t, strings.HasSuffix(db.dockerResourceKey(), "|ci:db,postgres"))
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithNetworkAlias("db"),
+	})
+	require.ErrorContains(t, err, "network aliases require WithDockerNetwork")
+
+	err = newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerNetwork("ci"), WithNetworkAlias(""),
+	})
+	require.ErrorContains(t, err, "network alias is empty")
+}
+
+func Test_PgxDockerNetwork(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	network, err := pool.CreateNetwork("testdock-network-" + newDatabaseName())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = network.Close() })
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5542", 1)
+	_, _ = GetPgxPool(t, dsn,
+		WithDockerNetwork(network.Network.Name),
+		WithNetworkAlias("db"),
+	)
+
+	id, _, ok := findTestdockContainer(pool, 5542)
+	require.True(t, ok)
+	container, err := pool.Client.InspectContainer(id)
+	require.NoError(t, err)
+	require.Contains(t, container.NetworkSettings.Networks, network.Network.Name)
+	require.Contains(t, container.NetworkSettings.Networks[network.Network.Name].Aliases, "db")
+}
+
+// TestWithDockerMounts verifies resolution of relative host paths and validation of the mounts.
+func TestWithDockerMounts(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	dir, err := filepath.Abs("migrations/pg")
+	require.NoError(t, err)
+
+	db := newDB()
+	key := db.dockerResourceKey()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"),
+		WithDockerMounts("./migrations/pg:/docker-entrypoint-initdb.d:ro", "pgdata:/var/lib/postgresql/data"),
+	}))
+	require.Equal(t, []string{
+		dir + ":/docker-entrypoint-initdb.d:ro",
+		"pgdata:/var/lib/postgresql/data",
+	}, db.dockerMounts)
+	require.NotEqual(t, key, db.dockerResourceKey())
+
+	for _, mount := range []string{"/data", "pgdata:data", "./missing:/data"} {
+		err = newDB().prepareOptions(db.driver, []Option{
+			WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerMounts(mount),
+		})
+		require.Error(t, err, mount)
+	}
+}
+
+func Test_PgxDockerMounts(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5543", 1)
+	pool, _ := GetPgxPool(t, dsn,
+		WithDockerMounts("./migrations/pg/csv:/testdock:ro"),
+	)
+
+	var content string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT pg_read_file('/testdock/test_table.csv')").Scan(&content))
+	require.Contains(t, content, "name")
+}
+
+// TestWithTmpfsData verifies the data directory of the presets and that unknown images are rejected.
+func TestWithTmpfsData(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	opts := append(getPostgresOptions(t, "pgx", DefaultPostgresDSN,
+		WithDockerRepository("timescale/timescaledb"), WithTmpfsData()), WithMode(RunModeDocker))
+	require.NoError(t, db.prepareOptions(db.driver, opts))
+	require.True(t, db.tmpfsData)
+	require.Equal(t, "/var/lib/postgresql/data", db.dockerDataDir)
+	require.Equal(t, []string{"PGDATA=/var/lib/postgresql/data"}, db.dockerDataEnv)
+
+	// tests without tmpfs do not use the container with the data on tmpfs
+	noTmpfs := newDB()
+	require.NoError(t, noTmpfs.prepareOptions(noTmpfs.driver, append(getPostgresOptions(t, "pgx", DefaultPostgresDSN,
+		WithDockerRepository("timescale/timescaledb")), WithMode(RunModeDocker))))
+	require.NotEqual(t, noTmpfs.dockerResourceKey(), db.dockerResourceKey())
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("example/custom"), WithTmpfsData(),
+	})
+	require.ErrorContains(t, err, "WithTmpfsData is not supported for docker repository example/custom")
+}
+
+func Test_PgxTmpfsData(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5544", 1)
+	pool, _ := GetPgxPool(t, dsn, WithTmpfsData())
+
+	var dataDir, mounts string
+	require.NoError(t, pool.QueryRow(t.Context(), "SHOW data_directory").Scan(&dataDir))
+	require.Equal(t, "/var/lib/postgresql/data", dataDir)
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT pg_read_file('/proc/mounts')").Scan(&mounts))
+	require.Contains(t, mounts, "tmpfs /var/lib/postgresql/data tmpfs")
+}
+
+// TestWithInitScripts verifies the mount of the init scripts directory and the unsupported cases.
+func TestWithInitScripts(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	dir := t.TempDir()
+	db := newDB()
+	opts := append(getPostgresOptions(t, "pgx", DefaultPostgresDSN, WithInitScripts(dir)), WithMode(RunModeDocker))
+	require.NoError(t, db.prepareOptions(db.driver, opts))
+	require.Equal(t, []string{dir + ":/docker-entrypoint-initdb.d:ro"}, db.dockerMounts)
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("example/custom"), WithInitScripts(dir),
+	})
+	require.ErrorContains(t, err, "WithInitScripts is not supported for docker repository example/custom")
+
+	opts = append(getPostgresOptions(t, "pgx", DefaultPostgresDSN, WithInitScripts(filepath.Join(dir, "missing"))),
+		WithMode(RunModeDocker))
+	require.ErrorContains(t, newDB().prepareOptions(db.driver, opts), "must be an existing directory")
+}
+
+func Test_PgxInitScripts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "01_roles.sql"),
+		[]byte("CREATE ROLE app_readonly NOLOGIN;\n"), 0o600))
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5550", 1)
+	pool, _ := GetPgxPool(t, dsn, WithInitScripts(dir))
+
+	var exists bool
+	require.NoError(t, pool.QueryRow(t.Context(),
+		"SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_readonly')").Scan(&exists))
+	require.True(t, exists)
+}
+
+func Test_PgxRandomHostPort(t *testing.T) {
+	t.Parallel()
+
+	// the port of the DSN is taken by another process
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	require.True(t, ok)
+	port := addr.Port
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", strconv.Itoa(port), 1)
+	pool, info := GetPgxPool(t, dsn)
+
+	require.NotEqual(t, port, info.Port())
+	require.Contains(t, info.DSN(), fmt.Sprintf(":%d/", info.Port()))
+	require.NoError(t, pool.Ping(t.Context()))
+}
+
+// TestWithDockerResources verifies the limits in the host config of the container.
+func TestWithDockerResources(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerResources(1.5, 1<<30, 256<<20),
+	}))
+
+	var config docker.HostConfig
+	db.configureDockerHost(&config)
+	require.Equal(t, int64(100000), config.CPUPeriod)
+	require.Equal(t, int64(150000), config.CPUQuota)
+	require.Equal(t, int64(1<<30), config.Memory)
+	require.Equal(t, int64(256<<20), config.ShmSize)
+
+	// tests with other limits do not use the container
+	other := newExternalTestDB(t)
+	require.NoError(t, other.prepareOptions(other.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerResources(1.5, 2<<30, 256<<20),
+	}))
+	require.NotEqual(t, other.dockerResourceKey(), db.dockerResourceKey())
+
+	db = newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerResources(0, -1, 0),
+	})
+	require.ErrorContains(t, err, "must not be negative")
+}
+
+// TestWithDockerPrivilegedUlimits verifies privileged mode and ulimits in the host config of the container.
+func TestWithDockerPrivilegedUlimits(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerPrivileged(),
+		WithDockerUlimits(docker.ULimit{Name: "nofile", Soft: 1024, Hard: 1024}),
+		WithDockerUlimits(docker.ULimit{Name: "memlock", Soft: -1, Hard: -1},
+			docker.ULimit{Name: "nofile", Soft: 65535, Hard: 65535}),
+	}))
+
+	var config docker.HostConfig
+	db.configureDockerHost(&config)
+	require.True(t, config.Privileged)
+	require.Equal(t, []docker.ULimit{
+		{Name: "memlock", Soft: -1, Hard: -1},
+		{Name: "nofile", Soft: 65535, Hard: 65535},
+	}, config.Ulimits)
+
+	// tests without privileged mode or with other ulimits do not use the container
+	for _, opts := range [][]Option{
+		{WithDockerUlimits(docker.ULimit{Name: "memlock", Soft: -1, Hard: -1},
+			docker.ULimit{Name: "nofile", Soft: 65535, Hard: 65535})},
+		{WithDockerPrivileged(), WithDockerUlimits(docker.ULimit{Name: "nofile", Soft: 65535, Hard: 65535})},
+	} {
+		other := newExternalTestDB(t)
+		require.NoError(t, other.prepareOptions(other.driver,
+			append([]Option{WithMode(RunModeDocker), WithDockerRepository("postgres")}, opts...)))
+		require.NotEqual(t, other.dockerResourceKey(), db.dockerResourceKey())
+	}
+
+	for _, ulimit := range []docker.ULimit{{Name: "", Soft: 1, Hard: 1}, {Name: "nofile", Soft: 2, Hard: 1}} {
+		db = newExternalTestDB(t)
+		err := db.prepareOptions(db.driver, []Option{
+			WithMode(RunModeDocker), WithDockerRepository("postgres"), WithDockerUlimits(ulimit),
+		})
+		require.Error(t, err)
+	}
+}
+
+func Test_PgxDockerResources(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5547", 1)
+	pool, _ := GetPgxPool(t, dsn, WithDockerResources(1, 1<<30, 256<<20))
+
+	var mounts string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT pg_read_file('/proc/mounts')").Scan(&mounts))
+	require.Contains(t, mounts, "size=262144k")
+}
+
+// TestWithDockerCmd verifies that the command is part of the resource key and is rejected for topologies.
+func TestWithDockerCmd(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		db.driver = "pgx"
+		return db
+	}
+
+	db := newDB()
+	key := db.dockerResourceKey()
+	require.NoError(t, db.prepareOptions("pgx", []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"),
+		WithDockerCmd("postgres", "-c", "max_connections=500"), WithDockerEntrypoint("docker-entrypoint.sh"),
+	}))
+	require.Equal(t, []string{"postgres", "-c", "max_connections=500"}, db.dockerCmd)
+	require.Equal(t, []string{"docker-entrypoint.sh"}, db.dockerEntrypoint)
+	require.True(t, strings.HasSuffix(db.dockerResourceKey(), "|docker-entrypoint.sh|postgres -c max_connections=500"))
+	require.NotEqual(t, key, db.dockerResourceKey())
+
+	err := newDB().prepareOptions("pgx", []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), withPostgresReplica(), WithDockerCmd("postgres"),
+	})
+	require.ErrorContains(t, err, "not supported for replica sets and multi-container topologies")
+}
+
+func Test_PgxDockerCmd(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5548", 1)
+	pool, _ := GetPgxPool(t, dsn, WithDockerCmd("postgres", "-c", "max_connections=500", "-c", "fsync=off"))
+
+	var maxConnections, fsync string
+	require.NoError(t, pool.QueryRow(t.Context(), "SHOW max_connections").Scan(&maxConnections))
+	require.NoError(t, pool.QueryRow(t.Context(), "SHOW fsync").Scan(&fsync))
+	require.Equal(t, "500", maxConnections)
+	require.Equal(t, "off", fsync)
+}
+
+// TestWithFastMode verifies that the speed flags of the preset are added to the command.
+func TestWithFastMode(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+	preset := []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"),
+		withDockerFastCmd("postgres", "-c", "fsync=off"), WithFastMode(),
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions("pgx", preset))
+	require.Equal(t, []string{"postgres", "-c", "fsync=off"}, db.dockerCmd)
+
+	db = newDB()
+	require.NoError(t, db.prepareOptions("pgx", append(preset, WithDockerCmd("postgres", "-c", "max_connections=500"))))
+	require.Equal(t, []string{"postgres", "-c", "max_connections=500", "-c", "fsync=off"}, db.dockerCmd)
+
+	db = newDB()
+	require.NoError(t, db.prepareOptions("pgx", append(preset, WithMode(RunModeExternal))))
+	require.Nil(t, db.dockerCmd)
+
+	err := newDB().prepareOptions("pgx", []Option{WithMode(RunModeDocker), WithDockerRepository("redis"), WithFastMode()})
+	require.ErrorContains(t, err, "WithFastMode is not supported for docker repository redis")
+}
+
+func Test_PgxFastMode(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5559", 1)
+	pool, _ := GetPgxPool(t, dsn, WithFastMode())
+
+	for _, setting := range []string{"fsync", "synchronous_commit", "full_page_writes"} {
+		var value string
+		require.NoError(t, pool.QueryRow(t.Context(), "SHOW "+setting).Scan(&value))
+		require.Equal(t, "off", value, setting)
+	}
+}
+
+// TestDockerResourceKeyRunOptions verifies that the container is not shared by tests with different environment,
+// labels or docker run functions.
+func TestDockerResourceKeyRunOptions(t *testing.T) {
+	t.Parallel()
+
+	newKey := func(t *testing.T, options ...Option) string {
+		db := newTestDB(t, "pgx", DefaultPostgresDSN)
+		require.NoError(t, db.prepareOptions(db.driver, append([]Option{
+			WithMode(RunModeDocker), WithDockerRepository("postgres"),
+		}, options...)))
+		return db.dockerResourceKey()
+	}
+
+	key := newKey(t)
+	require.NotEqual(t, key, newKey(t, WithDockerEnv([]string{"POSTGRES_PASSWORD=secret", "TZ=UTC"})))
+	require.NotEqual(t, key, newKey(t, WithDockerLabels(map[string]string{"ci.job": "1"})))
+
+	// tests with docker run functions share the container only with the same container name
+	hostConfig := WithDockerHostConfig(func(*docker.HostConfig) {})
+	key = newKey(t, hostConfig)
+	require.Equal(t, key, newKey(t, hostConfig))
+	named := newKey(t, hostConfig, WithContainerName("orders-pg"))
+	t.Run("other test", func(t *testing.T) {
+		t.Parallel()
+		require.NotEqual(t, key, newKey(t, hostConfig))
+		require.Equal(t, named, newKey(t, hostConfig, WithContainerName("orders-pg")))
+	})
+}
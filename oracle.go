@@ -0,0 +1,132 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oracle driver name registered by github.com/sijms/go-ora/v2.
+const oracleDriverName = "oracle"
+
+const (
+	// DefaultOracleRetryTimeout is the default retry timeout for Oracle.
+	DefaultOracleRetryTimeout = time.Second * 10
+	// DefaultOracleTotalRetryDuration is the default total retry duration for Oracle.
+	// Oracle Free needs minutes to open the pluggable database on the first start.
+	DefaultOracleTotalRetryDuration = time.Minute * 5
+)
+
+// GetOracleConn inits a test Oracle database, applies migrations,
+// and returns sql connection to the database.
+// Each test gets its own schema (Oracle user) inside the pluggable database from the DSN,
+// because CREATE DATABASE in Oracle creates a whole instance.
+// Informer.DatabaseName returns the schema name, Informer.DSN connects as the schema user.
+// Docker image: https://hub.docker.com/r/gvenzl/oracle-free.
+// Do not forget to import github.com/sijms/go-ora/v2 driver package.
+func GetOracleConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	url, err := parseURL(dsn)
+	if err != nil {
+		tb.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("gvenzl/oracle-free"),
+		WithDockerImage("23-slim-faststart"),
+		WithRetryTimeout(DefaultOracleRetryTimeout),
+		WithTotalRetryDuration(DefaultOracleTotalRetryDuration),
+		WithPrepareCleanUp(disconnectOracleSessions),
+		WithDockerEnv([]string{
+			fmt.Sprintf("ORACLE_PASSWORD=%s", url.Password),
+		}),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults(oracleDriverName, opt)...)
+
+	return getSQLConn(tb, oracleDriverName, dsn, optPrepared...)
+}
+
+// oracleTestURL returns the connection string of the schema user created for the test.
+// The schema user has the same password as the user from the DSN.
+func (d *testDB) oracleTestURL() *dbURL {
+	u := d.url.clone()
+	u.User = d.databaseName
+	return u
+}
+
+// validateOraclePassword checks that the password can be used in the quoted IDENTIFIED BY clause of the schema user.
+func validateOraclePassword(password string) error {
+	if strings.Contains(password, `"`) {
+		return errors.New(`oracle dsn password must not contain '"', it is used for the schema user`)
+	}
+
+	return nil
+}
+
+// createOracleSchema creates the schema user for the test.
+func (d *testDB) createOracleSchema(ctx context.Context) error {
+	d.logger.Info(ctx, "creating new test oracle schema", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	if d.url.Password == "" {
+		return errors.New("oracle dsn password is required to create the schema user")
+	}
+
+	db, err := d.connectSQLDB(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	queries := []string{
+		fmt.Sprintf(`CREATE USER %s IDENTIFIED BY "%s" DEFAULT TABLESPACE USERS QUOTA UNLIMITED ON USERS`,
+			d.databaseName, d.url.Password),
+		fmt.Sprintf("GRANT CONNECT, RESOURCE, CREATE VIEW, CREATE SYNONYM TO %s", d.databaseName),
+	}
+	for _, query := range queries {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("create oracle schema: %w", err)
+		}
+	}
+
+	d.logger.Info(ctx, "new test oracle schema created", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	return nil
+}
+
+// disconnectOracleSessions kills sessions of the schema user before dropping it.
+func disconnectOracleSessions(db *sql.DB, databaseName string) error {
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, "SELECT sid, serial# FROM v$session WHERE username = :1", databaseName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err is checked below.
+
+	var sessions []string
+	for rows.Next() {
+		var sid, serial int64
+		if err = rows.Scan(&sid, &serial); err != nil {
+			return err
+		}
+		sessions = append(sessions, fmt.Sprintf("%d,%d", sid, serial))
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if _, err = db.ExecContext(ctx, fmt.Sprintf("ALTER SYSTEM KILL SESSION '%s' IMMEDIATE", session)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package testdock
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+// perconaDockerPort is the port of Percona Server inside the docker container.
+const perconaDockerPort = 3306
+
+// GetPerconaConn inits a test Percona Server for MySQL database, applies migrations,
+// and returns sql connection (mysql driver) to the database.
+// Use user root for docker test database.
+// The container port is always 3306, so the DSN port only defines the host port.
+// Use GooseMigrateFactoryMySQL for migrations.
+// Docker image: https://hub.docker.com/r/percona/percona-server.
+func GetPerconaConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	url, err := parseURL(dsn)
+	if err != nil {
+		tb.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	optPrepared := make([]Option, 0, len(opt))
+
+	optPrepared = append(optPrepared,
+		WithDockerRepository("percona/percona-server"),
+		withDockerDataDir("/var/lib/mysql"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("mysqld", "--innodb-flush-log-at-trx-commit=0", "--skip-log-bin"),
+		WithDockerImage("8.4"),
+		WithDockerPort(perconaDockerPort),
+		WithDockerEnv([]string{
+			fmt.Sprintf("MYSQL_ROOT_PASSWORD=%s", url.Password),
+			fmt.Sprintf("MYSQL_DATABASE=%s", url.Database),
+		}),
+		WithWaitStrategy(WaitForLog(mysqlReadyLog, 1)),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults("percona", opt)...)
+
+	return getSQLConn(tb, "mysql", dsn, optPrepared...)
+}
@@ -0,0 +1,22 @@
+package testdock
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Percona(t *testing.T) {
+	t.Parallel()
+
+	db, informer := GetPerconaConn(t,
+		DefaultPerconaDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryMySQL),
+		WithMode(RunModeDocker),
+		WithRetryTimeout(time.Second*5),
+		WithTotalRetryDuration(time.Second*90),
+	)
+
+	checkInformer(t, DefaultPerconaDSN, informer)
+
+	testSQLHelper(t, db)
+}
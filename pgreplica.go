@@ -0,0 +1,168 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ory/dockertest/v3"
+)
+
+const (
+	// pgPrimaryRole is the role of the primary container, which is the main container of the topology.
+	pgPrimaryRole = "primary"
+	// pgReplicaRole is the role of the streaming replica container.
+	pgReplicaRole = "replica"
+	// pgReplicaPort is the port of PostgreSQL inside the replica container.
+	pgReplicaPort = 5432
+	// pgReplicaDataDir is the data directory of the replica inside the container.
+	pgReplicaDataDir = "/tmp/testdock_replica"
+	// pgPrimaryHBAFile is the pg_hba.conf of the primary, which allows replication connections.
+	pgPrimaryHBAFile = "/tmp/testdock_hba.conf"
+)
+
+// ReplicaInformer extends Informer with the connection details of the read replica.
+type ReplicaInformer interface {
+	Informer
+	// ReplicaDSN returns the connection string of the test database on the read replica.
+	ReplicaDSN() string
+	// WaitReplication waits until the replica replays all changes committed on the primary before the call.
+	// Use it before reading data from the replica which was written to the primary.
+	WaitReplication(ctx context.Context) error
+}
+
+// GetPgxPrimaryReplica starts a PostgreSQL primary and a streaming replica on a private docker network,
+// creates the test database and applies migrations on the primary, waits until the replica catches up,
+// and returns pgx connection pools to the test database on the primary and on the replica.
+// The replica is read-only and is published on a random host port, see ReplicaInformer.ReplicaDSN.
+// Only RunModeDocker is supported. Defaults registered with RegisterDriverDefaults for "pgx" are applied.
+// Docker image: https://hub.docker.com/_/postgres.
+func GetPgxPrimaryReplica(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, *pgxpool.Pool, ReplicaInformer) {
+	tb.Helper()
+
+	ctx := context.Background()
+
+	optPrepared := getPostgresOptions(tb, "pgx", dsn, opt...)
+	optPrepared = append(optPrepared, withPostgresReplica())
+
+	tDB := newTDB(ctx, tb, "pgx", dsn, optPrepared)
+
+	primary, err := tDB.connectPgxDB(ctx)
+	if err != nil {
+		tb.Fatalf("cannot connect to postgres primary: %v", err)
+	}
+	tDB.closePgxPoolOnCleanup(primary, "primary pgxpool")
+
+	replicaURL, err := tDB.topologyURL(pgReplicaRole, pgReplicaPort)
+	if err != nil {
+		tb.Fatalf("cannot get postgres replica url: %v", err)
+	}
+	replicaURL = replicaURL.replaceDatabase(tDB.databaseName)
+
+	// the test database appears on the replica after the replica replays its creation
+	replica, err := tDB.connectPgxURL(ctx, replicaURL)
+	if err != nil {
+		tb.Fatalf("cannot connect to postgres replica: %v", err)
+	}
+	tDB.closePgxPoolOnCleanup(replica, "replica pgxpool")
+
+	info := &pgReplicaInfo{
+		testDB:     tDB,
+		replicaURL: replicaURL,
+		primary:    primary,
+		replica:    replica,
+	}
+	if err = info.WaitReplication(ctx); err != nil {
+		tb.Fatalf("postgres replica has not caught up: %v", err)
+	}
+
+	return primary, replica, info
+}
+
+// withPostgresReplica starts the primary with a streaming replica.
+func withPostgresReplica() Option {
+	return func(o *testDB) {
+		o.pgReplica = true
+	}
+}
+
+// preparePostgresReplicaOptions configures the primary container and the hook starting the replica.
+func (d *testDB) preparePostgresReplicaOptions() error {
+	if !d.pgReplica {
+		return nil
+	}
+	if d.mode != RunModeDocker {
+		return errors.New("GetPgxPrimaryReplica supports only RunModeDocker")
+	}
+
+	primaryScript := fmt.Sprintf(`printf 'local all all trust\nhost all all all scram-sha-256\n`+
+		`host replication all all scram-sha-256\n' > %[1]s && `+
+		`exec docker-entrypoint.sh postgres -p %[2]d -c hba_file=%[1]s -c wal_level=replica -c max_wal_senders=10`,
+		pgPrimaryHBAFile, d.dockerPort)
+
+	d.topologyRole = pgPrimaryRole
+	d.dockerRunOptions = append(d.dockerRunOptions, func(o *dockertest.RunOptions) {
+		o.Entrypoint = []string{"sh", "-c", primaryScript}
+		o.Cmd = nil
+	})
+	d.dockerStartHooks = append(d.dockerStartHooks, func(_ context.Context, d *testDB) error {
+		return d.startPostgresReplica()
+	})
+
+	return nil
+}
+
+// startPostgresReplica starts the replica container, which clones the primary with pg_basebackup
+// as soon as the primary accepts connections and then follows it.
+func (d *testDB) startPostgresReplica() error {
+	replicaScript := fmt.Sprintf(
+		"until pg_basebackup -h %[1]s -p %[2]d -U %[3]s -D %[4]s -R -X stream; do rm -rf %[4]s; sleep 1; done && "+
+			"chmod 700 %[4]s && exec postgres -D %[4]s -c listen_addresses='*' -p %[5]d",
+		d.topology.containerName(pgPrimaryRole), d.dockerPort, d.url.User, pgReplicaDataDir, pgReplicaPort)
+
+	_, err := d.runTopologyContainer(pgReplicaRole, &dockertest.RunOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Repository:   d.dockerRepository,
+		Tag:          d.dockerImage,
+		User:         "postgres",
+		Env:          []string{"PGPASSWORD=" + d.url.Password},
+		Entrypoint:   []string{"sh", "-c", replicaScript},
+		ExposedPorts: []string{fmt.Sprintf("%d/tcp", pgReplicaPort)},
+	})
+
+	return err
+}
+
+// pgReplicaInfo implements ReplicaInformer.
+type pgReplicaInfo struct {
+	*testDB
+	replicaURL *dbURL
+	primary    *pgxpool.Pool
+	replica    *pgxpool.Pool
+}
+
+// ReplicaDSN returns the connection string of the test database on the read replica.
+func (p *pgReplicaInfo) ReplicaDSN() string {
+	return p.replicaURL.string(false)
+}
+
+// WaitReplication waits until the replica replays the current WAL position of the primary.
+func (p *pgReplicaInfo) WaitReplication(ctx context.Context) error {
+	var lsn string
+	if err := p.primary.QueryRow(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&lsn); err != nil {
+		return fmt.Errorf("get primary wal position: %w", err)
+	}
+
+	return p.retryConnect(ctx, p.replicaURL.string(true), func() error {
+		var caughtUp bool
+		if err := p.replica.QueryRow(ctx,
+			"SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, false)", lsn).Scan(&caughtUp); err != nil {
+			return err
+		}
+		if !caughtUp {
+			return errors.New("replica has not replayed wal position " + lsn)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxPrimaryReplica(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5532", 1)
+	primary, replica, informer := GetPgxPrimaryReplica(t, dsn,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDockerImage("17"),
+	)
+	require.NotEqual(t, informer.DSN(), informer.ReplicaDSN())
+
+	_, err := primary.Exec(t.Context(), "INSERT INTO test_table (name) VALUES ('replicated')")
+	require.NoError(t, err)
+	require.NoError(t, informer.WaitReplication(t.Context()))
+
+	var count int
+	require.NoError(t, replica.QueryRow(t.Context(),
+		"SELECT count(*) FROM test_table WHERE name = 'replicated'").Scan(&count))
+	require.Equal(t, 1, count)
+
+	_, err = replica.Exec(t.Context(), "INSERT INTO test_table (name) VALUES ('read-only')")
+	require.ErrorContains(t, err, "read-only transaction")
+}
+
+// TestPreparePostgresReplicaOptions verifies the primary configuration and the supported modes.
+func TestPreparePostgresReplicaOptions(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		db.driver = "pgx"
+		db.dsn = DefaultPostgresDSN
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions("pgx", []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), withPostgresReplica(),
+	}))
+	require.Equal(t, pgPrimaryRole, db.topologyRole)
+	require.Len(t, db.dockerStartHooks, 1)
+
+	runOptions := &dockertest.RunOptions{Cmd: []string{"postgres"}} //nolint:exhaustruct // only the command is used.
+	for _, f := range db.dockerRunOptions {
+		f(runOptions)
+	}
+	require.Nil(t, runOptions.Cmd)
+	require.Contains(t, runOptions.Entrypoint[2], "host replication all all scram-sha-256")
+	require.Contains(t, runOptions.Entrypoint[2], "postgres -p 5432 -c hba_file="+pgPrimaryHBAFile)
+
+	err := newDB().prepareOptions("pgx", []Option{WithMode(RunModeExternal), withPostgresReplica()})
+	require.ErrorContains(t, err, "supports only RunModeDocker")
+}
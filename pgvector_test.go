@@ -0,0 +1,26 @@
+package testdock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Pgvector(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, informer := GetPgvectorPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pgvector/goose", GooseMigrateFactoryPGX),
+		WithMode(RunModeDocker), // external postgres has no vector extension
+	)
+
+	checkInformer(t, DefaultPostgresDSN, informer)
+
+	var id int
+	err := db.QueryRow(ctx, "SELECT id FROM test_embeddings ORDER BY embedding <-> '[0.9,0.1,0]' LIMIT 1").Scan(&id)
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+}
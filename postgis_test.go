@@ -0,0 +1,36 @@
+package testdock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostGIS(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, informer := GetPostgisPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/postgis/goose", GooseMigrateFactoryPGX),
+		WithMode(RunModeDocker), // external postgres has no postgis extension
+	)
+
+	checkInformer(t, DefaultPostgresDSN, informer)
+
+	var x float64
+	err := db.QueryRow(ctx, "SELECT ST_X(location) FROM test_places").Scan(&x)
+	require.NoError(t, err)
+	require.InDelta(t, 37.6173, x, 0.0001)
+
+	// the spatial reference systems of the extension are kept
+	dbInfo, ok := informer.(DatabaseInformer)
+	require.True(t, ok)
+	require.NoError(t, dbInfo.TruncateAll(ctx))
+	var places, systems int
+	require.NoError(t, db.QueryRow(ctx, "SELECT count(*) FROM test_places").Scan(&places))
+	require.Zero(t, places)
+	require.NoError(t, db.QueryRow(ctx, "SELECT count(*) FROM spatial_ref_sys").Scan(&systems))
+	require.Positive(t, systems)
+}
@@ -0,0 +1,71 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresPreset is a PostgreSQL image with an extension, which is created in the test database.
+type postgresPreset struct {
+	driver     string // the name of the driver defaults in the registry
+	repository string
+	image      string
+	extension  string
+}
+
+//nolint:gochecknoglobals // the table of the presets.
+var (
+	pgvectorPreset = postgresPreset{
+		driver: "pgvector", repository: "pgvector/pgvector", image: "pg17", extension: "vector",
+	}
+	postgisPreset = postgresPreset{
+		driver: "postgis", repository: "postgis/postgis", image: "17-3.5", extension: "postgis",
+	}
+	timescalePreset = postgresPreset{
+		driver: "timescaledb", repository: "timescale/timescaledb", image: "latest-pg17", extension: "timescaledb",
+	}
+)
+
+// GetPgvectorPool inits a test PostgreSQL database with the pgvector extension,
+// applies migrations, and returns pgx connection pool to the database.
+// Docker image: https://hub.docker.com/r/pgvector/pgvector.
+func GetPgvectorPool(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, Informer) {
+	tb.Helper()
+
+	return getPostgresPresetPool(tb, pgvectorPreset, dsn, opt)
+}
+
+// GetPostgisPool inits a test PostGIS database, creates the postgis extension,
+// applies migrations, and returns pgx connection pool to the database.
+// Docker image: https://hub.docker.com/r/postgis/postgis.
+func GetPostgisPool(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, Informer) {
+	tb.Helper()
+
+	return getPostgresPresetPool(tb, postgisPreset, dsn, opt)
+}
+
+// GetTimescalePool inits a test TimescaleDB database, creates the timescaledb extension,
+// applies migrations, and returns pgx connection pool to the database.
+// Docker image: https://hub.docker.com/r/timescale/timescaledb.
+func GetTimescalePool(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, Informer) {
+	tb.Helper()
+
+	return getPostgresPresetPool(tb, timescalePreset, dsn, opt)
+}
+
+// getPostgresPresetPool inits a test database in the image of the preset and creates its extension.
+func getPostgresPresetPool(tb testing.TB, preset postgresPreset, dsn string, opt []Option) (*pgxpool.Pool, Informer) {
+	tb.Helper()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository(preset.repository),
+		WithDockerImage(preset.image),
+		WithPostgresExtensions(preset.extension),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults(preset.driver, opt)...)
+
+	return GetPgxPool(tb, dsn, optPrepared...)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	_ "github.com/jackc/pgx/v5/stdlib" // pgx postgres driver
@@ -19,23 +20,13 @@ func GetPgxPool(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, Inform
 
 	ctx := context.Background()
 
-	tDB := newTDB(ctx, tb, "pgx", dsn, getPostgresOptions(tb, dsn, opt...))
+	tDB := newTDB(ctx, tb, "pgx", dsn, getPostgresOptions(tb, "pgx", dsn, opt...))
 
 	db, err := tDB.connectPgxDB(ctx)
 	if err != nil {
 		tb.Fatalf("cannot connect to postgres: %v", err)
 	}
-
-	tb.Cleanup(func() {
-		if closeErr := closeResourceWithTimeout(tDB.closeTimeout, func() error {
-			db.Close()
-			return nil
-		}, func() string {
-			return tDB.closeTimeoutDetails("pgxpool", snapshotPgxPoolStats(db))
-		}); closeErr != nil {
-			tb.Errorf("%v", closeErr)
-		}
-	})
+	tDB.closePgxPoolOnCleanup(db, "pgxpool")
 
 	return db, tDB
 }
@@ -45,7 +36,7 @@ func GetPgxPool(tb testing.TB, dsn string, opt ...Option) (*pgxpool.Pool, Inform
 func GetPqConn(ctx context.Context, tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
 	tb.Helper()
 
-	tDB := newTDB(ctx, tb, "postgres", dsn, getPostgresOptions(tb, dsn, opt...))
+	tDB := newTDB(ctx, tb, "postgres", dsn, getPostgresOptions(tb, "postgres", dsn, opt...))
 
 	db, err := tDB.connectSQLDB(ctx, true)
 	if err != nil {
@@ -63,6 +54,30 @@ func GetPqConn(ctx context.Context, tb testing.TB, dsn string, opt ...Option) (*
 	return db, tDB
 }
 
+// WithPostgresExtensions creates the extensions in the test database before migrations.
+// The names are quoted, the extensions must be available on the database server.
+func WithPostgresExtensions(extensions ...string) Option {
+	return func(o *testDB) {
+		for _, extension := range extensions {
+			o.initQueries = append(o.initQueries, "CREATE EXTENSION IF NOT EXISTS "+pgx.Identifier{extension}.Sanitize())
+		}
+	}
+}
+
+// closePgxPoolOnCleanup closes the pool after the test.
+func (d *testDB) closePgxPoolOnCleanup(pool *pgxpool.Pool, name string) {
+	d.t.Cleanup(func() {
+		if closeErr := closeResourceWithTimeout(d.closeTimeout, func() error {
+			pool.Close()
+			return nil
+		}, func() string {
+			return d.closeTimeoutDetails(name, snapshotPgxPoolStats(pool))
+		}); closeErr != nil {
+			d.t.Errorf("%v", closeErr)
+		}
+	})
+}
+
 // snapshotPgxPoolStats captures the pgxpool counters required for close-timeout diagnostics.
 func snapshotPgxPoolStats(pool *pgxpool.Pool) *pgxPoolCloseStats {
 	stats := pool.Stat()
@@ -77,8 +92,12 @@ func snapshotPgxPoolStats(pool *pgxpool.Pool) *pgxPoolCloseStats {
 
 // connectPgxDB connects to the database with retries using pgx.
 func (d *testDB) connectPgxDB(ctx context.Context) (*pgxpool.Pool, error) {
+	return d.connectPgxURL(ctx, d.url.replaceDatabase(d.databaseName))
+}
+
+// connectPgxURL connects to the database from the url with retries using pgx.
+func (d *testDB) connectPgxURL(ctx context.Context, dbURL *dbURL) (*pgxpool.Pool, error) {
 	var db *pgxpool.Pool
-	dbURL := d.url.replaceDatabase(d.databaseName)
 	d.logger.Info(ctx, "connecting to test database", "url", dbURL.string(true))
 
 	err := d.retryConnect(ctx, dbURL.string(true), func() (err error) {
@@ -110,7 +129,7 @@ func disconnectUsers(db *sql.DB, databaseName string) error {
 }
 
 // getPostgresOptions returns the options for the postgresql database.
-func getPostgresOptions(tb testing.TB, dsn string, opt ...Option) []Option {
+func getPostgresOptions(tb testing.TB, driver, dsn string, opt ...Option) []Option {
 	tb.Helper()
 
 	url, err := parseURL(dsn)
@@ -121,6 +140,10 @@ func getPostgresOptions(tb testing.TB, dsn string, opt ...Option) []Option {
 	optPrepared := make([]Option, 0, len(opt))
 	optPrepared = append(optPrepared,
 		WithDockerRepository("postgres"),
+		// since PostgreSQL 18 the image keeps the data in a subdirectory of /var/lib/postgresql
+		withDockerDataDir("/var/lib/postgresql/data", "PGDATA=/var/lib/postgresql/data"),
+		withDockerInitDir(dockerEntrypointInitDir),
+		withDockerFastCmd("postgres", "-c", "fsync=off", "-c", "synchronous_commit=off", "-c", "full_page_writes=off"),
 		WithPrepareCleanUp(disconnectUsers),
 		WithDockerEnv([]string{
 			fmt.Sprintf("POSTGRES_USER=%s", url.User),
@@ -131,7 +154,7 @@ func getPostgresOptions(tb testing.TB, dsn string, opt ...Option) []Option {
 		}),
 	)
 
-	optPrepared = append(optPrepared, opt...)
+	optPrepared = append(optPrepared, withDriverDefaults(driver, opt)...)
 
 	return optPrepared
 }
@@ -156,3 +156,15 @@ func assertNormalizedNameIsNull(t *testing.T, ctx context.Context, db *pgxpool.P
 	require.NoError(t, err)
 	require.Equal(t, wantNull, isNull)
 }
+
+func TestWithPostgresExtensionsQuotesNames(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	WithPostgresExtensions("uuid-ossp", `x"; DROP DATABASE postgres; --`)(db)
+
+	require.Equal(t, []string{
+		`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`,
+		`CREATE EXTENSION IF NOT EXISTS "x""; DROP DATABASE postgres; --"`,
+	}, db.initQueries)
+}
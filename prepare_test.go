@@ -18,3 +18,13 @@ func checkInformer(t *testing.T, defaultDSN string, informer Informer) {
 	require.NotEqual(t, defaultURL.Database, url.Database)
 	require.NotEqual(t, defaultURL.Database, informer.DatabaseName())
 }
+
+// newExternalTestDB creates the configuration of a pgx test database in RunModeExternal for the tests of options.
+func newExternalTestDB(tb testing.TB) *testDB {
+	tb.Helper()
+
+	db := newTestDB(tb, "pgx", DefaultPostgresDSN)
+	db.mode = RunModeExternal
+
+	return db
+}
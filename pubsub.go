@@ -0,0 +1,143 @@
+package testdock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+)
+
+const (
+	// pubsub driver name for separating sql and pubsub.
+	pubSubDriverName = "pubsub"
+	// pubSubDockerPort is the port of the Pub/Sub emulator inside the docker container.
+	pubSubDockerPort = 8085
+)
+
+// PubSubInformer extends Informer with the Pub/Sub emulator connection details.
+type PubSubInformer interface {
+	Informer
+	// EmulatorHost returns the host:port of the emulator, the value for PUBSUB_EMULATOR_HOST.
+	EmulatorHost() string
+	// ProjectID returns the unique project ID of the test.
+	ProjectID() string
+}
+
+// GetPubSubEmulator starts the Pub/Sub emulator and returns the connection details
+// with a unique project ID per test, so topics and subscriptions of tests do not intersect.
+// The DSN format is http://host:port, the database part is ignored.
+// Use GetPubSubClient of the github.com/n-r-w/testdock/v2/gcp module for a client of the project.
+// Subscriptions and topics of the project are deleted after the test in RunModeExternal.
+// Docker image: gcr.io/google.com/cloudsdktool/google-cloud-cli (emulators tag).
+func GetPubSubEmulator(tb testing.TB, dsn string, opt ...Option) PubSubInformer {
+	tb.Helper()
+
+	return startPubSubEmulator(tb, dsn, opt)
+}
+
+// startPubSubEmulator starts the emulator and generates the project ID of the test.
+func startPubSubEmulator(tb testing.TB, dsn string, opt []Option) *pubSubInfo {
+	tb.Helper()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("gcr.io/google.com/cloudsdktool/google-cloud-cli"),
+		WithDockerImage("emulators"),
+		WithDockerPort(pubSubDockerPort),
+		WithDockerRunOptions(func(o *dockertest.RunOptions) {
+			o.Cmd = []string{"gcloud", "beta", "emulators", "pubsub", "start",
+				fmt.Sprintf("--host-port=0.0.0.0:%d", pubSubDockerPort)}
+		}),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults(pubSubDriverName, opt)...)
+
+	return &pubSubInfo{testDB: newTDB(context.Background(), tb, pubSubDriverName, dsn, optPrepared)}
+}
+
+// pubSubInfo implements PubSubInformer.
+type pubSubInfo struct {
+	*testDB
+}
+
+// EmulatorHost returns the host:port of the emulator.
+func (p *pubSubInfo) EmulatorHost() string {
+	return p.url.address()
+}
+
+// ProjectID returns the unique project ID of the test.
+func (p *pubSubInfo) ProjectID() string {
+	return p.databaseName
+}
+
+// clearPubSubProject deletes all subscriptions and topics of the test project.
+func (d *testDB) clearPubSubProject(ctx context.Context) error {
+	d.logger.Info(ctx, "deleting test project topics", "dsn", d.dsnNoPass, "project", d.databaseName)
+
+	// subscriptions are deleted first, because deleting a topic does not delete its subscriptions.
+	for _, collection := range []string{"subscriptions", "topics"} {
+		names, err := d.listPubSubResources(ctx, collection)
+		if err != nil {
+			return fmt.Errorf("clear pubsub project: %w", err)
+		}
+
+		for _, name := range names {
+			if _, err = emulatorRequest(ctx, http.MethodDelete, d.emulatorEndpoint()+"/v1/"+name, nil); err != nil {
+				return fmt.Errorf("clear pubsub project: delete %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// listPubSubResources returns the names of all topics or subscriptions of the test project.
+func (d *testDB) listPubSubResources(ctx context.Context, collection string) ([]string, error) {
+	var (
+		names     []string
+		pageToken string
+	)
+
+	for {
+		endpoint := fmt.Sprintf("%s/v1/projects/%s/%s", d.emulatorEndpoint(), d.databaseName, collection)
+		if pageToken != "" {
+			endpoint += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		data, err := emulatorRequest(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", collection, err)
+		}
+
+		var page map[string]json.RawMessage
+		if err = json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("list %s: unmarshal response: %w", collection, err)
+		}
+
+		var items []struct {
+			Name string `json:"name"`
+		}
+		if raw, ok := page[collection]; ok {
+			if err = json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("list %s: unmarshal items: %w", collection, err)
+			}
+		}
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+
+		pageToken = ""
+		if raw, ok := page["nextPageToken"]; ok {
+			if err = json.Unmarshal(raw, &pageToken); err != nil {
+				return nil, fmt.Errorf("list %s: unmarshal page token: %w", collection, err)
+			}
+		}
+		if pageToken == "" {
+			return names, nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package testdock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestClearPubSubProject verifies that subscriptions and topics of all pages are deleted.
+func TestClearPubSubProject(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.driver = pubSubDriverName
+
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/v1/projects/" + db.databaseName
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+		case r.URL.Path == prefix+"/subscriptions":
+			_, _ = w.Write([]byte(`{"subscriptions":[{"name":"projects/p/subscriptions/s1"}]}`))
+		case r.URL.Path == prefix+"/topics" && r.URL.Query().Get("pageToken") == "":
+			_, _ = w.Write([]byte(`{"topics":[{"name":"projects/p/topics/t1"}],"nextPageToken":"next"}`))
+		case r.URL.Path == prefix+"/topics":
+			_, _ = w.Write([]byte(`{"topics":[{"name":"projects/p/topics/t2"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	db.dsn = srv.URL
+	require.NoError(t, db.prepareOptions(pubSubDriverName, nil))
+	require.Len(t, db.databaseName, emulatorProjectIDLength)
+
+	require.NoError(t, db.clearPubSubProject(t.Context()))
+	require.Equal(t, []string{
+		"/v1/projects/p/subscriptions/s1",
+		"/v1/projects/p/topics/t1",
+		"/v1/projects/p/topics/t2",
+	}, deleted)
+}
@@ -0,0 +1,110 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+const (
+	// questDBILPPort is the InfluxDB line protocol (ILP) port of QuestDB.
+	questDBILPPort = 9009
+	// questDBHTTPPort is the HTTP port of QuestDB (REST API, web console and ILP over HTTP).
+	questDBHTTPPort = 9000
+)
+
+// QuestDBInformer extends Informer with QuestDB ingestion endpoints.
+type QuestDBInformer interface {
+	Informer
+	// ILPPort returns the InfluxDB line protocol (TCP) port.
+	ILPPort() int
+	// HTTPPort returns the HTTP port (REST API and ILP over HTTP).
+	HTTPPort() int
+}
+
+// GetQuestDBConn inits a test QuestDB database, applies migrations,
+// and returns sql connection (pgx driver) to the Postgres wire protocol port.
+// QuestDB has no separate databases, so tests share the database from the DSN and should use unique table names.
+// In RunModeDocker ILP and HTTP ports are published on random host ports,
+// otherwise the default ports 9009 and 9000 on the DSN host are used.
+// Docker image: https://hub.docker.com/r/questdb/questdb.
+func GetQuestDBConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, QuestDBInformer) {
+	tb.Helper()
+
+	ctx := context.Background()
+
+	url, err := parseURL(dsn)
+	if err != nil {
+		tb.Fatalf("failed to parse dsn: %v", err)
+	}
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("questdb/questdb"),
+		withDockerDataDir("/var/lib/questdb"),
+		WithDockerImage("8.3.3"),
+		WithDockerEnv([]string{
+			fmt.Sprintf("QDB_PG_USER=%s", url.User),
+			fmt.Sprintf("QDB_PG_PASSWORD=%s", url.Password),
+		}),
+		withoutTestDatabase(),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults("questdb", opt)...)
+
+	tDB := newTDB(ctx, tb, "pgx", dsn, optPrepared)
+
+	db, err := tDB.connectSQLDB(ctx, true)
+	if err != nil {
+		tb.Fatalf("cannot connect to questdb: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		if closeErr := closeResourceWithTimeout(tDB.closeTimeout, db.Close, func() string {
+			return tDB.closeTimeoutDetails("questdb sql connection", nil)
+		}); closeErr != nil {
+			tb.Errorf("%v", closeErr)
+		}
+	})
+
+	return db, &questDBInfo{
+		testDB:   tDB,
+		ilpPort:  tDB.publishedPort(questDBILPPort),
+		httpPort: tDB.publishedPort(questDBHTTPPort),
+	}
+}
+
+// questDBInfo implements QuestDBInformer.
+type questDBInfo struct {
+	*testDB
+
+	ilpPort  int
+	httpPort int
+}
+
+// ILPPort returns the InfluxDB line protocol (TCP) port.
+func (q *questDBInfo) ILPPort() int {
+	return q.ilpPort
+}
+
+// HTTPPort returns the HTTP port (REST API and ILP over HTTP).
+func (q *questDBInfo) HTTPPort() int {
+	return q.httpPort
+}
+
+// publishedPort returns the host port of the container port in RunModeDocker,
+// otherwise the container port itself.
+func (d *testDB) publishedPort(containerPort int) int {
+	if d.resource == nil {
+		return containerPort
+	}
+
+	port, err := strconv.Atoi(d.resource.GetPort(fmt.Sprintf("%d/tcp", containerPort)))
+	if err != nil {
+		return containerPort
+	}
+
+	return port
+}
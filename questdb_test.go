@@ -0,0 +1,24 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QuestDB(t *testing.T) {
+	t.Parallel()
+
+	db, informer := GetQuestDBConn(t,
+		DefaultQuestDBDSN,
+		WithMode(RunModeDocker),
+	)
+
+	require.Equal(t, "qdb", informer.DatabaseName())
+	require.Positive(t, informer.ILPPort())
+	require.Positive(t, informer.HTTPPort())
+
+	var value int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT 1").Scan(&value))
+	require.Equal(t, 1, value)
+}
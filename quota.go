@@ -0,0 +1,183 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+const (
+	// testDatabaseSlotLockClass is the first key of PostgreSQL advisory locks used as test database slots.
+	testDatabaseSlotLockClass = 0x74647164
+	// testDatabaseSlotWait limits the wait for a free slot if the test has no deadline, like go test -timeout.
+	testDatabaseSlotWait = 10 * time.Minute
+	// testDatabaseSlotInterval is the first interval between the attempts to take a slot.
+	testDatabaseSlotInterval = 100 * time.Millisecond
+)
+
+// errNoFreeTestDatabaseSlot is returned while all test database slots are taken.
+var errNoFreeTestDatabaseSlot = errors.New("no free test database slot")
+
+// WithMaxConcurrentTestDatabases limits the number of test databases existing at the same time on a shared
+// PostgreSQL or MySQL server in RunModeExternal. Tests wait for a free slot until the test deadline.
+func WithMaxConcurrentTestDatabases(n int) Option {
+	return func(o *testDB) {
+		o.maxTestDatabases = n
+	}
+}
+
+// prepareQuotaOptions validates WithMaxConcurrentTestDatabases.
+func (d *testDB) prepareQuotaOptions() error {
+	if d.maxTestDatabases == 0 {
+		return nil
+	}
+	if d.maxTestDatabases < 0 {
+		return errors.New("max concurrent test databases must be greater than 0")
+	}
+	if d.slotLockQuery() == "" {
+		return fmt.Errorf("WithMaxConcurrentTestDatabases is not supported for driver %s", d.driver)
+	}
+
+	return nil
+}
+
+// acquireTestDatabaseSlot waits for a free test database slot on the server.
+// The slot is held by a dedicated connection until releaseTestDatabaseSlot.
+func (d *testDB) acquireTestDatabaseSlot(ctx context.Context) error {
+	if d.maxTestDatabases == 0 || d.mode != RunModeExternal {
+		return nil
+	}
+
+	dsn := d.url.string(false)
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return fmt.Errorf("sql open url (%s): %w", d.dsnNoPass, err)
+	}
+
+	var conn *sql.Conn
+	if err = d.retryConnect(ctx, d.dsnNoPass, func() error {
+		var connErr error
+		conn, connErr = db.Conn(ctx)
+		return connErr
+	}); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("connect for test database slot: %w", err)
+	}
+
+	var attempt int
+	slot, err := backoff.Retry(ctx, func() (int, error) {
+		slot, lockErr := d.lockTestDatabaseSlot(ctx, conn)
+		if lockErr != nil {
+			return 0, backoff.Permanent(lockErr)
+		}
+		if slot < 0 {
+			attempt++
+			d.logger.Info(ctx, "waiting for free test database slot", "dsn", d.dsnNoPass,
+				"max", d.maxTestDatabases, "attempt", attempt, "holders", d.testDatabaseSlotHolders(ctx, conn))
+			return 0, errNoFreeTestDatabaseSlot
+		}
+		return slot, nil
+	}, backoff.WithBackOff(d.newSlotBackOff()),
+		backoff.WithMaxElapsedTime(d.untilTestDeadline(testDatabaseSlotWait)))
+	if err != nil {
+		_ = conn.Close()
+		_ = db.Close()
+		return fmt.Errorf("wait for test database slot after %d attempts: %w", attempt, err)
+	}
+
+	d.logger.Info(ctx, "test database slot acquired", "dsn", d.dsnNoPass, "slot", slot)
+	d.releaseSlot = func() {
+		_ = conn.Close()
+		_ = db.Close()
+	}
+
+	return nil
+}
+
+// lockTestDatabaseSlot tries to take one of the slots without waiting and returns it, or -1 if all slots are taken.
+func (d *testDB) lockTestDatabaseSlot(ctx context.Context, conn *sql.Conn) (int, error) {
+	for slot := range d.maxTestDatabases {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, d.slotLockQuery(), slot).Scan(&acquired); err != nil {
+			return 0, fmt.Errorf("lock test database slot %d: %w", slot, err)
+		}
+		if acquired {
+			return slot, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// testDatabaseSlotHolders describes the sessions which hold the slots, so a stuck test can be found.
+// Errors are ignored, the description is only logged.
+func (d *testDB) testDatabaseSlotHolders(ctx context.Context, conn *sql.Conn) []string {
+	holders := make([]string, 0, d.maxTestDatabases)
+	for slot := range d.maxTestDatabases {
+		var holder sql.NullString
+		if err := conn.QueryRowContext(ctx, d.slotHolderQuery(), slot).Scan(&holder); err != nil {
+			return holders
+		}
+		if holder.String != "" {
+			holders = append(holders, fmt.Sprintf("slot %d: %s", slot, holder.String))
+		}
+	}
+
+	return holders
+}
+
+// newSlotBackOff returns the backoff of the wait for a free slot: WithRetryPolicy
+// or an exponential one up to the retry timeout.
+func (d *testDB) newSlotBackOff() backoff.BackOff {
+	if d.retryBackOff != nil {
+		return d.newRetryBackOff()
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = testDatabaseSlotInterval
+	b.MaxInterval = d.retryTimeout
+	b.Reset()
+
+	return b
+}
+
+// releaseTestDatabaseSlot releases the slot by closing its connection, which also releases the session lock.
+func (d *testDB) releaseTestDatabaseSlot() {
+	if d.releaseSlot == nil {
+		return
+	}
+
+	d.releaseSlot()
+	d.releaseSlot = nil
+}
+
+// slotLockQuery returns the query which tries to take the session lock of the slot without waiting.
+func (d *testDB) slotLockQuery() string {
+	switch {
+	case isPostgresDriver(d.driver):
+		return fmt.Sprintf("SELECT pg_try_advisory_lock(%d, $1)", testDatabaseSlotLockClass)
+	case d.driver == "mysql":
+		return "SELECT COALESCE(GET_LOCK(CONCAT('testdock_slot_', ?), 0), 0) = 1"
+	default:
+		return ""
+	}
+}
+
+// slotHolderQuery returns the query which describes the session holding the lock of the slot, empty if it is free.
+func (d *testDB) slotHolderQuery() string {
+	switch {
+	case isPostgresDriver(d.driver):
+		return fmt.Sprintf("SELECT string_agg('pid ' || l.pid || ' ' || COALESCE(a.application_name, '') || "+
+			"' ' || COALESCE(host(a.client_addr), 'local'), ', ') FROM pg_locks l "+
+			"LEFT JOIN pg_stat_activity a ON a.pid = l.pid WHERE l.locktype = 'advisory' AND l.granted "+
+			"AND l.classid::bigint = %d AND l.objid::bigint = $1 AND l.objsubid = 2", testDatabaseSlotLockClass)
+	case d.driver == "mysql":
+		return "SELECT CONCAT('connection ', IS_USED_LOCK(CONCAT('testdock_slot_', ?)))"
+	default:
+		return ""
+	}
+}
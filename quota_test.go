@@ -0,0 +1,66 @@
+package testdock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithMaxConcurrentTestDatabases verifies validation of the option.
+func TestWithMaxConcurrentTestDatabases(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithMaxConcurrentTestDatabases(3)}))
+	require.Equal(t, 3, db.maxTestDatabases)
+	require.Contains(t, db.slotLockQuery(), "pg_try_advisory_lock")
+
+	db = newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithMaxConcurrentTestDatabases(-1)})
+	require.ErrorContains(t, err, "must be greater than 0")
+
+	db = newExternalTestDB(t)
+	db.driver = "mysql"
+	db.dsn = DefaultMySQLDSN
+	require.NoError(t, db.prepareOptions("mysql", []Option{WithMaxConcurrentTestDatabases(1)}))
+	require.Contains(t, db.slotLockQuery(), "GET_LOCK")
+
+	db = newExternalTestDB(t)
+	db.driver = oracleDriverName
+	db.dsn = DefaultOracleDSN
+	err = db.prepareOptions(oracleDriverName, []Option{WithMaxConcurrentTestDatabases(1)})
+	require.ErrorContains(t, err, "not supported for driver oracle")
+}
+
+// TestAcquireTestDatabaseSlotSkipsOtherModes verifies that slots are used only in RunModeExternal.
+func TestAcquireTestDatabaseSlotSkipsOtherModes(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithMaxConcurrentTestDatabases(1)}))
+	db.mode = RunModeDocker
+	require.NoError(t, db.acquireTestDatabaseSlot(t.Context()))
+	require.Nil(t, db.releaseSlot)
+	db.releaseTestDatabaseSlot()
+}
+
+// TestTestDatabaseSlotWait verifies the backoff of the wait for a free slot and the queries of the slot holders.
+func TestTestDatabaseSlotWait(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithMaxConcurrentTestDatabases(2)}))
+	require.InDelta(t, testDatabaseSlotInterval, db.newSlotBackOff().NextBackOff(), float64(testDatabaseSlotInterval)/2)
+	require.Contains(t, db.slotHolderQuery(), "pg_locks")
+	require.LessOrEqual(t, db.untilTestDeadline(testDatabaseSlotWait), testDatabaseSlotWait)
+
+	db = newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMaxConcurrentTestDatabases(2), WithRetryPolicy(time.Second, time.Second, 1, 0),
+	}))
+	require.Equal(t, time.Second, db.newSlotBackOff().NextBackOff())
+
+	db.driver = "mysql"
+	require.Contains(t, db.slotHolderQuery(), "IS_USED_LOCK")
+}
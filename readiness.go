@@ -0,0 +1,49 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// readinessCheck is a query which must succeed before the database is considered ready.
+type readinessCheck struct {
+	query  string
+	expect func(rows *sql.Rows) error
+}
+
+// WithReadinessQuery adds a query which must succeed, in addition to Ping, before the SQL database is considered ready.
+// expect receives the rows before the first Next call and can be nil.
+func WithReadinessQuery(query string, expect func(rows *sql.Rows) error) Option {
+	return func(o *testDB) {
+		o.readinessChecks = append(o.readinessChecks, readinessCheck{query: query, expect: expect})
+	}
+}
+
+// checkReadiness executes the readiness queries.
+func (d *testDB) checkReadiness(ctx context.Context, db *sql.DB) error {
+	for _, check := range d.readinessChecks {
+		if err := check.run(ctx, db); err != nil {
+			return fmt.Errorf("readiness query (%s): %w", check.query, err)
+		}
+	}
+
+	return nil
+}
+
+// run executes the query and checks the result.
+func (c readinessCheck) run(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, c.query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err is checked below.
+
+	if c.expect != nil {
+		if err = c.expect(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,88 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readinessTestDriver returns one row after the query was executed readyAfter times.
+type readinessTestDriver struct {
+	calls      atomic.Int32
+	readyAfter int32
+}
+
+func (d *readinessTestDriver) Open(_ string) (driver.Conn, error) {
+	return readinessTestConn{d: d}, nil
+}
+
+type readinessTestConn struct {
+	d *readinessTestDriver
+}
+
+func (c readinessTestConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c readinessTestConn) Close() error { return nil }
+
+func (c readinessTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c readinessTestConn) QueryContext(_ context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	return &readinessTestRows{ready: c.d.calls.Add(1) >= c.d.readyAfter}, nil
+}
+
+type readinessTestRows struct {
+	ready bool
+}
+
+func (r *readinessTestRows) Columns() []string { return []string{"ready"} }
+
+func (r *readinessTestRows) Close() error { return nil }
+
+func (r *readinessTestRows) Next(dest []driver.Value) error {
+	if !r.ready {
+		return io.EOF
+	}
+	r.ready = false
+	dest[0] = int64(1)
+	return nil
+}
+
+// TestWithReadinessQuery verifies that the readiness query is retried until expect succeeds.
+func TestWithReadinessQuery(t *testing.T) {
+	t.Parallel()
+
+	testDriver := &readinessTestDriver{readyAfter: 3} //nolint:exhaustruct // calls starts from 0.
+	sql.Register("testdock_readiness", testDriver)
+
+	db := newExternalTestDB(t)
+	db.driver = "testdock_readiness"
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithRetryTimeout(time.Millisecond),
+		WithReadinessQuery("SELECT ready", func(rows *sql.Rows) error {
+			if !rows.Next() {
+				return errors.New("no rows")
+			}
+			return nil
+		}),
+	}))
+
+	conn, err := db.connectSQLDB(t.Context(), false)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.Equal(t, int32(3), testDriver.calls.Load())
+
+	db = newExternalTestDB(t)
+	err = db.prepareOptions(db.driver, []Option{WithReadinessQuery(" ", nil)})
+	require.ErrorContains(t, err, "readiness query is empty")
+}
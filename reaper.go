@@ -0,0 +1,194 @@
+package testdock
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	// dockerLabelSession is the label of containers and networks with the id of the test binary run.
+	dockerLabelSession = "testdock.session"
+	// dockerLabelCreated is the label of networks with the creation time, docker does not report it in the list.
+	dockerLabelCreated = "testdock.created"
+	// dockerLabelKeep is the label of containers of WithKeepContainerOnFailure, the reaper never removes them.
+	dockerLabelKeep = "testdock.keep"
+	// defaultReaperTTL is the default age of leftover containers and networks removed by the reaper.
+	defaultReaperTTL = time.Hour
+)
+
+//nolint:gochecknoglobals // the session is the run of the test binary, the reaper runs once per run.
+var (
+	dockerSessionID = uuid.NewString()
+	reaperOnce      sync.Once
+)
+
+// WithReaperTTL sets the age of leftover containers and networks of previous runs which are removed.
+// The default is 1 hour, zero disables the reaper.
+func WithReaperTTL(ttl time.Duration) Option {
+	return func(o *testDB) {
+		o.reaperTTL = ttl
+	}
+}
+
+// dockerNetworkLabels returns the labels of the networks of the test database.
+func dockerNetworkLabels() map[string]string {
+	return map[string]string{
+		dockerLabelSession: dockerSessionID,
+		dockerLabelCreated: strconv.FormatInt(time.Now().Unix(), 10),
+	}
+}
+
+// reapOrphanedResources removes leftover containers and networks of previous runs once per test binary.
+// Errors are logged and do not fail the test.
+func (d *testDB) reapOrphanedResources(ctx context.Context) {
+	if d.reaperTTL <= 0 {
+		return
+	}
+
+	reaperOnce.Do(func() {
+		if endpoint := globalDockerPool.Client.Endpoint(); !isLocalDockerEndpoint(endpoint) {
+			d.logger.Info(ctx, "the docker daemon is remote, leftover resources are not removed", "component", "reaper",
+				"endpoint", endpoint)
+			return
+		}
+
+		cutoff := time.Now().Add(-d.reaperTTL).Unix()
+		containers, networks := d.reapContainers(ctx, cutoff), d.reapNetworks(ctx, cutoff)
+		if containers > 0 || networks > 0 {
+			d.logger.Info(ctx, "removed leftover resources of previous runs", "component", "reaper",
+				"containers", containers, "networks", networks)
+		}
+	})
+}
+
+// reapContainers removes containers of other sessions created before the cutoff and returns their number.
+func (d *testDB) reapContainers(ctx context.Context, cutoff int64) int {
+	containers, err := globalDockerPool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		All:     true,
+		Filters: map[string][]string{"label": {dockerLabelSession}},
+	})
+	if err != nil {
+		d.logger.Info(ctx, "failed to list containers", "component", "reaper", "error", err)
+		return 0
+	}
+
+	var removed int
+	for _, c := range containers {
+		if isOrphanedResource(c.Labels, c.Created, cutoff) && d.reapContainer(ctx, c) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// reapContainer removes the container unless a running test binary holds its lease and reports the removal.
+// The lease stays locked during the removal, so no test binary attaches to the removed container.
+func (d *testDB) reapContainer(ctx context.Context, c docker.APIContainers) bool {
+	lease, keep := lockReapedContainerLease(c.Names)
+	if keep {
+		return false
+	}
+	defer lease.unlock()
+
+	err := globalDockerPool.Client.RemoveContainer(docker.RemoveContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		ID:            c.ID,
+		RemoveVolumes: true,
+		Force:         true,
+	})
+	if err != nil {
+		d.logger.Info(ctx, "failed to remove container", "component", "reaper", "container", c.ID, "error", err)
+		return false
+	}
+	_ = os.Remove(lease.path)
+
+	return true
+}
+
+// lockReapedContainerLease locks the lease of the container with the names and reports whether the container
+// must be kept, because a running test binary holds the lease or the lease can not be checked.
+func lockReapedContainerLease(names []string) (*containerLease, bool) {
+	if len(names) == 0 {
+		return nil, true
+	}
+
+	lease, err := lockContainerLease(names[0])
+	if err != nil {
+		return nil, true
+	}
+	if len(lease.pids) > 0 {
+		lease.unlock()
+		return nil, true
+	}
+
+	return lease, false
+}
+
+// reapNetworks removes networks of other sessions created before the cutoff and returns their number.
+func (d *testDB) reapNetworks(ctx context.Context, cutoff int64) int {
+	networks, err := globalDockerPool.Client.FilteredListNetworks(docker.NetworkFilterOpts{
+		"label": {dockerLabelSession: true},
+	})
+	if err != nil {
+		d.logger.Info(ctx, "failed to list networks", "component", "reaper", "error", err)
+		return 0
+	}
+
+	var removed int
+	for _, n := range networks {
+		created, parseErr := strconv.ParseInt(n.Labels[dockerLabelCreated], 10, 64)
+		if parseErr != nil || !isOrphanedResource(n.Labels, created, cutoff) {
+			continue
+		}
+		if err = globalDockerPool.Client.RemoveNetwork(n.ID); err != nil {
+			d.logger.Info(ctx, "failed to remove network", "component", "reaper", "network", n.Name, "error", err)
+			continue
+		}
+		removed++
+	}
+
+	return removed
+}
+
+// isOrphanedResource checks that the resource belongs to another session, is not kept for reuse or inspection
+// and was created before the cutoff.
+func isOrphanedResource(labels map[string]string, created, cutoff int64) bool {
+	if labels[dockerLabelSession] == dockerSessionID {
+		return false
+	}
+	if _, ok := labels[dockerLabelReuse]; ok {
+		return false
+	}
+	if _, ok := labels[dockerLabelKeep]; ok {
+		return false
+	}
+
+	return created < cutoff
+}
+
+// isLocalDockerEndpoint reports whether the docker daemon of the endpoint runs on this host,
+// so the leases of its containers can be checked.
+func isLocalDockerEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "unix", "npipe":
+		return true
+	case "tcp", "http", "https":
+		ip := net.ParseIP(u.Hostname())
+		return u.Hostname() == "localhost" || (ip != nil && ip.IsLoopback())
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package testdock
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsOrphanedResource verifies that only old resources of other sessions, which are not reused, are removed.
+func TestIsOrphanedResource(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.Now().Add(-time.Hour).Unix()
+	old := cutoff - 1
+	other := map[string]string{dockerLabelSession: "other"}
+
+	require.True(t, isOrphanedResource(other, old, cutoff))
+	require.False(t, isOrphanedResource(other, cutoff+1, cutoff))
+	require.False(t, isOrphanedResource(map[string]string{dockerLabelSession: dockerSessionID}, old, cutoff))
+	require.False(t, isOrphanedResource(map[string]string{dockerLabelSession: "other", dockerLabelReuse: "dev"}, old, cutoff))
+	require.False(t, isOrphanedResource(map[string]string{dockerLabelSession: "other", dockerLabelKeep: "true"}, old, cutoff))
+}
+
+// TestIsLocalDockerEndpoint verifies that the reaper runs only with a docker daemon of this host.
+func TestIsLocalDockerEndpoint(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isLocalDockerEndpoint("unix:///var/run/docker.sock"))
+	require.True(t, isLocalDockerEndpoint("npipe:////./pipe/docker_engine"))
+	require.True(t, isLocalDockerEndpoint("tcp://127.0.0.1:2375"))
+	require.True(t, isLocalDockerEndpoint("tcp://localhost:2375"))
+	require.False(t, isLocalDockerEndpoint("tcp://build-server:2376"))
+	require.False(t, isLocalDockerEndpoint(sshDockerEndpoint))
+}
+
+// TestLockReapedContainerLease verifies that containers leased by a running test binary are kept.
+func TestLockReapedContainerLease(t *testing.T) {
+	t.Parallel()
+
+	if !sharedContainerSupported {
+		t.Skip("container leases are not supported on this platform")
+	}
+
+	// a container without a name has no lease which can be checked
+	lease, keep := lockReapedContainerLease(nil)
+	require.Nil(t, lease)
+	require.True(t, keep)
+
+	// the go command which runs the test binary stands for another test binary
+	name := newLeaseTestContainerName(t)
+	writeContainerLease(t, name, os.Getppid())
+	lease, keep = lockReapedContainerLease([]string{"/" + name})
+	require.Nil(t, lease)
+	require.True(t, keep)
+
+	// the pid of an exited process
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	writeContainerLease(t, name, cmd.Process.Pid)
+	lease, keep = lockReapedContainerLease([]string{"/" + name})
+	require.False(t, keep)
+	require.NotNil(t, lease)
+	require.Empty(t, lease.pids)
+	lease.unlock()
+}
+
+// TestDockerLabels verifies the session label of containers and networks.
+func TestDockerLabels(t *testing.T) {
+	t.Parallel()
+
+	// the reaper is enabled by default
+	require.Equal(t, defaultReaperTTL, newTestDB(t, "pgx", DefaultPostgresDSN).reaperTTL)
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithReuseContainer("dev"), WithReaperTTL(time.Minute)}))
+	require.Equal(t, time.Minute, db.reaperTTL)
+
+	labels := db.containerLabels()
+	require.Equal(t, dockerSessionID, labels[dockerLabelSession])
+	require.Equal(t, db.dockerResourceLabel(), labels[dockerLabelKey])
+	require.Equal(t, "dev", labels[dockerLabelReuse])
+
+	networkLabels := dockerNetworkLabels()
+	require.Equal(t, dockerSessionID, networkLabels[dockerLabelSession])
+	require.NotEmpty(t, networkLabels[dockerLabelCreated])
+}
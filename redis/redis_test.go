@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/n-r-w/testdock/v2/internal/backend/backendtest"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Redis(t *testing.T) {
+	t.Parallel()
+
+	for _, flavor := range []Flavor{FlavorRedis, FlavorValkey, FlavorKeyDB} {
+		t.Run(string(flavor), func(t *testing.T) {
+			t.Parallel()
+
+			dsn := strings.Replace(testdock.DefaultRedisDSN, "6379", map[Flavor]string{
+				FlavorRedis: "6379", FlavorValkey: "6380", FlavorKeyDB: "6381",
+			}[flavor], 1)
+			client, informer := GetClient(t, dsn, flavor, testdock.WithMode(testdock.RunModeDocker))
+
+			key := informer.DatabaseName() + ":key"
+			require.NoError(t, client.Set(t.Context(), key, "value", 0).Err())
+
+			value, err := client.Get(t.Context(), key).Result()
+			require.NoError(t, err)
+			require.Equal(t, "value", value)
+		})
+	}
+}
+
+// TestDeleteKeys verifies authentication and that keys with the test prefix are deleted page by page.
+func TestDeleteKeys(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var (
+		mu       sync.Mutex
+		commands []string
+	)
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go serveFakeRedis(conn, func(args []string) string {
+				mu.Lock()
+				commands = append(commands, strings.Join(args, " "))
+				mu.Unlock()
+
+				switch strings.ToUpper(args[0]) {
+				case "HELLO", "CLIENT":
+					// the client falls back to RESP2 and ignores failed client info
+					return "-ERR unknown command\r\n"
+				case "PING":
+					return "+PONG\r\n"
+				case "SCAN":
+					if args[1] == "0" {
+						return fmt.Sprintf("*2\r\n$1\r\n7\r\n*1\r\n$%d\r\n%s\r\n", len(args[3]), args[3][:len(args[3])-1]+"a")
+					}
+					return "*2\r\n$1\r\n0\r\n*0\r\n"
+				case "DEL":
+					return ":1\r\n"
+				default:
+					return "+OK\r\n"
+				}
+			})
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr) //nolint:forcetypeassert // tcp listener.
+	prefix := "test_prefix"
+	db := backendtest.New(t, backend.URL{
+		User: "default", Password: "pass", Host: addr.IP.String(), Port: addr.Port, Database: "2",
+	}, prefix)
+
+	require.NoError(t, deleteKeys(t.Context(), db))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var sent []string
+	for _, command := range commands {
+		if !strings.HasPrefix(command, "hello") && !strings.HasPrefix(command, "client") {
+			sent = append(sent, command)
+		}
+	}
+	require.Equal(t, []string{
+		"auth default pass",
+		"select 2",
+		"ping",
+		"scan 0 match " + prefix + "* count 1000",
+		"del " + prefix + "a",
+		"scan 7 match " + prefix + "* count 1000",
+	}, sent)
+}
+
+// serveFakeRedis reads commands from the connection and writes the replies of the handler.
+func serveFakeRedis(conn net.Conn, handle func(args []string) string) {
+	defer conn.Close() //nolint:errcheck // test server.
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readFakeRedisCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err = conn.Write([]byte(handle(args))); err != nil {
+			return
+		}
+	}
+}
+
+// readFakeRedisCommand reads a command sent by the client as an array of bulk strings.
+func readFakeRedisCommand(r *bufio.Reader) ([]string, error) {
+	readLine := func(prefix byte) (int, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		if len(line) < 3 || line[0] != prefix { //nolint:mnd // prefix and CRLF.
+			return 0, fmt.Errorf("unexpected line %q", line)
+		}
+		return strconv.Atoi(strings.TrimSpace(line[1:]))
+	}
+
+	n, err := readLine('*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for range n {
+		size, err := readLine('$')
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2) //nolint:mnd // CRLF.
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,42 @@
+package testdock
+
+import (
+	"slices"
+	"sync"
+)
+
+//nolint:gochecknoglobals // defaults registered by the user for all tests of the binary.
+var (
+	driverDefaultsMu sync.RWMutex
+	driverDefaults   = make(map[string][]Option)
+)
+
+// RegisterDriverDefaults registers options applied by every Get... function of the driver,
+// so an organization can define its images, environment and cleanup hooks in one place,
+// for example in init or TestMain of a shared test package.
+// The options are applied after the defaults of the Get... function and before the options of the test.
+// Can be called multiple times, options are applied in order.
+// The driver is the name of the Get... function preset:
+//   - "pgx" (GetPgxPool), "postgres" (GetPqConn), "pgvector", "postgis", "timescaledb", "citus";
+//     GetPgvectorPool, GetPostgisPool, GetTimescalePool and GetCitusPool also apply "pgx" defaults
+//   - "mysql", "mariadb", "percona", "tidb", "oracle", "questdb", "clickhouse"
+//   - "mongodb", "surrealdb", "spanner", "firestore", "pubsub", "localstack"
+//   - "typesense", "meilisearch", "qdrant", "weaviate"
+//   - "tarantool" (tarantool.GetConn), "azblob" (azure.GetBlobContainer)
+//   - "redis", "valkey", "keydb" (the flavor of redis.GetClient)
+//   - "duckdb", and the driver of GetSQLiteConn and GetSQLConn.
+func RegisterDriverDefaults(driver string, opts ...Option) {
+	driverDefaultsMu.Lock()
+	defer driverDefaultsMu.Unlock()
+
+	driverDefaults[driver] = append(driverDefaults[driver], opts...)
+}
+
+// withDriverDefaults returns the registered defaults of the driver followed by opt.
+func withDriverDefaults(driver string, opt []Option) []Option {
+	driverDefaultsMu.RLock()
+	defaults := slices.Clone(driverDefaults[driver])
+	driverDefaultsMu.RUnlock()
+
+	return append(defaults, opt...)
+}
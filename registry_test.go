@@ -0,0 +1,32 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegisterDriverDefaults verifies that registered defaults are applied after the preset and before the test options.
+func TestRegisterDriverDefaults(t *testing.T) {
+	t.Parallel()
+
+	const driver = "testdock_registry_test"
+	RegisterDriverDefaults(driver, WithDockerRepository("registry.example.com/postgres"), WithDockerImage("16"))
+	RegisterDriverDefaults(driver, WithDockerImage("17"))
+
+	db := newExternalTestDB(t)
+	for _, o := range getPostgresOptions(t, driver, DefaultPostgresDSN, WithDockerImage("18")) {
+		o(db)
+	}
+	require.Equal(t, "registry.example.com/postgres", db.dockerRepository)
+	require.Equal(t, "18", db.dockerImage)
+
+	db = newExternalTestDB(t)
+	for _, o := range getPostgresOptions(t, driver, DefaultPostgresDSN) {
+		o(db)
+	}
+	require.Equal(t, "17", db.dockerImage)
+	require.Len(t, db.prepareCleanUp, 1)
+
+	require.Empty(t, withDriverDefaults("testdock_registry_unknown", nil))
+}
@@ -0,0 +1,387 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// sshDockerEndpoint is the placeholder endpoint of the docker client connected over SSH.
+// Requests are sent to the daemon through the SSH connection, so the host is not used.
+const sshDockerEndpoint = "http://docker.ssh:2375"
+
+// newDockerPool creates a docker pool for the endpoint, or for the endpoint of dockerEndpoint if it is empty.
+// In addition to dockertest it supports ssh://user@host endpoints, which use `docker system dial-stdio`
+// on the remote machine like the docker CLI, and the DOCKER_TLS_VERIFY and DOCKER_TLS variables.
+func newDockerPool(endpoint string) (*dockertest.Pool, error) {
+	endpoint = dockerEndpoint(endpoint)
+
+	u, err := url.Parse(endpoint)
+	if err != nil || endpoint == "" {
+		return dockertest.NewPool(endpoint)
+	}
+
+	var client *docker.Client
+	switch {
+	case u.Scheme == "ssh":
+		client, err = newSSHDockerClient(u)
+	case (u.Scheme == "tcp" || u.Scheme == "https") && dockerTLSEnabled():
+		client, err = newTLSDockerClient(endpoint)
+	default:
+		return dockertest.NewPool(endpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockertest.Pool{Client: client}, nil //nolint:exhaustruct // the same as dockertest.NewPool.
+}
+
+// dockerTLSEnabled checks the environment variables of the docker CLI which enable TLS.
+func dockerTLSEnabled() bool {
+	return os.Getenv("DOCKER_TLS_VERIFY") != "" || os.Getenv("DOCKER_TLS") != "" || os.Getenv("DOCKER_CERT_PATH") != ""
+}
+
+// newTLSDockerClient creates a docker client for a TCP endpoint protected by TLS.
+// Certificates are read from DOCKER_CERT_PATH, the default is ~/.docker. The client certificate is optional.
+// The server certificate is verified if DOCKER_TLS_VERIFY is set or ca.pem exists.
+func newTLSDockerClient(endpoint string) (*docker.Client, error) {
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("docker cert path: %w", err)
+		}
+		certPath = filepath.Join(home, ".docker")
+	}
+
+	ca, err := readDockerCert(certPath, "ca.pem")
+	if err != nil {
+		return nil, err
+	}
+	if ca == nil && os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		return nil, fmt.Errorf("DOCKER_TLS_VERIFY is set, but %s does not exist", filepath.Join(certPath, "ca.pem"))
+	}
+	cert, err := readDockerCert(certPath, "cert.pem")
+	if err != nil {
+		return nil, err
+	}
+	key, err := readDockerCert(certPath, "key.pem")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := docker.NewVersionedTLSClientFromBytes(endpoint, cert, key, ca, "")
+	if err != nil {
+		return nil, fmt.Errorf("docker tls client %s: %w", endpoint, err)
+	}
+
+	return client, nil
+}
+
+// readDockerCert reads the certificate file, returns nil if it does not exist.
+func readDockerCert(certPath, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(certPath, name)) //nolint:gosec // the path is configured by the user.
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read docker certificate: %w", err)
+	}
+
+	return data, nil
+}
+
+// newSSHDockerClient creates a docker client which connects to the daemon of the remote machine over SSH.
+// The ssh binary is used, so keys, agents and ~/.ssh/config work the same way as for the docker CLI.
+func newSSHDockerClient(u *url.URL) (*docker.Client, error) {
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("ssh docker endpoint %s has no host", u.Redacted())
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("ssh docker endpoint %s must not have a path", u.Redacted())
+	}
+
+	client, err := docker.NewClient(sshDockerEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("docker ssh client: %w", err)
+	}
+
+	dialer := &sshDockerDialer{url: u}
+	client.HTTPClient.Transport = &http.Transport{ //nolint:exhaustruct // optional transport fields use zero values.
+		DialContext:     func(ctx context.Context, _, _ string) (net.Conn, error) { return dialer.DialContext(ctx) },
+		IdleConnTimeout: time.Minute,
+	}
+	client.Dialer = dialer
+
+	return client, nil
+}
+
+// sshDockerDialer opens connections to the remote docker daemon with `ssh host docker system dial-stdio`.
+type sshDockerDialer struct {
+	url *url.URL
+}
+
+// Dial implements docker.Dialer for hijacked connections, for example exec.
+func (s *sshDockerDialer) Dial(_, _ string) (net.Conn, error) {
+	return s.DialContext(context.Background())
+}
+
+// DialContext starts the ssh process and returns its stdin and stdout as a connection.
+func (s *sshDockerDialer) DialContext(ctx context.Context) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// the process lives as long as the connection, so it is not bound to the dial context
+	cmd := exec.Command("ssh", sshDockerArgs(s.url)...) //nolint:gosec,noctx // the endpoint is configured by the user.
+	conn := &sshDockerConn{cmd: cmd, host: s.url.Host}
+	cmd.Stderr = &conn.stderr
+
+	var err error
+	if conn.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, fmt.Errorf("ssh stdin: %w", err)
+	}
+	if conn.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, fmt.Errorf("ssh stdout: %w", err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ssh to %s: %w", s.url.Host, err)
+	}
+
+	return conn, nil
+}
+
+// sshDockerArgs returns the arguments of the ssh command for the endpoint.
+// BatchMode prevents password prompts, which would hang the tests.
+func sshDockerArgs(u *url.URL) []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if u.User != nil && u.User.Username() != "" {
+		args = append(args, "-l", u.User.Username())
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+
+	return append(args, "--", u.Hostname(), "docker", "system", "dial-stdio")
+}
+
+// sshDockerConn is a connection to the remote docker daemon over the stdin and stdout of the ssh process.
+type sshDockerConn struct {
+	cmd    *exec.Cmd
+	host   string
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr lockedBuffer
+	once   sync.Once
+}
+
+// Read implements net.Conn. Errors of ssh, for example failed authentication, are added to io.EOF.
+func (c *sshDockerConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, io.EOF) {
+		if stderr := strings.TrimSpace(c.stderr.String()); stderr != "" {
+			return n, fmt.Errorf("ssh %s: %s: %w", c.host, stderr, err)
+		}
+	}
+
+	return n, err
+}
+
+// Write implements net.Conn.
+func (c *sshDockerConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close implements net.Conn and stops the ssh process.
+func (c *sshDockerConn) Close() error {
+	c.once.Do(func() {
+		_ = c.stdin.Close()
+		if c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		_ = c.cmd.Wait()
+	})
+
+	return nil
+}
+
+// LocalAddr implements net.Conn.
+func (c *sshDockerConn) LocalAddr() net.Addr {
+	return sshDockerAddr("local")
+}
+
+// RemoteAddr implements net.Conn.
+func (c *sshDockerConn) RemoteAddr() net.Addr {
+	return sshDockerAddr(c.host)
+}
+
+// SetDeadline implements net.Conn. Deadlines are not supported by pipes of a process and are ignored.
+func (c *sshDockerConn) SetDeadline(time.Time) error { return nil }
+
+// SetReadDeadline implements net.Conn.
+func (c *sshDockerConn) SetReadDeadline(time.Time) error { return nil }
+
+// SetWriteDeadline implements net.Conn.
+func (c *sshDockerConn) SetWriteDeadline(time.Time) error { return nil }
+
+// sshDockerAddr is the address of the SSH connection.
+type sshDockerAddr string
+
+// Network implements net.Addr.
+func (a sshDockerAddr) Network() string { return "ssh" }
+
+// String implements net.Addr.
+func (a sshDockerAddr) String() string { return string(a) }
+
+// lockedBuffer is a buffer written by the ssh process and read by the connection.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// String returns the content of the buffer.
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// dockerEndpoint returns the configured endpoint of the docker daemon, DOCKER_HOST, or the endpoint
+// of the Testcontainers configuration (tc.host or docker.host in ~/.testcontainers.properties),
+// which is written by Testcontainers Desktop and the Testcontainers Cloud agent.
+// Returns an empty string for the default local socket.
+func dockerEndpoint(endpoint string) string {
+	if endpoint != "" {
+		return endpoint
+	}
+	if endpoint = os.Getenv("DOCKER_HOST"); endpoint != "" {
+		return endpoint
+	}
+
+	props := testcontainersProperties()
+	if endpoint = props["tc.host"]; endpoint != "" {
+		return endpoint
+	}
+
+	return props["docker.host"]
+}
+
+// testcontainersProperties reads ~/.testcontainers.properties, returns nil if it does not exist.
+func testcontainersProperties() map[string]string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".testcontainers.properties")) //nolint:gosec // the file of the user.
+	if err != nil {
+		return nil
+	}
+
+	props := make(map[string]string)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return props
+}
+
+// remoteDockerHost returns the host where the ports of the containers are reachable,
+// or an empty string if the daemon is local. TESTCONTAINERS_HOST_OVERRIDE sets the host explicitly
+// like in Testcontainers, for example the gateway of the runner container in docker-in-docker CI.
+// Otherwise container ports are published on the machine of the daemon, so the database is available on its host.
+func (d *testDB) remoteDockerHost() string {
+	if host := os.Getenv("TESTCONTAINERS_HOST_OVERRIDE"); host != "" {
+		return host
+	}
+
+	return dockerEndpointHost(dockerEndpoint(d.dockerSocketEndpoint))
+}
+
+// dockerEndpointHost returns the host of a tcp, http, https or ssh docker endpoint,
+// or an empty string for unix sockets, named pipes and loopback addresses.
+func dockerEndpointHost(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "tcp://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https", "ssh":
+	default:
+		return ""
+	}
+	if host := u.Hostname(); !isLoopbackHost(host) {
+		return host
+	}
+
+	return ""
+}
+
+// isLoopbackHost checks that the host is localhost or a loopback IP address.
+func isLoopbackHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// useRemoteDockerHost replaces the loopback host of the DSN with the host of a remote docker daemon.
+func (d *testDB) useRemoteDockerHost() {
+	host := d.remoteDockerHost()
+	if host == "" || !isLoopbackHost(d.url.Host) {
+		return
+	}
+
+	d.url.Host = host
+	d.dsnNoPass = d.url.string(true)
+}
+
+// dockerHostIP returns the host IP of the published port of the container.
+// For an IPv6 DSN host, for example [::1], the port is published on the IPv6 address.
+// The port of a remote daemon is published on all interfaces, because the loopback of the remote machine
+// is not reachable from the tests.
+func (d *testDB) dockerHostIP() string {
+	if d.remoteDockerHost() != "" {
+		return ""
+	}
+
+	return d.url.Host
+}
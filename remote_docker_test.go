@@ -0,0 +1,101 @@
+package testdock
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDockerEndpointHost verifies detection of the host of a remote docker daemon.
+func TestDockerEndpointHost(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"":                                "",
+		"unix:///var/run/docker.sock":     "",
+		"npipe:////./pipe/docker_engine":  "",
+		"tcp://127.0.0.1:2375":            "",
+		"tcp://localhost:2375":            "",
+		"http://[::1]:2375":               "",
+		"tcp://build.example.com:2376":    "build.example.com",
+		"https://10.0.0.5:2376":           "10.0.0.5",
+		"ssh://ci@build.example.com":      "build.example.com",
+		"ssh://ci@build.example.com:2222": "build.example.com",
+		"build.example.com:2375":          "build.example.com",
+		"ssh://ci@[2001:db8::1]:22":       "2001:db8::1",
+	}
+	for endpoint, host := range tests {
+		require.Equal(t, host, dockerEndpointHost(endpoint), endpoint)
+	}
+}
+
+// TestSSHDockerArgs verifies the ssh command of an ssh:// docker endpoint.
+func TestSSHDockerArgs(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("ssh://ci@build.example.com:2222")
+	require.NoError(t, err)
+	require.Equal(t, []string{"-o", "BatchMode=yes", "-l", "ci", "-p", "2222", "--",
+		"build.example.com", "docker", "system", "dial-stdio"}, sshDockerArgs(u))
+
+	u, err = url.Parse("ssh://build.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"-o", "BatchMode=yes", "--", "build.example.com", "docker", "system", "dial-stdio"},
+		sshDockerArgs(u))
+
+	_, err = newDockerPool("ssh://build.example.com/var/run/docker.sock")
+	require.ErrorContains(t, err, "must not have a path")
+}
+
+// TestUseRemoteDockerHost verifies that the loopback host of the DSN is replaced with the remote docker host.
+func TestUseRemoteDockerHost(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	var err error
+	db.url, err = parseURL(DefaultPostgresDSN)
+	require.NoError(t, err)
+
+	db.dockerSocketEndpoint = "unix:///var/run/docker.sock"
+	db.useRemoteDockerHost()
+	require.Equal(t, "127.0.0.1", db.Host())
+	require.Equal(t, "127.0.0.1", db.dockerHostIP())
+
+	db.dockerSocketEndpoint = "ssh://ci@build.example.com"
+	db.useRemoteDockerHost()
+	require.Equal(t, "build.example.com", db.Host())
+	require.Empty(t, db.dockerHostIP())
+	require.Contains(t, db.dsnNoPass, "@build.example.com:5432/")
+}
+
+// TestDockerEndpointTestcontainers verifies the endpoint of the Testcontainers configuration
+// and TESTCONTAINERS_HOST_OVERRIDE.
+func TestDockerEndpointTestcontainers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("TESTCONTAINERS_HOST_OVERRIDE", "")
+
+	require.Empty(t, dockerEndpoint(""))
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".testcontainers.properties"),
+		[]byte("# written by the agent\ndocker.host=unix:///var/run/docker.sock\ntc.host = tcp://127.0.0.1:40213\n"), 0o600))
+	require.Equal(t, "tcp://127.0.0.1:40213", dockerEndpoint(""))
+	require.Equal(t, "unix:///run/user.sock", dockerEndpoint("unix:///run/user.sock"))
+
+	t.Setenv("DOCKER_HOST", "tcp://build.example.com:2375")
+	require.Equal(t, "tcp://build.example.com:2375", dockerEndpoint(""))
+
+	db := newExternalTestDB(t)
+	var err error
+	db.url, err = parseURL(DefaultPostgresDSN)
+	require.NoError(t, err)
+
+	t.Setenv("TESTCONTAINERS_HOST_OVERRIDE", "172.17.0.1")
+	db.useRemoteDockerHost()
+	require.Equal(t, "172.17.0.1", db.Host())
+	require.Empty(t, db.dockerHostIP())
+}
@@ -0,0 +1,53 @@
+package testdock
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+// retryPolicy is the exponential backoff of WithRetryPolicy.
+type retryPolicy struct {
+	initial     time.Duration // first wait
+	maxInterval time.Duration // upper limit of a wait
+	multiplier  float64       // growth of the wait after every attempt
+	jitter      float64       // randomization factor of the wait
+}
+
+// validate checks the parameters of WithRetryPolicy, nil is the default constant backoff.
+func (p *retryPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+
+	switch {
+	case p.initial <= 0:
+		return errors.New("retry policy initial interval must be greater than 0")
+	case p.maxInterval < p.initial:
+		return errors.New("retry policy max interval must not be less than the initial interval")
+	case p.multiplier < 1:
+		return errors.New("retry policy multiplier must not be less than 1")
+	case p.jitter < 0 || p.jitter >= 1:
+		return errors.New("retry policy jitter must be in [0, 1)")
+	}
+
+	return nil
+}
+
+// newRetryBackOff returns the backoff of connection retries. A new backoff is created for every retry loop,
+// because backoffs keep the state of the current wait and tests retry concurrently.
+func (d *testDB) newRetryBackOff() backoff.BackOff {
+	if d.retryBackOff == nil {
+		return backoff.NewConstantBackOff(d.retryTimeout)
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = d.retryBackOff.initial
+	b.MaxInterval = d.retryBackOff.maxInterval
+	b.Multiplier = d.retryBackOff.multiplier
+	b.RandomizationFactor = d.retryBackOff.jitter
+	b.Reset()
+
+	return b
+}
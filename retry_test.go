@@ -0,0 +1,84 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineTB is a testing.TB with the deadline of go test -timeout.
+type deadlineTB struct {
+	testing.TB
+	deadline time.Time
+}
+
+func (tb *deadlineTB) Deadline() (time.Time, bool) { return tb.deadline, true }
+
+// TestRetryConnectCanceled verifies that retries stop when the context is canceled.
+func TestRetryConnectCanceled(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.retryTimeout = time.Hour
+	db.totalRetryDuration = 2 * time.Hour
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var attempts int
+	start := time.Now()
+	err := db.retryConnect(ctx, "test", func() error {
+		attempts++
+		cancel()
+		return errors.New("not ready")
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, attempts)
+	require.Less(t, time.Since(start), time.Minute)
+}
+
+// TestUntilTestDeadline verifies that timeouts are limited by the deadline of the test.
+func TestUntilTestDeadline(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.t = &deadlineTB{TB: t, deadline: time.Now().Add(time.Minute)}
+	require.Equal(t, time.Second, db.untilTestDeadline(time.Second))
+	require.InDelta(t, time.Minute-testDeadlineMargin, db.untilTestDeadline(time.Hour), float64(time.Second))
+
+	db.t = &deadlineTB{TB: t, deadline: time.Now().Add(-time.Minute)}
+	require.Equal(t, time.Nanosecond, db.untilTestDeadline(time.Hour))
+
+	db.t = nil
+	require.Equal(t, time.Hour, db.untilTestDeadline(time.Hour))
+}
+
+// TestWithRetryPolicy verifies validation of WithRetryPolicy and the waits of the exponential backoff.
+func TestWithRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithRetryPolicy(100*time.Millisecond, 400*time.Millisecond, 2, 0),
+	}))
+	b := db.newRetryBackOff()
+	var waits []time.Duration
+	for range 4 {
+		waits = append(waits, b.NextBackOff())
+	}
+	require.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 400 * time.Millisecond,
+	}, waits)
+
+	db = newExternalTestDB(t)
+	require.Equal(t, DefaultRetryTimeout, db.newRetryBackOff().NextBackOff())
+
+	db = newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithRetryPolicy(time.Second, time.Millisecond, 2, 0)})
+	require.ErrorContains(t, err, "max interval must not be less than the initial interval")
+
+	db = newExternalTestDB(t)
+	err = db.prepareOptions(db.driver, []Option{WithRetryPolicy(time.Second, time.Second, 2, 1)})
+	require.ErrorContains(t, err, "jitter must be in [0, 1)")
+}
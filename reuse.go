@@ -0,0 +1,94 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// dockerLabelReuse is the container label with the key of WithReuseContainer.
+const dockerLabelReuse = "testdock.reuse"
+
+// WithReuseContainer keeps the docker container running after the tests and reuses it in the next go test runs.
+// Used only in RunModeDocker.
+func WithReuseContainer(key string) Option {
+	return func(o *testDB) {
+		o.reuseContainer = key
+	}
+}
+
+// prepareReuseOptions validates WithReuseContainer.
+func (d *testDB) prepareReuseOptions() error {
+	if d.reuseContainer == "" || d.mode != RunModeDocker {
+		return nil
+	}
+	if strings.ContainsAny(d.reuseContainer, "=,") {
+		return fmt.Errorf("reuse container key %q must not contain '=' or ','", d.reuseContainer)
+	}
+	if d.topologyRole != "" {
+		return errors.New("container reuse is not supported for multi-container topologies")
+	}
+
+	return nil
+}
+
+// findReusableContainer finds a running container of a previous run with the same reuse key and resource key
+// and returns it with the host port of the database.
+func (d *testDB) findReusableContainer() (*dockertest.Resource, int, bool) {
+	containers, err := globalDockerPool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Filters: map[string][]string{
+			"label": {
+				dockerLabelReuse + "=" + d.reuseContainer,
+				dockerLabelKey + "=" + d.dockerResourceLabel(),
+			},
+			"status": {"running"},
+		},
+	})
+	if err != nil {
+		return nil, 0, false
+	}
+
+	for _, c := range containers {
+		if len(c.Names) == 0 {
+			continue
+		}
+		resource, ok := containerByName(c.Names[0])
+		if !ok || resource.Container.ID != c.ID {
+			continue
+		}
+		port, err := strconv.Atoi(resource.GetPort(fmt.Sprintf("%d/tcp", d.dockerPort)))
+		if err != nil {
+			continue
+		}
+
+		return resource, port, true
+	}
+
+	return nil, 0, false
+}
+
+// reuseDockerResource uses a container of a previous run if WithReuseContainer is set.
+func (d *testDB) reuseDockerResource(ctx context.Context, info *dockerResourceInfo, logDsn string) bool {
+	if d.reuseContainer == "" {
+		return false
+	}
+
+	resource, port, ok := d.findReusableContainer()
+	if !ok {
+		return false
+	}
+
+	info.resource = resource
+	info.foreign = true
+	info.port = port
+	d.url.Port = port
+	d.logger.Info(ctx, "reusing container of a previous run", "component", "docker", "dsn", logDsn,
+		"container", resource.Container.ID)
+
+	return true
+}
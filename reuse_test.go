@@ -0,0 +1,78 @@
+package testdock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithReuseContainer verifies validation of the reuse key.
+func TestWithReuseContainer(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithReuseContainer("billing"),
+	}))
+	require.Equal(t, "billing", db.reuseContainer)
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithReuseContainer("a=b"),
+	})
+	require.ErrorContains(t, err, "must not contain")
+
+	err = newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("postgres"), WithReuseContainer("billing"), withPostgresReplica(),
+	})
+	require.ErrorContains(t, err, "not supported for multi-container topologies")
+}
+
+func Test_PgxReuseContainer(t *testing.T) {
+	t.Parallel()
+
+	const key = "testdock-reuse-test"
+
+	// the container outlives the test, it is removed after the test database
+	dockerPool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		containers, _ := dockerPool.Client.ListContainers(docker.ListContainersOptions{ //nolint:exhaustruct // only the filter is used.
+			Filters: map[string][]string{"label": {dockerLabelReuse + "=" + key}},
+		})
+		for _, c := range containers {
+			_ = dockerPool.Client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: true}) //nolint:exhaustruct // only the id is used.
+		}
+	})
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5546", 1)
+	var containerID string
+
+	// each subtest stands for a go test run, the second run reuses the container of the first one
+	// and creates the test database with the same name again
+	for i := range 2 {
+		t.Run(fmt.Sprintf("run%d", i), func(t *testing.T) {
+			pool, informer := GetPgxPool(t, dsn, WithReuseContainer(key), WithDatabaseName("testdock_reuse"))
+			require.NoError(t, pool.Ping(t.Context()))
+
+			db, ok := informer.(*testDB)
+			require.True(t, ok)
+			resource, port, ok := db.findReusableContainer()
+			require.True(t, ok)
+			require.Equal(t, 5546, port)
+			require.Equal(t, key, resource.Container.Config.Labels[dockerLabelReuse])
+			if containerID != "" {
+				require.Equal(t, containerID, resource.Container.ID)
+			}
+			containerID = resource.Container.ID
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TxScope is a transaction scope with savepoint-based nesting.
+// The root scope wraps a transaction owned by the caller, nested scopes are savepoints inside it.
+// It allows testing code which opens its own transactions inside the transaction of the test.
+// The database must support SAVEPOINT, ROLLBACK TO SAVEPOINT and RELEASE SAVEPOINT (PostgreSQL, MySQL, SQLite).
+// A transaction is not safe for concurrent use, so subtests started with Run must not be parallel.
+type TxScope struct {
+	exec   func(ctx context.Context, query string) error
+	parent *TxScope
+	name   string // savepoint name, empty for the root scope
+	depth  int
+	done   bool
+}
+
+// NewSQLTxScope returns the root scope for the database/sql transaction.
+func NewSQLTxScope(tx *sql.Tx) *TxScope {
+	return &TxScope{ //nolint:exhaustruct // root scope has no parent and savepoint.
+		exec: func(ctx context.Context, query string) error {
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// NewPgxTxScope returns the root scope for the pgx transaction.
+func NewPgxTxScope(tx pgx.Tx) *TxScope {
+	return &TxScope{ //nolint:exhaustruct // root scope has no parent and savepoint.
+		exec: func(ctx context.Context, query string) error {
+			_, err := tx.Exec(ctx, query)
+			return err
+		},
+	}
+}
+
+// Begin starts a nested scope with a new savepoint.
+func (s *TxScope) Begin(ctx context.Context) (*TxScope, error) {
+	if s.done {
+		return nil, errors.New("begin savepoint: scope is already finished")
+	}
+
+	child := &TxScope{
+		exec:   s.exec,
+		parent: s,
+		name:   fmt.Sprintf("testdock_sp_%d", s.depth+1),
+		depth:  s.depth + 1,
+		done:   false,
+	}
+	if err := s.exec(ctx, "SAVEPOINT "+child.name); err != nil {
+		return nil, fmt.Errorf("begin savepoint %s: %w", child.name, err)
+	}
+
+	return child, nil
+}
+
+// Commit releases the savepoint of the nested scope and keeps its changes in the parent scope.
+func (s *TxScope) Commit(ctx context.Context) error {
+	if err := s.finish(); err != nil {
+		return fmt.Errorf("commit savepoint: %w", err)
+	}
+
+	if err := s.exec(ctx, "RELEASE SAVEPOINT "+s.name); err != nil {
+		return fmt.Errorf("commit savepoint %s: %w", s.name, err)
+	}
+
+	return nil
+}
+
+// Rollback discards the changes of the nested scope and releases its savepoint.
+func (s *TxScope) Rollback(ctx context.Context) error {
+	if err := s.finish(); err != nil {
+		return fmt.Errorf("rollback savepoint: %w", err)
+	}
+
+	if err := s.exec(ctx, "ROLLBACK TO SAVEPOINT "+s.name); err != nil {
+		return fmt.Errorf("rollback savepoint %s: %w", s.name, err)
+	}
+	if err := s.exec(ctx, "RELEASE SAVEPOINT "+s.name); err != nil {
+		return fmt.Errorf("rollback savepoint %s: %w", s.name, err)
+	}
+
+	return nil
+}
+
+// Run runs f as a subtest inside a nested scope. Changes of the subtest are rolled back
+// when the subtest is finished, unless f commits the scope.
+func (s *TxScope) Run(t *testing.T, name string, f func(t *testing.T, scope *TxScope)) bool {
+	t.Helper()
+
+	return t.Run(name, func(t *testing.T) {
+		t.Helper()
+
+		scope, err := s.Begin(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if scope.done {
+				return
+			}
+			// t.Context is already canceled in cleanup.
+			if err := scope.Rollback(context.Background()); err != nil {
+				t.Error(err)
+			}
+		})
+
+		f(t, scope)
+	})
+}
+
+// finish marks the nested scope as finished.
+func (s *TxScope) finish() error {
+	if s.parent == nil {
+		return errors.New("root scope is owned by the caller, finish the transaction instead")
+	}
+	if s.done {
+		return fmt.Errorf("savepoint %s is already finished", s.name)
+	}
+	s.done = true
+
+	return nil
+}
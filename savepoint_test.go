@@ -0,0 +1,67 @@
+package testdock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newRecordingTxScope returns the root scope which records executed queries.
+func newRecordingTxScope(queries *[]string) *TxScope {
+	return &TxScope{ //nolint:exhaustruct // root scope has no parent and savepoint.
+		exec: func(_ context.Context, query string) error {
+			*queries = append(*queries, query)
+			return nil
+		},
+	}
+}
+
+// TestTxScopeNesting verifies savepoint statements of nested scopes.
+func TestTxScopeNesting(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	root := newRecordingTxScope(&queries)
+
+	outer, err := root.Begin(t.Context())
+	require.NoError(t, err)
+	inner, err := outer.Begin(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, inner.Rollback(t.Context()))
+	require.NoError(t, outer.Commit(t.Context()))
+
+	require.Equal(t, []string{
+		"SAVEPOINT testdock_sp_1",
+		"SAVEPOINT testdock_sp_2",
+		"ROLLBACK TO SAVEPOINT testdock_sp_2",
+		"RELEASE SAVEPOINT testdock_sp_2",
+		"RELEASE SAVEPOINT testdock_sp_1",
+	}, queries)
+
+	require.ErrorContains(t, outer.Commit(t.Context()), "already finished")
+	require.ErrorContains(t, root.Rollback(t.Context()), "root scope")
+	_, err = inner.Begin(t.Context())
+	require.ErrorContains(t, err, "already finished")
+}
+
+// TestTxScopeRun verifies that subtest changes are rolled back unless committed.
+func TestTxScopeRun(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	root := newRecordingTxScope(&queries)
+
+	root.Run(t, "rollback", func(_ *testing.T, _ *TxScope) {})
+	root.Run(t, "commit", func(t *testing.T, scope *TxScope) {
+		require.NoError(t, scope.Commit(t.Context()))
+	})
+
+	require.Equal(t, []string{
+		"SAVEPOINT testdock_sp_1",
+		"ROLLBACK TO SAVEPOINT testdock_sp_1",
+		"RELEASE SAVEPOINT testdock_sp_1",
+		"SAVEPOINT testdock_sp_1",
+		"RELEASE SAVEPOINT testdock_sp_1",
+	}, queries)
+}
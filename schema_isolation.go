@@ -0,0 +1,79 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WithSchemaIsolation creates a schema for every test instead of a database.
+// Only PostgreSQL is supported.
+func WithSchemaIsolation() Option {
+	return func(o *testDB) {
+		o.schemaIsolation = true
+	}
+}
+
+// prepareSchemaIsolationOptions validates WithSchemaIsolation and moves the generated name to the schema.
+func (d *testDB) prepareSchemaIsolationOptions() error {
+	if !d.schemaIsolation {
+		return nil
+	}
+
+	switch {
+	case !isPostgresDriver(d.driver):
+		return fmt.Errorf("WithSchemaIsolation is not supported for driver %s", d.driver)
+	case d.noTestDatabase:
+		return errors.New("WithSchemaIsolation can not be used with WithNoCreateDatabase")
+	case d.connectDatabase == "":
+		return errors.New("WithSchemaIsolation requires the database in the DSN or WithConnectDatabase")
+	case d.templateDatabase || d.databasePoolSize > 0 || d.sharedDatabase:
+		return errors.New("WithSchemaIsolation can not be used with WithTemplateDatabase, WithDatabasePoolSize " +
+			"and WithSharedDatabase")
+	case d.artifactMode != ArtifactModeOff:
+		return errors.New("WithSchemaIsolation can not be used with WithArtifact")
+	case d.citusWorkers > 0:
+		return errors.New("WithSchemaIsolation is not supported for Citus")
+	}
+
+	d.schemaName = d.databaseName
+	d.databaseName = d.connectDatabase
+
+	return nil
+}
+
+// schemaTestURL returns the url of the database from the DSN with search_path set to the schema of the test.
+func (d *testDB) schemaTestURL() *dbURL {
+	u := d.url.replaceDatabase(d.databaseName)
+	u.Options["search_path"] = d.schemaName
+
+	return u
+}
+
+// createTestSchema creates the schema of the test.
+func (d *testDB) createTestSchema(ctx context.Context) error {
+	d.logger.Info(ctx, "creating new test schema", "dsn", d.dsnNoPass, "database", d.databaseName,
+		"schema", d.schemaName)
+
+	db, err := d.connectSQLDB(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	if _, err = db.ExecContext(ctx, "CREATE SCHEMA "+pgx.Identifier{d.schemaName}.Sanitize()); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+
+	d.logger.Info(ctx, "new test schema created", "dsn", d.dsnNoPass, "database", d.databaseName,
+		"schema", d.schemaName)
+
+	return nil
+}
+
+// dropSchemaQuery returns the statement that removes the schema of the test with its objects.
+func (d *testDB) dropSchemaQuery() string {
+	return "DROP SCHEMA " + pgx.Identifier{d.schemaName}.Sanitize() + " CASCADE"
+}
@@ -0,0 +1,62 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxSchemaIsolation(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5561", 1)
+	options := []Option{
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithSchemaIsolation(),
+		WithDockerImage(testPostgresImage),
+	}
+
+	var schemas []string
+	for range 2 {
+		pool, info := GetPgxPool(t, dsn, options...)
+		require.Equal(t, "postgres", info.DatabaseName())
+
+		var schema string
+		require.NoError(t, pool.QueryRow(t.Context(), "SELECT current_schema()").Scan(&schema))
+		require.True(t, strings.HasPrefix(schema, "t_"))
+		schemas = append(schemas, schema)
+
+		var count int
+		require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM test_table").Scan(&count))
+		require.Equal(t, 1, count)
+		dbInfo, ok := info.(DatabaseInformer)
+		require.True(t, ok)
+		require.NoError(t, dbInfo.TruncateAll(t.Context()))
+	}
+	require.NotEqual(t, schemas[0], schemas[1])
+}
+
+// TestWithSchemaIsolation verifies validation of WithSchemaIsolation and the DSN of the test.
+func TestWithSchemaIsolation(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	require.NoError(t, db.prepareOptions(db.driver, []Option{WithSchemaIsolation()}))
+	require.Equal(t, "postgres", db.databaseName)
+	require.True(t, strings.HasPrefix(db.schemaName, "t_"))
+	require.Contains(t, db.DSN(), "/postgres?")
+	require.Contains(t, db.DSN(), "search_path="+db.schemaName)
+	require.Equal(t, `DROP SCHEMA "`+db.schemaName+`" CASCADE`, db.dropDatabaseQuery())
+	require.ErrorContains(t, db.Snapshot(t.Context(), "a"), "not supported with WithSchemaIsolation")
+
+	db = newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithSchemaIsolation(), WithSharedDatabase()})
+	require.ErrorContains(t, err, "WithSchemaIsolation can not be used with")
+
+	db = newExternalTestDB(t)
+	db.driver = "mysql"
+	db.dsn = DefaultMySQLDSN
+	err = db.prepareOptions(db.driver, []Option{WithSchemaIsolation()})
+	require.ErrorContains(t, err, "WithSchemaIsolation is not supported for driver mysql")
+}
@@ -0,0 +1,278 @@
+package testdock
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+)
+
+// SQLScriptMigrateFactory creates a migrator which executes *.sql files with the database/sql driver.
+// Use it for engines without goose or golang-migrate support.
+// Files must have a numeric prefix before "_", for example 0001_init.sql, and are applied in the order of versions.
+// If a file contains goose annotations, only the "-- +goose Up" section is executed.
+// Applied versions are not stored in the database, so the migrator is intended for new test databases only.
+func SQLScriptMigrateFactory(driver string) MigrateFactory {
+	return describeMigrateFactory(func(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		migrations, err := loadScriptMigrations(migrationsDir, "*.sql")
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := sql.Open(driver, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("sql open url (%s): %w", dsn, err)
+		}
+
+		return &scriptMigrator{
+			exec: func(ctx context.Context, script string) error {
+				_, execErr := db.ExecContext(ctx, script)
+				return execErr
+			},
+			begin:      nil,
+			close:      db.Close,
+			migrations: migrations,
+			logger:     logger,
+		}, nil
+	}, "sql script %s", driver)
+}
+
+// ScriptMigrateFactory creates a migrator which executes all *.sql files of the migrations directory
+// with the database/sql driver in the lexical order of names inside a single transaction,
+// for example schema.sql and seed.sql. File names need no versions and applied files are not stored,
+// so the migrator is intended for new test databases only. WithMigrationsToVersion requires a numeric prefix
+// before "_" in every file name, like SQLScriptMigrateFactory.
+// The driver must support multiple statements in one Exec. Note that MySQL commits DDL statements implicitly.
+func ScriptMigrateFactory(driver string) MigrateFactory {
+	return describeMigrateFactory(func(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		migrations, err := loadScriptFiles(migrationsDir, "*.sql")
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := sql.Open(driver, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("sql open url (%s): %w", dsn, err)
+		}
+
+		return &scriptMigrator{
+			exec:       nil,
+			begin:      func(ctx context.Context) (*sql.Tx, error) { return db.BeginTx(ctx, nil) },
+			close:      db.Close,
+			migrations: migrations,
+			logger:     logger,
+		}, nil
+	}, "script %s", driver)
+}
+
+// execScriptsTx executes the script files in one transaction.
+func execScriptsTx(ctx context.Context, db *sql.DB, paths []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op.
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // script files are provided by the test.
+		if err != nil {
+			return fmt.Errorf("read script: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, string(data)); err != nil {
+			return fmt.Errorf("script %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit scripts: %w", err)
+	}
+
+	return nil
+}
+
+// noScriptVersion is the version of a script file without a numeric prefix.
+const noScriptVersion = -1
+
+// scriptMigration is a migration file executed as a single script.
+type scriptMigration struct {
+	version int64 // noScriptVersion for the files of ScriptMigrateFactory without a numeric prefix
+	path    string
+}
+
+// loadScriptMigrations finds migration files by the pattern and sorts them by versions.
+func loadScriptMigrations(migrationsDir, pattern string) ([]scriptMigration, error) {
+	migrationsDir, err := resolveMigrationsDir(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMigrationFiles(migrationsDir, pattern); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(migrationsDir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	migrations := make([]scriptMigration, 0, len(paths))
+	for _, path := range paths {
+		prefix, _, _ := strings.Cut(filepath.Base(path), "_")
+		version, parseErr := strconv.ParseInt(prefix, 10, 64)
+		if parseErr != nil {
+			return nil, fmt.Errorf("migration %s: version prefix: %w", path, parseErr)
+		}
+		migrations = append(migrations, scriptMigration{version: version, path: path})
+	}
+	slices.SortFunc(migrations, func(a, b scriptMigration) int {
+		return cmp.Compare(a.version, b.version)
+	})
+
+	return migrations, nil
+}
+
+// loadScriptFiles finds script files by the pattern and sorts them by names.
+// Files without a numeric prefix before "_" have noScriptVersion.
+func loadScriptFiles(migrationsDir, pattern string) ([]scriptMigration, error) {
+	migrationsDir, err := resolveMigrationsDir(migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMigrationFiles(migrationsDir, pattern); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(migrationsDir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	slices.Sort(paths)
+
+	migrations := make([]scriptMigration, 0, len(paths))
+	for _, path := range paths {
+		prefix, _, _ := strings.Cut(filepath.Base(path), "_")
+		version, parseErr := strconv.ParseInt(prefix, 10, 64)
+		if parseErr != nil {
+			version = noScriptVersion
+		}
+		migrations = append(migrations, scriptMigration{version: version, path: path})
+	}
+
+	return migrations, nil
+}
+
+// scriptMigrator executes migration files as scripts.
+type scriptMigrator struct {
+	exec       func(ctx context.Context, script string) error
+	begin      func(ctx context.Context) (*sql.Tx, error) // executes all scripts in one transaction instead of exec
+	close      func() error
+	migrations []scriptMigration
+	logger     ctxlog.ILogger
+}
+
+// Up applies all migrations.
+func (m *scriptMigrator) Up(ctx context.Context) error {
+	return m.upTo(ctx, 0)
+}
+
+// UpTo applies migrations up to and including the target version.
+func (m *scriptMigrator) UpTo(ctx context.Context, version int64) error {
+	return m.upTo(ctx, version)
+}
+
+// upTo applies migrations up to the version, zero version means all migrations.
+// With begin the migrations are committed together, nothing is applied if any of them fails.
+func (m *scriptMigrator) upTo(ctx context.Context, version int64) error {
+	defer m.close() //nolint:errcheck // Close only releases resources; keep migration result.
+
+	exec := m.exec
+	var tx *sql.Tx
+	if m.begin != nil {
+		var err error
+		if tx, err = m.begin(ctx); err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op.
+
+		exec = func(ctx context.Context, script string) error {
+			_, execErr := tx.ExecContext(ctx, script)
+			return execErr
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if version > 0 && migration.version == noScriptVersion {
+			return fmt.Errorf("migration %s: the target version requires a numeric prefix before \"_\" in file names",
+				filepath.Base(migration.path))
+		}
+		if version > 0 && migration.version > version {
+			break
+		}
+
+		data, err := os.ReadFile(migration.path)
+		if err != nil {
+			return fmt.Errorf("read migration: %w", err)
+		}
+		if err = exec(ctx, gooseUpSection(string(data))); err != nil {
+			return fmt.Errorf("migration %s: %w", filepath.Base(migration.path), err)
+		}
+		m.logger.Info(ctx, "migration applied", "file", filepath.Base(migration.path))
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migrations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// gooseUpSection returns the "-- +goose Up" section of the script without goose annotations.
+// Scripts without annotations are returned as is.
+func gooseUpSection(script string) string {
+	const (
+		upAnnotation   = "-- +goose Up"
+		downAnnotation = "-- +goose Down"
+		annotation     = "-- +goose"
+	)
+
+	if !strings.Contains(script, upAnnotation) {
+		return script
+	}
+
+	var (
+		b    strings.Builder
+		isUp bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(nil, len(script)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, upAnnotation):
+			isUp = true
+			continue
+		case strings.HasPrefix(trimmed, downAnnotation):
+			isUp = false
+			continue
+		case strings.HasPrefix(trimmed, annotation):
+			continue
+		}
+		if isUp {
+			_, _ = b.WriteString(line)
+			_, _ = b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
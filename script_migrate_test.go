@@ -0,0 +1,172 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGooseUpSection verifies that only the up section of goose annotated scripts is executed.
+func TestGooseUpSection(t *testing.T) {
+	t.Parallel()
+
+	script := "-- +goose Up\n-- +goose StatementBegin\nCREATE TABLE a (id INT);\n-- +goose StatementEnd\n" +
+		"-- +goose Down\nDROP TABLE a;\n"
+	require.Equal(t, "CREATE TABLE a (id INT);\n", gooseUpSection(script))
+	require.Equal(t, "CREATE TABLE a (id INT);", gooseUpSection("CREATE TABLE a (id INT);"))
+}
+
+// TestScriptMigratorUpTo verifies the order of migrations and the target version boundary.
+func TestScriptMigratorUpTo(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := loadScriptMigrations("migrations/pg/goose_timestamp", "*.sql")
+	require.NoError(t, err)
+	require.Greater(t, len(migrations), 1)
+
+	var executed []string
+	migrator := &scriptMigrator{
+		exec: func(_ context.Context, script string) error {
+			executed = append(executed, script)
+			return nil
+		},
+		begin:      nil,
+		close:      func() error { return nil },
+		migrations: migrations,
+		logger:     ctxlog.Must(ctxlog.WithTesting(t)),
+	}
+
+	require.NoError(t, migrator.UpTo(context.Background(), migrations[0].version))
+	require.Len(t, executed, 1)
+	require.NotContains(t, executed[0], "+goose")
+}
+
+func Test_PgxScriptDB(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/script", ScriptMigrateFactory("pgx")),
+		WithDockerImage(testPostgresImage),
+	)
+
+	testPgxHelper(t, db)
+}
+
+// scriptTestDriver records the statements of transactions by the DSN, statements containing FAIL fail.
+type scriptTestDriver struct {
+	mu        sync.Mutex
+	committed map[string][]string
+}
+
+func (d *scriptTestDriver) Open(dsn string) (driver.Conn, error) {
+	return &scriptTestConn{d: d, dsn: dsn}, nil
+}
+
+type scriptTestConn struct {
+	d       *scriptTestDriver
+	dsn     string
+	pending []string
+}
+
+func (c *scriptTestConn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *scriptTestConn) Close() error { return nil }
+
+func (c *scriptTestConn) Begin() (driver.Tx, error) {
+	c.pending = nil
+	return c, nil
+}
+
+func (c *scriptTestConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+	c.pending = append(c.pending, strings.TrimSpace(query))
+	return driver.RowsAffected(0), nil
+}
+
+func (c *scriptTestConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.committed[c.dsn] = c.pending
+	return nil
+}
+
+func (c *scriptTestConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+var registerScriptTestDriver = sync.OnceValue(func() *scriptTestDriver {
+	d := &scriptTestDriver{committed: make(map[string][]string)} //nolint:exhaustruct // mutex starts from zero value.
+	sql.Register("testdock_script", d)
+	return d
+})
+
+// TestScriptMigrateFactory verifies the lexical order of scripts and that a failed script applies nothing.
+func TestScriptMigrateFactory(t *testing.T) {
+	t.Parallel()
+
+	testDriver := registerScriptTestDriver()
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "seed.sql"), []byte("INSERT 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.sql"), []byte("CREATE 1"), 0o600))
+
+	migrator, err := ScriptMigrateFactory("testdock_script")(t, "ok", dir, logger)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(t.Context()))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "z_broken.sql"), []byte("FAIL"), 0o600))
+	migrator, err = ScriptMigrateFactory("testdock_script")(t, "broken", dir, logger)
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Up(t.Context()), "migration z_broken.sql")
+
+	testDriver.mu.Lock()
+	defer testDriver.mu.Unlock()
+	require.Equal(t, []string{"CREATE 1", "INSERT 1"}, testDriver.committed["ok"])
+	require.NotContains(t, testDriver.committed, "broken")
+}
+
+// TestScriptMigrateFactoryUpTo verifies that the transactional scripts are applied up to the target version.
+func TestScriptMigrateFactoryUpTo(t *testing.T) {
+	t.Parallel()
+
+	testDriver := registerScriptTestDriver()
+	logger := ctxlog.Must(ctxlog.WithTesting(t))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_schema.sql"), []byte("CREATE 1"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0002_seed.sql"), []byte("INSERT 1"), 0o600))
+
+	migrator, err := ScriptMigrateFactory("testdock_script")(t, "up to", dir, logger)
+	require.NoError(t, err)
+	versioned, ok := migrator.(VersionedMigrator)
+	require.True(t, ok)
+	require.NoError(t, versioned.UpTo(t.Context(), 1))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "seed.sql"), []byte("INSERT 2"), 0o600))
+	migrator, err = ScriptMigrateFactory("testdock_script")(t, "no version", dir, logger)
+	require.NoError(t, err)
+	versioned, ok = migrator.(VersionedMigrator)
+	require.True(t, ok)
+	require.ErrorContains(t, versioned.UpTo(t.Context(), 2), "requires a numeric prefix")
+
+	testDriver.mu.Lock()
+	defer testDriver.mu.Unlock()
+	require.Equal(t, []string{"CREATE 1"}, testDriver.committed["up to"])
+	require.NotContains(t, testDriver.committed, "no version")
+}
@@ -0,0 +1,41 @@
+// Package search returns the clients of the search engines started by testdock.
+package search
+
+import (
+	"testing"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/typesense/typesense-go/v3/typesense"
+)
+
+// GetTypesenseClient starts Typesense like testdock.GetTypesense and returns the typesense-go client
+// authenticated with the admin API key of the DSN. Use collection names starting with SearchInformer.Prefix.
+func GetTypesenseClient(tb testing.TB, dsn string, opt ...testdock.Option,
+) (*typesense.Client, testdock.SearchInformer) {
+	tb.Helper()
+
+	informer := testdock.GetTypesense(tb, dsn, opt...)
+
+	client := typesense.NewClient(
+		typesense.WithServer(informer.Endpoint()),
+		typesense.WithAPIKey(informer.APIKey()),
+	)
+
+	return client, informer
+}
+
+// GetMeilisearchClient starts Meilisearch like testdock.GetMeilisearch and returns the meilisearch-go client
+// authenticated with the master key of the DSN. Use index names starting with SearchInformer.Prefix.
+// The client is closed after the test.
+func GetMeilisearchClient(tb testing.TB, dsn string, opt ...testdock.Option,
+) (meilisearch.ServiceManager, testdock.SearchInformer) {
+	tb.Helper()
+
+	informer := testdock.GetMeilisearch(tb, dsn, opt...)
+
+	client := meilisearch.New(informer.Endpoint(), meilisearch.WithAPIKey(informer.APIKey()))
+	tb.Cleanup(client.Close)
+
+	return client, informer
+}
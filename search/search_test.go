@@ -0,0 +1,42 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+func Test_Typesense(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetTypesenseClient(t, testdock.DefaultTypesenseDSN, testdock.WithMode(testdock.RunModeDocker))
+	name := informer.Prefix() + "_items"
+
+	_, err := client.Collections().Create(t.Context(), &api.CollectionSchema{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Name:   name,
+		Fields: []api.Field{{Name: "title", Type: "string"}}, //nolint:exhaustruct // optional SDK fields use zero values.
+	})
+	require.NoError(t, err)
+	collection, err := client.Collection(name).Retrieve(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, name, collection.Name)
+}
+
+func Test_Meilisearch(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetMeilisearchClient(t, testdock.DefaultMeilisearchDSN, testdock.WithMode(testdock.RunModeDocker))
+	name := informer.Prefix() + "_items"
+
+	task, err := client.CreateIndexWithContext(t.Context(), &meilisearch.IndexConfig{Uid: name}) //nolint:exhaustruct // optional SDK fields use zero values.
+	require.NoError(t, err)
+	_, err = client.WaitForTaskWithContext(t.Context(), task.TaskUID, 100*time.Millisecond)
+	require.NoError(t, err)
+	index, err := client.GetIndexWithContext(t.Context(), name)
+	require.NoError(t, err)
+	require.Equal(t, name, index.UID)
+}
@@ -0,0 +1,120 @@
+package testdock
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Typesense(t *testing.T) {
+	t.Parallel()
+
+	informer := GetTypesense(t, DefaultTypesenseDSN, WithMode(RunModeDocker))
+	require.Equal(t, "secret", informer.APIKey())
+	require.NotEmpty(t, informer.Prefix())
+	require.NotEmpty(t, informer.Endpoint())
+}
+
+func Test_Meilisearch(t *testing.T) {
+	t.Parallel()
+
+	informer := GetMeilisearch(t, DefaultMeilisearchDSN, WithMode(RunModeDocker))
+	require.Equal(t, "secret-master-key", informer.APIKey())
+	require.NotEmpty(t, informer.Prefix())
+	require.NotEmpty(t, informer.Endpoint())
+}
+
+// newSearchTestDB returns a test database for the search engine server.
+func newSearchTestDB(t *testing.T, driver, endpoint string) *testDB {
+	t.Helper()
+
+	db := newExternalTestDB(t)
+	db.driver = driver
+	db.dsn = strings.Replace(endpoint, "http://", "http://admin:key@", 1)
+	require.NoError(t, db.prepareOptions(driver, nil))
+
+	return db
+}
+
+// TestDeleteTypesensePrefix verifies that only collections with the test prefix are deleted.
+func TestDeleteTypesensePrefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+		prefix  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "key", r.Header.Get("X-Typesense-Api-Key"))
+
+		switch r.Method {
+		case http.MethodGet:
+			require.Equal(t, "/collections", r.URL.Path)
+			_, _ = fmt.Fprintf(w, `[{"name":"%s_books"},{"name":"other_books"}]`, prefix)
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/collections/"))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newSearchTestDB(t, typesenseDriverName, srv.URL)
+	prefix = db.databaseName
+
+	info := &searchInfo{testDB: db}
+	require.Equal(t, "key", info.APIKey())
+	require.Equal(t, srv.URL, info.Endpoint())
+	require.Equal(t, prefix, info.Prefix())
+
+	require.NoError(t, db.deleteSearchPrefix(t.Context()))
+	require.Equal(t, []string{prefix + "_books"}, deleted)
+}
+
+// TestDeleteMeilisearchPrefix verifies that indexes with the test prefix are deleted from all pages.
+func TestDeleteMeilisearchPrefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+		prefix  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+		switch r.Method {
+		case http.MethodGet:
+			require.Equal(t, "/indexes", r.URL.Path)
+			if r.URL.Query().Get("offset") != "0" {
+				_, _ = fmt.Fprintf(w, `{"results":[{"uid":"%s_last"}],"total":%d}`, prefix, meilisearchIndexPageSize+1)
+				return
+			}
+			results := make([]string, 0, meilisearchIndexPageSize)
+			results = append(results, fmt.Sprintf(`{"uid":"%s_movies"}`, prefix))
+			for i := 1; i < meilisearchIndexPageSize; i++ {
+				results = append(results, fmt.Sprintf(`{"uid":"other_%d"}`, i))
+			}
+			_, _ = fmt.Fprintf(w, `{"results":[%s],"total":%d}`, strings.Join(results, ","), meilisearchIndexPageSize+1)
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/indexes/"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newSearchTestDB(t, meilisearchDriverName, srv.URL)
+	prefix = db.databaseName
+
+	require.NoError(t, db.deleteSearchPrefix(t.Context()))
+	require.Equal(t, []string{prefix + "_movies", prefix + "_last"}, deleted)
+}
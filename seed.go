@@ -0,0 +1,86 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// seedStep fills the test database with data after migrations.
+type seedStep func(ctx context.Context, db *sql.DB) error
+
+// WithSeedScripts executes *.sql files of the directory in the lexical order of names inside a single transaction
+// after migrations, so reference and seed data can live separately from schema migrations.
+// Only databases with a database/sql driver are supported. Can be used multiple times with WithSeedFunc,
+// seeds are executed in order.
+func WithSeedScripts(dir string) Option {
+	return func(o *testDB) {
+		o.seeds = append(o.seeds, func(ctx context.Context, db *sql.DB) error {
+			return execSeedScripts(ctx, db, dir)
+		})
+	}
+}
+
+// WithSeedFunc calls f with the connection to the test database after migrations.
+// Only databases with a database/sql driver are supported. Can be used multiple times with WithSeedScripts,
+// seeds are executed in order.
+func WithSeedFunc(f func(db *sql.DB) error) Option {
+	return func(o *testDB) {
+		o.seeds = append(o.seeds, func(_ context.Context, db *sql.DB) error {
+			return f(db)
+		})
+	}
+}
+
+// execSeedScripts executes the seed scripts of the directory.
+func execSeedScripts(ctx context.Context, db *sql.DB, dir string) error {
+	dir, err := resolveMigrationsDir(dir)
+	if err != nil {
+		return err
+	}
+	if err = checkMigrationFiles(dir, "*.sql"); err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return fmt.Errorf("list seed scripts: %w", err)
+	}
+	slices.Sort(paths)
+
+	return execScriptsTx(ctx, db, paths)
+}
+
+// prepareSeedOptions checks that the driver can run the seeds.
+func (d *testDB) prepareSeedOptions() error {
+	if len(d.seeds) > 0 && !isSQLDriver(d.driver) {
+		return fmt.Errorf("seeds are not supported for driver %s", d.driver)
+	}
+
+	return nil
+}
+
+// seedTestDatabase executes the seeds in the test database.
+func (d *testDB) seedTestDatabase(ctx context.Context) error {
+	if len(d.seeds) == 0 {
+		return nil
+	}
+
+	d.logger.Info(ctx, "seeding test database", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	db, err := sql.Open(d.driver, d.DSN())
+	if err != nil {
+		return fmt.Errorf("sql open url (%s): %w", d.dsnNoPass, err)
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep seed result.
+
+	for i, seed := range d.seeds {
+		if err = seed(ctx, db); err != nil {
+			return fmt.Errorf("seed %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
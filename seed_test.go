@@ -0,0 +1,64 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxSeeds(t *testing.T) {
+	t.Parallel()
+
+	db, _ := GetPgxPool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithSeedScripts("migrations/pg/seed"),
+		WithSeedFunc(func(db *sql.DB) error {
+			_, err := db.Exec("INSERT INTO test_table (name) VALUES ('func')")
+			return err
+		}),
+		WithDockerImage(testPostgresImage),
+	)
+
+	requireTestTableNames(t, db, "test", "seed", "func")
+}
+
+// TestSeeds verifies the order of seeds and that seeds are rejected for drivers without database/sql.
+func TestSeeds(t *testing.T) {
+	t.Parallel()
+
+	testDriver := registerScriptTestDriver()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.sql"), []byte("INSERT 2"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.sql"), []byte("INSERT 1"), 0o600))
+
+	var calls []string
+	db := newExternalTestDB(t)
+	WithSeedFunc(func(*sql.DB) error {
+		calls = append(calls, "func")
+		return nil
+	})(db)
+	WithSeedScripts(dir)(db)
+	require.Len(t, db.seeds, 2)
+
+	conn, err := sql.Open("testdock_script", "seed")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	for _, seed := range db.seeds {
+		require.NoError(t, seed(context.Background(), conn))
+	}
+	require.Equal(t, []string{"func"}, calls)
+
+	testDriver.mu.Lock()
+	require.Equal(t, []string{"INSERT 1", "INSERT 2"}, testDriver.committed["seed"])
+	testDriver.mu.Unlock()
+
+	require.NoError(t, db.prepareSeedOptions())
+	db.driver = redisDriverName
+	require.ErrorContains(t, db.prepareSeedOptions(), "seeds are not supported")
+}
@@ -0,0 +1,93 @@
+package testdock
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Shared is a resource returned by a Get... function and shared between parallel subtests.
+// The resource is closed and the test database is removed only after the owner test
+// and all subtests which acquired the resource are finished.
+type Shared[T any] struct {
+	value    T
+	informer Informer
+
+	mu       sync.Mutex
+	count    int      // owner test and subtests which acquired the resource
+	cleanups []func() // cleanup functions registered by the Get... function
+}
+
+// NewShared creates a resource with the Get... function and shares it between subtests, for example:
+//
+//	shared := testdock.NewShared(t, func(tb testing.TB) (*pgxpool.Pool, testdock.Informer) {
+//		return testdock.GetPgxPool(tb, testdock.DefaultPostgresDSN)
+//	})
+//	t.Run("subtest", func(t *testing.T) {
+//		t.Parallel()
+//		pool, _ := shared.Acquire(t)
+//	})
+func NewShared[T any](tb testing.TB, get func(tb testing.TB) (T, Informer)) *Shared[T] {
+	tb.Helper()
+
+	s := &Shared[T]{ //nolint:exhaustruct // value and informer are filled below.
+		count: 1,
+	}
+	s.value, s.informer = get(&sharedTB{TB: tb, addCleanup: s.addCleanup})
+	tb.Cleanup(s.release)
+
+	return s
+}
+
+// Acquire returns the shared resource and defers its teardown until tb is finished.
+func (s *Shared[T]) Acquire(tb testing.TB) (T, Informer) {
+	tb.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		tb.Fatal("shared resource is already released")
+	}
+	s.count++
+	tb.Cleanup(s.release)
+
+	return s.value, s.informer
+}
+
+// addCleanup stores the cleanup function of the Get... function.
+func (s *Shared[T]) addCleanup(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanups = append(s.cleanups, f)
+}
+
+// release runs the stored cleanup functions in the reverse order after the last user is finished.
+func (s *Shared[T]) release() {
+	s.mu.Lock()
+	s.count--
+	if s.count > 0 {
+		s.mu.Unlock()
+		return
+	}
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.mu.Unlock()
+
+	for _, f := range slices.Backward(cleanups) {
+		f()
+	}
+}
+
+// sharedTB redirects the cleanup functions of the Get... function to Shared.
+type sharedTB struct {
+	testing.TB
+
+	addCleanup func(func())
+}
+
+// Cleanup registers the function which is called after the last user of the shared resource is finished.
+func (t *sharedTB) Cleanup(f func()) {
+	t.addCleanup(f)
+}
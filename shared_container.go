@@ -0,0 +1,192 @@
+package testdock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// sharedContainerState is the state file of WithSharedContainer with the container of a go test run.
+type sharedContainerState struct {
+	Parent    int    `json:"parent"`    // pid of the go command which runs the test binaries
+	Container string `json:"container"` // id of the container
+	Name      string `json:"name"`      // name of the container
+}
+
+// WithSharedContainer shares the docker container with the other test binaries of the same go test run.
+// Used only in RunModeDocker.
+func WithSharedContainer() Option {
+	return func(o *testDB) {
+		o.sharedContainer = true
+	}
+}
+
+// prepareSharedContainerOptions validates WithSharedContainer.
+func (d *testDB) prepareSharedContainerOptions() error {
+	if !d.sharedContainer || d.mode != RunModeDocker {
+		return nil
+	}
+
+	switch {
+	case !sharedContainerSupported:
+		return errors.New("container sharing is not supported on this platform")
+	case d.topologyRole != "":
+		return errors.New("container sharing is not supported for multi-container topologies")
+	case d.reuseContainer != "":
+		return errors.New("WithSharedContainer can not be used with WithReuseContainer")
+	}
+
+	return nil
+}
+
+// sharedContainerPath returns the path of the state file of the shared container, the lock file has the .lock suffix.
+func (d *testDB) sharedContainerPath() string {
+	return filepath.Join(os.TempDir(), "testdock-shared-"+d.dockerResourceLabel()+".json")
+}
+
+// lockSharedContainer takes the file lock of the shared container, so only one test binary creates it.
+// The lock is released by the returned function and is released by the system if the test binary is killed.
+func (d *testDB) lockSharedContainer() (func(), error) {
+	if !d.sharedContainer {
+		return func() {}, nil
+	}
+
+	return lockSharedContainerFile(d.sharedContainerPath())
+}
+
+// lockSharedContainerFile takes the file lock of the state file with the path.
+func lockSharedContainerFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open shared container lock: %w", err)
+	}
+	if err = lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock shared container: %w", err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// readSharedContainerState reads the state file of the shared container, false if it does not exist or is corrupted.
+func (d *testDB) readSharedContainerState() (sharedContainerState, bool) {
+	return readSharedContainerFile(d.sharedContainerPath())
+}
+
+// readSharedContainerFile reads the state file with the path, false if it does not exist or is corrupted.
+func readSharedContainerFile(path string) (sharedContainerState, bool) {
+	var state sharedContainerState
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, false
+	}
+	if err = json.Unmarshal(data, &state); err != nil {
+		return state, false
+	}
+
+	return state, true
+}
+
+// attachSharedContainer attaches to the container created by another test binary of the go test run
+// and joins its lease. A container left by a killed run is removed.
+// Must be called with the lock of lockSharedContainer.
+func (d *testDB) attachSharedContainer(ctx context.Context, info *dockerResourceInfo, logDsn string) bool {
+	if !d.sharedContainer {
+		return false
+	}
+
+	state, ok := d.readSharedContainerState()
+	if !ok {
+		return false
+	}
+
+	if state.Parent != os.Getppid() {
+		if processExists(state.Parent) {
+			d.logger.Info(ctx, "container is shared by another go test run, creating a new one",
+				"component", "docker", "dsn", logDsn, "container", state.Container)
+			return false
+		}
+
+		d.logger.Info(ctx, "removing shared container of a finished run", "component", "docker", "dsn", logDsn,
+			"container", state.Container)
+		_ = globalDockerPool.Client.RemoveContainer(docker.RemoveContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+			ID:            state.Container,
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		_ = os.Remove(d.sharedContainerPath())
+		return false
+	}
+
+	resource, ok := containerByName(state.Name)
+	if !ok || resource.Container.ID != state.Container || !resource.Container.State.Running {
+		_ = os.Remove(d.sharedContainerPath())
+		return false
+	}
+	port, err := strconv.Atoi(resource.GetPort(fmt.Sprintf("%d/tcp", d.dockerPort)))
+	if err != nil {
+		_ = os.Remove(d.sharedContainerPath())
+		return false
+	}
+	if err = d.adoptDockerResource(info, resource); err != nil {
+		// the test binaries which used the container have finished and are removing it
+		d.logger.Info(ctx, "shared container is not used by a running test binary, creating a new one",
+			"component", "docker", "dsn", logDsn, "container", state.Container, "error", err)
+		_ = os.Remove(d.sharedContainerPath())
+		return false
+	}
+
+	info.port = port
+	d.url.Port = port
+	d.logger.Info(ctx, "using shared container of the go test run", "component", "docker", "dsn", logDsn,
+		"container", resource.Container.ID)
+
+	return true
+}
+
+// registerSharedContainer saves the container created by the test binary to the state file,
+// so the next test binaries of the go test run attach to it, and starts its keeper.
+// Must be called with the lock of lockSharedContainer.
+func (d *testDB) registerSharedContainer(ctx context.Context, info *dockerResourceInfo, logDsn string) error {
+	if !d.sharedContainer || info.resource == nil {
+		return nil
+	}
+
+	state, ok := d.readSharedContainerState()
+	switch {
+	case ok && state.Container == info.resource.Container.ID:
+		// attached by attachSharedContainer
+		return nil
+	case ok && state.Parent != os.Getppid() && processExists(state.Parent):
+		// the container of another go test run is kept, the container of the test binary is not shared
+		return nil
+	}
+
+	data, err := json.Marshal(sharedContainerState{
+		Parent:    os.Getppid(),
+		Container: info.resource.Container.ID,
+		Name:      info.resource.Container.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal shared container state: %w", err)
+	}
+	if err = os.WriteFile(d.sharedContainerPath(), data, 0o600); err != nil {
+		return fmt.Errorf("write shared container state: %w", err)
+	}
+
+	d.logger.Info(ctx, "container is shared with the next test binaries of the go test run", "component", "docker",
+		"dsn", logDsn, "container", info.resource.Container.ID)
+	d.startSharedContainerKeeper(ctx, info, logDsn)
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package testdock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	// sharedContainerKeeperEnv is the environment variable which starts a copy of the test binary
+	// as the keeper of the shared container, its value is the JSON of sharedContainerKeeper.
+	sharedContainerKeeperEnv = "TESTDOCK_SHARED_CONTAINER_KEEPER"
+	// sharedContainerIdleTTL is the time the shared container is kept without test binaries which use it.
+	sharedContainerIdleTTL = time.Minute
+	// sharedContainerKeeperInterval is the interval of the checks of the keeper.
+	sharedContainerKeeperInterval = time.Second
+)
+
+// sharedContainerKeeper keeps the container of WithSharedContainer between the test binaries of the go test run.
+// The keeper is a copy of the test binary, which holds the lease of the container, so the test binaries
+// do not purge it. The keeper removes the container when no test binary has used it for sharedContainerIdleTTL
+// or the go command has exited.
+type sharedContainerKeeper struct {
+	Endpoint  string        `json:"endpoint"`  // docker endpoint of WithDockerSocketEndpoint
+	Container string        `json:"container"` // id of the container
+	Lease     string        `json:"lease"`     // name of the container lease
+	State     string        `json:"state"`     // path of the state file of the shared container
+	Parent    int           `json:"parent"`    // pid of the go command which runs the test binaries
+	TTL       time.Duration `json:"ttl"`       // time the container is kept without test binaries
+
+	idleSince time.Time // time when the last test binary released the lease
+}
+
+// The keeper process must not run the tests, so it is started before the testing package parses the flags.
+//
+//nolint:gochecknoinits // the keeper of WithSharedContainer is a copy of the test binary.
+func init() {
+	if config, ok := os.LookupEnv(sharedContainerKeeperEnv); ok {
+		os.Exit(runSharedContainerKeeper(config))
+	}
+}
+
+// startSharedContainerKeeper starts the keeper of the shared container created by the test binary
+// and adds it to the holders of the lease. Without the keeper the last test binary purges the container.
+func (d *testDB) startSharedContainerKeeper(ctx context.Context, info *dockerResourceInfo, logDsn string) {
+	if info.lease == "" {
+		// the container is not leased, so the test binary purges it
+		return
+	}
+
+	err := func() error {
+		config, err := json.Marshal(sharedContainerKeeper{
+			Endpoint:  d.dockerSocketEndpoint,
+			Container: info.resource.Container.ID,
+			Lease:     info.lease,
+			State:     d.sharedContainerPath(),
+			Parent:    os.Getppid(),
+			TTL:       sharedContainerIdleTTL,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal keeper config: %w", err)
+		}
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("test binary path: %w", err)
+		}
+
+		lease, err := lockContainerLease(info.lease)
+		if err != nil {
+			return err
+		}
+		defer lease.unlock()
+
+		cmd := exec.Command(executable) //nolint:gosec,noctx // the keeper outlives the test binary.
+		cmd.Env = append(os.Environ(), sharedContainerKeeperEnv+"="+string(config))
+		cmd.SysProcAttr = detachedProcAttr()
+		if err = cmd.Start(); err != nil {
+			return fmt.Errorf("start keeper: %w", err)
+		}
+		// the keeper exits before the test binary if the container is removed earlier
+		go func() { _ = cmd.Wait() }()
+
+		lease.pids = append(lease.pids, cmd.Process.Pid)
+		return lease.write()
+	}()
+	if err != nil {
+		d.logger.Info(ctx, "failed to start the keeper of the shared container", "component", "docker",
+			"dsn", logDsn, "error", err)
+	}
+}
+
+// runSharedContainerKeeper runs the keeper with the JSON config and returns the exit code of the process.
+func runSharedContainerKeeper(config string) int {
+	var k sharedContainerKeeper
+	if err := json.Unmarshal([]byte(config), &k); err != nil {
+		return 1
+	}
+	pool, err := newDockerPool(k.Endpoint)
+	if err != nil {
+		return 1
+	}
+
+	k.idleSince = time.Now()
+	ticker := time.NewTicker(sharedContainerKeeperInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		lease, err := lockContainerLease(k.Lease)
+		if err != nil {
+			return 1
+		}
+		if !k.expired(lease, now) {
+			lease.unlock()
+			continue
+		}
+
+		// the lease stays locked during the removal, so no test binary attaches to the removed container
+		_ = pool.Client.RemoveContainer(docker.RemoveContainerOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+			ID:            k.Container,
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		_, _ = lease.release()
+		lease.unlock()
+		k.removeState()
+
+		return 0
+	}
+
+	return 0
+}
+
+// expired reports whether the container must be removed: no test binary holds the lease
+// for the TTL or the go command has exited.
+func (k *sharedContainerKeeper) expired(lease *containerLease, now time.Time) bool {
+	if lease.heldByOthers() {
+		k.idleSince = now
+		return false
+	}
+
+	return !processExists(k.Parent) || now.Sub(k.idleSince) >= k.TTL
+}
+
+// removeState removes the state file if it still refers to the container of the keeper.
+func (k *sharedContainerKeeper) removeState() {
+	unlock, err := lockSharedContainerFile(k.State)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	if state, ok := readSharedContainerFile(k.State); ok && state.Container == k.Container {
+		_ = os.Remove(k.State)
+	}
+}
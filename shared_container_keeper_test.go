@@ -0,0 +1,46 @@
+package testdock
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSharedContainerKeeperExpired verifies when the keeper removes the shared container.
+func TestSharedContainerKeeperExpired(t *testing.T) {
+	t.Parallel()
+
+	if !sharedContainerSupported {
+		t.Skip("container sharing is not supported on this platform")
+	}
+
+	name := newLeaseTestContainerName(t)
+	now := time.Now()
+	keeper := &sharedContainerKeeper{Parent: os.Getppid(), TTL: time.Minute, idleSince: now.Add(-time.Hour)} //nolint:exhaustruct // only the expiration settings are used.
+
+	expired := func(at time.Time) bool {
+		lease, err := lockContainerLease(name)
+		require.NoError(t, err)
+		defer lease.unlock()
+		return keeper.expired(lease, at)
+	}
+
+	// the go command stands for a test binary which uses the container, the test binary stands for the keeper
+	writeContainerLease(t, name, os.Getpid(), os.Getppid())
+	require.False(t, expired(now))
+	require.Equal(t, now, keeper.idleSince)
+
+	// the container is kept for the TTL after the last test binary
+	writeContainerLease(t, name, os.Getpid())
+	require.False(t, expired(now.Add(time.Second)))
+	require.True(t, expired(now.Add(time.Minute)))
+
+	// the container is removed when the go command exits
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	keeper.Parent = cmd.Process.Pid
+	require.True(t, expired(now.Add(time.Second)))
+}
@@ -0,0 +1,35 @@
+//go:build !unix
+
+package testdock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// sharedContainerSupported reports whether WithSharedContainer is supported on the platform.
+const sharedContainerSupported = false
+
+// errSharedContainerUnsupported is returned by the file lock functions on platforms without WithSharedContainer.
+var errSharedContainerUnsupported = errors.New("container sharing is not supported on this platform")
+
+// lockFile is not supported on the platform.
+func lockFile(*os.File) error {
+	return errSharedContainerUnsupported
+}
+
+// unlockFile is not supported on the platform.
+func unlockFile(*os.File) error {
+	return errSharedContainerUnsupported
+}
+
+// processExists is not supported on the platform.
+func processExists(int) bool {
+	return false
+}
+
+// detachedProcAttr is not supported on the platform.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package testdock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// sharedContainerSupported reports whether WithSharedContainer is supported on the platform.
+const sharedContainerSupported = true
+
+// lockFile takes an exclusive lock of the file and waits until it is free.
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+// unlockFile releases the lock of lockFile.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// processExists reports whether the process with the pid is running.
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// detachedProcAttr starts the process in a new session, so it is not stopped with the process group of go test.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true} //nolint:exhaustruct // only the session is set.
+}
@@ -0,0 +1,127 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// we ensure the tests with the same server and migrations are attached to one shared test database.
+//
+//nolint:gochecknoglobals // used to share test databases across tests.
+var (
+	globalSharedMu        sync.Mutex
+	globalSharedDatabases = make(map[string]*sharedDB)
+)
+
+// sharedDB is a test database of WithSharedDatabase used by several tests.
+type sharedDB struct {
+	mu    sync.Mutex
+	name  string // name of the test database
+	count int    // number of tests attached to the database, zero if the database is removed
+	kept  bool   // a failed test keeps the database with WithKeepDatabaseOnFailure
+}
+
+// WithSharedDatabase attaches the tests with the same server, migrations and init queries to one test database.
+// The default is a database for each test.
+func WithSharedDatabase() Option {
+	return func(o *testDB) {
+		o.sharedDatabase = true
+	}
+}
+
+// prepareSharedDatabaseOptions validates WithSharedDatabase.
+func (d *testDB) prepareSharedDatabaseOptions() error {
+	if !d.sharedDatabase {
+		return nil
+	}
+
+	switch {
+	case !isSQLDriver(d.driver) || d.driver == oracleDriverName:
+		return fmt.Errorf("WithSharedDatabase is not supported for driver %s", d.driver)
+	case d.noTestDatabase:
+		return errors.New("WithSharedDatabase can not be used without a test database")
+	case d.databasePoolSize > 0:
+		return errors.New("WithSharedDatabase can not be used with WithDatabasePoolSize")
+	}
+
+	return nil
+}
+
+// attachSharedDatabase attaches the test to the shared test database and reports false
+// if the database does not exist, so the test prepares it and registers it with registerSharedDatabase.
+// The shared database stays locked until the returned unlock, so other tests wait for the preparation.
+// The caller defers unlock, so the database is unlocked even if the test fails during the preparation.
+func (d *testDB) attachSharedDatabase(ctx context.Context) (attached bool, unlock func(), err error) {
+	unlock = func() {}
+	if !d.sharedDatabase {
+		return false, unlock, nil
+	}
+	if err = d.extractMigrationsFS(); err != nil {
+		return false, unlock, err
+	}
+
+	key, err := d.migratedDatabaseKey()
+	if err != nil {
+		return false, unlock, err
+	}
+
+	globalSharedMu.Lock()
+	shared, ok := globalSharedDatabases[key]
+	if !ok {
+		shared = &sharedDB{}
+		globalSharedDatabases[key] = shared
+	}
+	globalSharedMu.Unlock()
+
+	d.shared = shared
+
+	shared.mu.Lock()
+	if shared.count == 0 {
+		return false, sync.OnceFunc(shared.mu.Unlock), nil
+	}
+	defer shared.mu.Unlock()
+
+	shared.count++
+	d.databaseName = shared.name
+	d.logger.Info(ctx, "using shared test database", "dsn", d.dsnNoPass, "database", d.databaseName,
+		"tests", shared.count)
+
+	return true, unlock, nil
+}
+
+// registerSharedDatabase makes the test database prepared by the test available to other tests.
+// It is called while the shared database is locked by attachSharedDatabase. If the preparation failed,
+// the next test prepares the database again.
+func (d *testDB) registerSharedDatabase(prepared bool) {
+	if d.shared == nil || !prepared {
+		return
+	}
+
+	d.shared.name = d.databaseName
+	d.shared.count = 1
+	d.shared.kept = false
+}
+
+// detachSharedDatabase detaches the test from the shared test database and reports true
+// if the test was the last one and no attached test kept the database, so the database must be removed.
+// Tests without a shared database remove their database unless it is kept.
+func (d *testDB) detachSharedDatabase(keep bool) bool {
+	if d.shared == nil {
+		return !keep
+	}
+
+	d.shared.mu.Lock()
+	defer d.shared.mu.Unlock()
+
+	// a test which failed to prepare the database did not register it
+	if d.shared.count == 0 || d.shared.name != d.databaseName {
+		return !keep
+	}
+
+	d.shared.count--
+	d.shared.kept = d.shared.kept || keep
+
+	return d.shared.count == 0 && !d.shared.kept
+}
@@ -0,0 +1,125 @@
+package testdock
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxSharedDatabase(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5560", 1)
+	options := []Option{
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithSharedDatabase(),
+		WithDockerImage(testPostgresImage),
+	}
+
+	pool, first := GetPgxPool(t, dsn, options...)
+	_, err := pool.Exec(t.Context(), "INSERT INTO test_table (name) VALUES ('shared')")
+	require.NoError(t, err)
+
+	pool, second := GetPgxPool(t, dsn, options...)
+	require.Equal(t, first.DatabaseName(), second.DatabaseName())
+
+	var count int
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM test_table").Scan(&count))
+	require.Equal(t, 2, count)
+}
+
+// TestSharedDatabaseCount verifies that the shared database is removed after the last attached test.
+func TestSharedDatabaseCount(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{"sql/0001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE shared (id INT);")}}
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		db.dsn = strings.Replace(DefaultPostgresDSN, "5432", "5601", 1)
+		require.NoError(t, db.prepareOptions(db.driver, []Option{
+			WithMode(RunModeExternal), WithMigrationsFS(files, "sql", GooseMigrateFactoryPGX), WithSharedDatabase(),
+		}))
+		return db
+	}
+
+	first := newDB()
+	attached, unlock, err := first.attachSharedDatabase(t.Context())
+	require.NoError(t, err)
+	require.False(t, attached)
+
+	// the second test waits until the first one prepares the database
+	second := newDB()
+	done := make(chan bool)
+	go func() {
+		secondAttached, secondUnlock, _ := second.attachSharedDatabase(t.Context())
+		secondUnlock()
+		done <- secondAttached
+	}()
+	select {
+	case <-done:
+		require.Fail(t, "attached before the database is prepared")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	first.databaseName = "t_shared"
+	first.registerSharedDatabase(true)
+	unlock()
+	require.True(t, <-done)
+	require.Equal(t, "t_shared", second.databaseName)
+
+	require.False(t, first.detachSharedDatabase(false))
+	require.True(t, second.detachSharedDatabase(false))
+
+	third := newDB()
+	attached, unlock, err = third.attachSharedDatabase(t.Context())
+	require.NoError(t, err)
+	require.False(t, attached)
+
+	// a test which fails with FailNow during the preparation does not block other tests
+	fourth := newDB()
+	done = make(chan bool)
+	go func() {
+		fourthAttached, fourthUnlock, _ := fourth.attachSharedDatabase(t.Context())
+		fourthUnlock()
+		done <- fourthAttached
+	}()
+	go func() {
+		defer unlock()
+		runtime.Goexit()
+	}()
+	require.False(t, <-done)
+
+	err = newExternalTestDB(t).prepareOptions("pgx", []Option{WithSharedDatabase(), WithDatabasePoolSize(2)})
+	require.ErrorContains(t, err, "can not be used with WithDatabasePoolSize")
+}
+
+// TestDetachKeptSharedDatabase verifies that a kept shared database is not removed after the last attached test.
+func TestDetachKeptSharedDatabase(t *testing.T) {
+	t.Parallel()
+
+	shared := &sharedDB{}
+	newDB := func() *testDB {
+		db := newTestDB(t, "pgx", DefaultPostgresDSN)
+		db.databaseName = "t_kept"
+		db.shared = shared
+		return db
+	}
+
+	first := newDB()
+	first.registerSharedDatabase(true)
+	second := newDB()
+	shared.count++
+
+	require.False(t, first.detachSharedDatabase(true))
+	require.False(t, second.detachSharedDatabase(false))
+	require.Zero(t, shared.count)
+
+	// the next test which prepares the database resets the kept flag
+	third := newDB()
+	third.registerSharedDatabase(true)
+	require.True(t, third.detachSharedDatabase(false))
+}
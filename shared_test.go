@@ -0,0 +1,38 @@
+package testdock
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSharedReleasesAfterParallelSubtests verifies that teardown waits for all parallel subtests.
+func TestSharedReleasesAfterParallelSubtests(t *testing.T) {
+	t.Parallel()
+
+	var (
+		closed   atomic.Bool
+		acquired atomic.Int32
+	)
+
+	t.Run("group", func(t *testing.T) {
+		shared := NewShared(t, func(tb testing.TB) (*atomic.Bool, Informer) {
+			tb.Cleanup(func() { closed.Store(true) })
+			return &closed, nil
+		})
+
+		for range 3 {
+			t.Run("subtest", func(t *testing.T) {
+				t.Parallel()
+
+				value, _ := shared.Acquire(t)
+				require.False(t, value.Load())
+				acquired.Add(1)
+			})
+		}
+	})
+
+	require.Equal(t, int32(3), acquired.Load())
+	require.True(t, closed.Load())
+}
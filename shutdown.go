@@ -0,0 +1,206 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/testdock/v2/internal/backend"
+)
+
+// defaultShutdownConcurrency is the number of resources removed by ShutdownAll at the same time.
+const defaultShutdownConcurrency = 4
+
+// ShutdownOption configures ShutdownAll.
+type ShutdownOption func(*shutdownOptions)
+
+// shutdownOptions holds ShutdownAll settings.
+type shutdownOptions struct {
+	concurrency int           // number of resources removed at the same time
+	deadline    time.Duration // time limit for removing all resources, 0 means only ctx limits it
+}
+
+// WithShutdownConcurrency sets the number of resources removed at the same time. Default is 4.
+func WithShutdownConcurrency(n int) ShutdownOption {
+	return func(o *shutdownOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithShutdownDeadline limits the time of ShutdownAll, for example to finish before the go test timeout.
+// Resources not removed before the deadline are reported in the returned error.
+func WithShutdownDeadline(deadline time.Duration) ShutdownOption {
+	return func(o *shutdownOptions) {
+		o.deadline = deadline
+	}
+}
+
+// ShutdownAll removes all Docker containers and Kubernetes pods and stops all embedded servers created by the package.
+// Test databases left in the pools of WithDatabasePoolSize are dropped.
+// It is intended for custom harnesses that manage the lifecycle outside tb.Cleanup,
+// for example TestMain with signal handling.
+// Databases returned to running tests become unavailable; cleanup functions registered by testdock
+// skip resources that have already been removed.
+// Resources are removed concurrently on a best-effort basis: a failure does not stop removal of other
+// resources, and the returned error lists every resource which could not be removed before ctx is done
+// or the deadline expires.
+func ShutdownAll(ctx context.Context, opt ...ShutdownOption) error {
+	opts := shutdownOptions{
+		concurrency: defaultShutdownConcurrency,
+		deadline:    0,
+	}
+	for _, o := range opt {
+		o(&opts)
+	}
+	if opts.concurrency <= 0 {
+		return errors.New("shutdown concurrency must be greater than 0")
+	}
+	if opts.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.deadline)
+		defer cancel()
+	}
+
+	var tasks []shutdownTask
+
+	globalDockerMu.Lock()
+	pool := globalDockerPool
+	dockerResources := globalDockerResources
+	globalDockerResources = make(map[string]*dockerResourceInfo)
+	globalDockerPool = nil
+	globalDockerMu.Unlock()
+
+	// the keys contain the password, so errors name the resources by the hashes of the keys
+	for key, info := range dockerResources {
+		label := hashResourceKey(key)
+		tasks = append(tasks, shutdownTask{
+			name: "docker resource " + label,
+			run: func() error {
+				info.mu.Lock()
+				defer info.mu.Unlock()
+
+				if info.resource != nil && pool != nil {
+					purge, unlockLease, err := info.releaseContainerLease()
+					defer unlockLease()
+					if err != nil {
+						return fmt.Errorf("release container lease %s: %w", label, err)
+					}
+					if purge {
+						if err = pool.Purge(info.resource); err != nil {
+							return fmt.Errorf("purge docker resource %s: %w", label, err)
+						}
+					}
+					// the lease is released, the container of another test binary is left to it
+					info.resource = nil
+				}
+				if info.topology != nil && pool != nil {
+					if err := info.topology.purge(pool); err != nil {
+						return fmt.Errorf("purge docker topology %s: %w", label, err)
+					}
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, server := range backend.Servers() {
+		for _, task := range server.Shutdown(ctx) {
+			name := server.Resource + " " + hashResourceKey(task.Key)
+			tasks = append(tasks, shutdownTask{
+				name: name,
+				run: func() error {
+					if err := task.Run(); err != nil {
+						return fmt.Errorf("%s: %w", name, err)
+					}
+					return nil
+				},
+			})
+		}
+	}
+
+	globalDatabasePoolMu.Lock()
+	databasePools := globalDatabasePools
+	globalDatabasePools = make(map[string]*databasePool)
+	for _, pool := range databasePools {
+		pool.closed = true
+	}
+	globalDatabasePoolMu.Unlock()
+
+	for _, pool := range databasePools {
+		tasks = append(tasks, shutdownTask{
+			name: "database pool " + pool.logDsn,
+			run: func() error {
+				return pool.remove(ctx)
+			},
+		})
+	}
+
+	return runShutdownTasks(ctx, opts.concurrency, tasks)
+}
+
+// shutdownTask removes one resource.
+type shutdownTask struct {
+	name string
+	run  func() error
+}
+
+// runShutdownTasks runs the tasks with bounded concurrency until all of them finish or ctx is done.
+// Tasks still running when ctx is done are left in the background and reported as not finished.
+func runShutdownTasks(ctx context.Context, concurrency int, tasks []shutdownTask) error {
+	var (
+		mu       sync.Mutex
+		errs     []error
+		finished = make([]bool, len(tasks))
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for i, task := range tasks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := task.run()
+
+			mu.Lock()
+			defer mu.Unlock()
+			finished[i] = true
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, task := range tasks {
+		if !finished[i] {
+			errs = append(errs, fmt.Errorf("%s is not removed: %w", task.name, ctx.Err()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package testdock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunShutdownTasksConcurrency verifies that all tasks run with bounded concurrency and errors are collected.
+func TestRunShutdownTasksConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning atomic.Int32
+	task := func(err error) func() error {
+		return func() error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return err
+		}
+	}
+
+	tasks := []shutdownTask{
+		{name: "a", run: task(nil)},
+		{name: "b", run: task(errors.New("purge b"))},
+		{name: "c", run: task(nil)},
+		{name: "d", run: task(nil)},
+		{name: "e", run: task(nil)},
+	}
+
+	err := runShutdownTasks(t.Context(), 2, tasks)
+	require.EqualError(t, err, "purge b")
+	require.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+// TestRunShutdownTasksDeadline verifies that tasks not finished before the deadline are reported.
+func TestRunShutdownTasksDeadline(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	err := runShutdownTasks(ctx, 1, []shutdownTask{
+		{name: "fast", run: func() error { return nil }},
+		{name: "stuck", run: func() error { <-release; return nil }},
+		{name: "waiting", run: func() error { return nil }},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "stuck is not removed")
+	require.ErrorContains(t, err, "waiting is not removed")
+	require.NotContains(t, err.Error(), "fast")
+}
+
+// TestShutdownAllRejectsInvalidConcurrency verifies validation of the options.
+func TestShutdownAllRejectsInvalidConcurrency(t *testing.T) {
+	t.Parallel()
+
+	err := ShutdownAll(t.Context(), WithShutdownConcurrency(0), WithShutdownDeadline(time.Second))
+	require.ErrorContains(t, err, "concurrency must be greater than 0")
+}
@@ -0,0 +1,239 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// databaseSnapshot is a snapshot of the test database taken by Snapshot.
+type databaseSnapshot struct {
+	dump     []byte // plain SQL dump of the PostgreSQL test database
+	database string // MySQL database with the copies of the tables
+}
+
+// Snapshot saves the state of the test database under the name, a snapshot with the same name is replaced.
+func (d *testDB) Snapshot(ctx context.Context, name string) error {
+	if d.driver != "mysql" && !isPostgresDriver(d.driver) {
+		return fmt.Errorf("snapshot is not supported for driver %s", d.driver)
+	}
+	if d.schemaName != "" {
+		return errors.New("snapshot is not supported with WithSchemaIsolation")
+	}
+
+	var (
+		snapshot databaseSnapshot
+		err      error
+	)
+	if d.driver == "mysql" {
+		snapshot, err = d.snapshotMySQL(ctx, name)
+	} else {
+		snapshot.dump, err = d.pgDump(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot %s: %w", name, err)
+	}
+
+	if d.snapshots == nil {
+		d.snapshots = make(map[string]databaseSnapshot)
+	}
+	d.snapshots[name] = snapshot
+
+	d.logger.Info(ctx, "test database snapshot taken", "dsn", d.dsnNoPass, "snapshot", name)
+
+	return nil
+}
+
+// Restore returns the test database to the state saved by Snapshot.
+func (d *testDB) Restore(ctx context.Context, name string) error {
+	snapshot, ok := d.snapshots[name]
+	if !ok {
+		return fmt.Errorf("snapshot %s is not found", name)
+	}
+
+	db, err := d.connectSQLDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	if d.driver == "mysql" {
+		err = d.restoreMySQL(ctx, db, snapshot.database)
+	} else {
+		err = restorePostgres(ctx, db, snapshot.dump)
+	}
+	if err != nil {
+		return fmt.Errorf("restore %s: %w", name, err)
+	}
+
+	d.logger.Info(ctx, "test database restored", "dsn", d.dsnNoPass, "snapshot", name)
+
+	return nil
+}
+
+// restorePostgres drops all user schemas and executes the dump in one implicit transaction,
+// so objects created after the snapshot are removed too.
+func restorePostgres(ctx context.Context, db *sql.DB, dump []byte) error {
+	rows, err := db.QueryContext(ctx, `SELECT nspname FROM pg_namespace
+		WHERE nspname <> 'information_schema' AND nspname NOT LIKE 'pg\_%'`)
+	if err != nil {
+		return fmt.Errorf("list schemas: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err reports the iteration errors.
+
+	var query strings.Builder
+	for rows.Next() {
+		var schema string
+		if err = rows.Scan(&schema); err != nil {
+			return fmt.Errorf("scan schema: %w", err)
+		}
+		_, _ = fmt.Fprintf(&query, "DROP SCHEMA %s CASCADE;\n", pgx.Identifier{schema}.Sanitize())
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("list schemas: %w", err)
+	}
+
+	// the dump does not create the public schema, which exists in new databases
+	_, _ = query.WriteString("CREATE SCHEMA public;\n")
+	_, _ = query.WriteString(stripPsqlMetaCommands(string(dump)))
+
+	_, err = db.ExecContext(ctx, query.String())
+
+	return err
+}
+
+// snapshotMySQL copies the tables of the test database into a snapshot database on the same server.
+// The snapshot databases are removed after the test.
+func (d *testDB) snapshotMySQL(ctx context.Context, name string) (databaseSnapshot, error) {
+	snapshot, ok := d.snapshots[name]
+	if !ok {
+		snapshot.database = newDatabaseName()
+	}
+
+	db, err := d.connectSQLDB(ctx, true)
+	if err != nil {
+		return snapshot, err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return snapshot, fmt.Errorf("connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // the connection is returned to the closed pool.
+
+	target := quoteMySQLIdentifier(snapshot.database)
+	if _, err = conn.ExecContext(ctx, "DROP DATABASE IF EXISTS "+target); err != nil {
+		return snapshot, fmt.Errorf("drop snapshot database: %w", err)
+	}
+	if d.snapshots == nil {
+		d.snapshots = make(map[string]databaseSnapshot)
+		d.t.Cleanup(func() {
+			if dropErr := d.dropMySQLSnapshots(context.Background()); dropErr != nil {
+				d.logger.Info(context.Background(), "failed to drop snapshots", "dsn", d.dsnNoPass, "error", dropErr)
+			}
+		})
+	}
+	if _, err = conn.ExecContext(ctx, "CREATE DATABASE "+target); err != nil {
+		return snapshot, fmt.Errorf("create snapshot database: %w", err)
+	}
+
+	if err = copyMySQLTables(ctx, conn, d.databaseName, snapshot.database); err != nil {
+		// a replaced snapshot is lost, so it is removed from the snapshots together with its database
+		delete(d.snapshots, name)
+		if _, dropErr := conn.ExecContext(ctx, "DROP DATABASE IF EXISTS "+target); dropErr != nil {
+			d.logger.Info(ctx, "failed to drop snapshot database", "dsn", d.dsnNoPass, "error", dropErr)
+		}
+		return snapshot, err
+	}
+
+	return snapshot, nil
+}
+
+// copyMySQLTables copies the tables and rows of the source database into the target database.
+func copyMySQLTables(ctx context.Context, conn *sql.Conn, source, target string) error {
+	tables, err := listMySQLTables(ctx, conn, source)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		from := quoteMySQLIdentifier(source) + "." + quoteMySQLIdentifier(table)
+		copied := quoteMySQLIdentifier(target) + "." + quoteMySQLIdentifier(table)
+		if _, err = conn.ExecContext(ctx, "CREATE TABLE "+copied+" LIKE "+from); err != nil {
+			return fmt.Errorf("copy table %s: %w", table, err)
+		}
+		if _, err = conn.ExecContext(ctx, "INSERT INTO "+copied+" SELECT * FROM "+from); err != nil {
+			return fmt.Errorf("copy rows of %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreMySQL replaces the rows of the tables of the test database with the rows of the snapshot database.
+// The schema is not restored, tables created after the snapshot are emptied.
+func (d *testDB) restoreMySQL(ctx context.Context, db *sql.DB, database string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // the session variable is dropped with the connection.
+
+	tables, err := listMySQLTables(ctx, conn, d.databaseName)
+	if err != nil {
+		return err
+	}
+	saved, err := listMySQLTables(ctx, conn, database)
+	if err != nil {
+		return err
+	}
+	for _, table := range saved {
+		if !slices.Contains(tables, table) {
+			return fmt.Errorf("table %s was removed after the snapshot", table)
+		}
+	}
+
+	if _, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("disable foreign key checks: %w", err)
+	}
+	for _, table := range tables {
+		if _, err = conn.ExecContext(ctx, "TRUNCATE TABLE "+quoteMySQLIdentifier(table)); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		if !slices.Contains(saved, table) {
+			continue
+		}
+		if _, err = conn.ExecContext(ctx, "INSERT INTO "+quoteMySQLIdentifier(table)+" SELECT * FROM "+
+			quoteMySQLIdentifier(database)+"."+quoteMySQLIdentifier(table)); err != nil {
+			return fmt.Errorf("restore rows of %s: %w", table, err)
+		}
+	}
+	if _, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+		return fmt.Errorf("enable foreign key checks: %w", err)
+	}
+
+	return nil
+}
+
+// dropMySQLSnapshots removes the snapshot databases of the test.
+func (d *testDB) dropMySQLSnapshots(ctx context.Context) error {
+	db, err := d.connectSQLDB(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	for _, snapshot := range d.snapshots {
+		if _, err = db.ExecContext(ctx, "DROP DATABASE IF EXISTS "+quoteMySQLIdentifier(snapshot.database)); err != nil {
+			return fmt.Errorf("drop snapshot database %s: %w", snapshot.database, err)
+		}
+	}
+
+	return nil
+}
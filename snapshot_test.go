@@ -0,0 +1,76 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxSnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5558", 1)
+	pool, info := GetPgxPool(t, dsn,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDockerImage(testPostgresImage),
+	)
+
+	dbInfo, ok := info.(DatabaseInformer)
+	require.True(t, ok)
+	require.NoError(t, dbInfo.Snapshot(t.Context(), "migrated"))
+
+	_, err := pool.Exec(t.Context(), "INSERT INTO test_table (name) VALUES ('changed')")
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), "CREATE TABLE created_later (id INT)")
+	require.NoError(t, err)
+
+	require.NoError(t, dbInfo.Restore(t.Context(), "migrated"))
+
+	var names []string
+	rows, err := pool.Query(t.Context(), "SELECT name FROM test_table")
+	require.NoError(t, err)
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"test"}, names)
+
+	var exists bool
+	require.NoError(t, pool.QueryRow(t.Context(),
+		"SELECT to_regclass('public.created_later') IS NOT NULL").Scan(&exists))
+	require.False(t, exists)
+}
+
+func Test_MySQLSnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultMySQLDSN, "3306", "3316", 1)
+	db, info := GetMySQLConn(t, dsn, WithMigrations("migrations/pg/goose", GooseMigrateFactoryMySQL))
+
+	dbInfo, ok := info.(DatabaseInformer)
+	require.True(t, ok)
+	require.NoError(t, dbInfo.Snapshot(t.Context(), "migrated"))
+
+	_, err := db.ExecContext(t.Context(), "INSERT INTO test_table (name) VALUES ('changed')")
+	require.NoError(t, err)
+
+	require.NoError(t, dbInfo.Restore(t.Context(), "migrated"))
+	testSQLHelper(t, db)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM test_table").Scan(&count))
+	require.Equal(t, 1, count)
+}
+
+// TestSnapshotUnsupported verifies the errors of Snapshot and Restore without a database.
+func TestSnapshotUnsupported(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.driver = mongoDriverName
+	require.ErrorContains(t, db.Snapshot(t.Context(), "a"), "snapshot is not supported for driver mongodb")
+	require.ErrorContains(t, db.Restore(t.Context(), "a"), "snapshot a is not found")
+}
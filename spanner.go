@@ -0,0 +1,355 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/ctxlog"
+)
+
+const (
+	// spanner driver name for separating sql and spanner.
+	spannerDriverName = "spanner"
+	// spannerGRPCPort is the gRPC port of the Spanner emulator.
+	spannerGRPCPort = 9010
+	// spannerDatabaseNameLength is the maximum length of a Spanner database name.
+	spannerDatabaseNameLength = 30
+)
+
+// SpannerInformer extends Informer with the Spanner emulator connection details.
+type SpannerInformer interface {
+	Informer
+	// EmulatorHost returns the gRPC host:port of the emulator, the value for SPANNER_EMULATOR_HOST
+	// or for option.WithEndpoint together with option.WithoutAuthentication and insecure credentials.
+	EmulatorHost() string
+	// DatabasePath returns projects/<project>/instances/<instance>/databases/<database>.
+	DatabasePath() string
+}
+
+// GetSpannerDatabase starts the Cloud Spanner emulator, creates the instance if needed
+// and a database per test, applies DDL migrations, and returns the connection details.
+// The DSN format is http://host:rest_port/project/instance, where rest_port is the emulator REST port (9020).
+// Use SpannerMigrateFactory for DDL migrations and GetSpannerClient of the github.com/n-r-w/testdock/v2/gcp
+// module for a client of the database.
+// Docker image: gcr.io/cloud-spanner-emulator/emulator.
+func GetSpannerDatabase(tb testing.TB, dsn string, opt ...Option) SpannerInformer {
+	tb.Helper()
+
+	return startSpannerDatabase(tb, dsn, opt)
+}
+
+// startSpannerDatabase starts the emulator and creates the test database.
+func startSpannerDatabase(tb testing.TB, dsn string, opt []Option) *spannerInfo {
+	tb.Helper()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("gcr.io/cloud-spanner-emulator/emulator"),
+		WithDockerImage("1.5.41"),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults(spannerDriverName, opt)...)
+
+	tDB := newTDB(context.Background(), tb, spannerDriverName, dsn, optPrepared)
+
+	return &spannerInfo{
+		testDB:   tDB,
+		grpcPort: tDB.publishedPort(spannerGRPCPort),
+	}
+}
+
+// spannerInfo implements SpannerInformer.
+type spannerInfo struct {
+	*testDB
+
+	grpcPort int
+}
+
+// EmulatorHost returns the gRPC host:port of the emulator.
+func (s *spannerInfo) EmulatorHost() string {
+	return net.JoinHostPort(s.url.Host, strconv.Itoa(s.grpcPort))
+}
+
+// DatabasePath returns projects/<project>/instances/<instance>/databases/<database>.
+func (s *spannerInfo) DatabasePath() string {
+	return newSpannerClient(s.url, s.connectDatabase).databasePath(s.databaseName)
+}
+
+// newSpannerDatabaseName returns a unique name which satisfies Spanner database name rules.
+func newSpannerDatabaseName() string {
+	name := "t_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	return name[:spannerDatabaseNameLength]
+}
+
+// spannerTestURL returns the test DSN with the project, the instance and the test database.
+func (d *testDB) spannerTestURL() *dbURL {
+	return d.url.replaceDatabase(d.connectDatabase + "/" + d.databaseName)
+}
+
+// createSpannerDatabase creates the instance if it does not exist and the test database.
+func (d *testDB) createSpannerDatabase(ctx context.Context) error {
+	d.logger.Info(ctx, "creating new test spanner database", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	client := newSpannerClient(d.url, d.connectDatabase)
+	defer client.httpClient.CloseIdleConnections()
+
+	if err := d.retryConnect(ctx, d.dsnNoPass, func() error {
+		return client.createInstance(ctx)
+	}); err != nil {
+		return fmt.Errorf("create spanner instance: %w", err)
+	}
+
+	if err := client.createDatabase(ctx, d.databaseName); err != nil {
+		return fmt.Errorf("create spanner database: %w", err)
+	}
+
+	d.logger.Info(ctx, "new test spanner database created", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	return nil
+}
+
+// dropSpannerDatabase removes the test database.
+func (d *testDB) dropSpannerDatabase(ctx context.Context) error {
+	d.logger.Info(ctx, "deleting test database", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	client := newSpannerClient(d.url, d.connectDatabase)
+	defer client.httpClient.CloseIdleConnections()
+
+	if _, err := client.do(ctx, http.MethodDelete, "/v1/"+client.databasePath(d.databaseName), nil); err != nil {
+		return fmt.Errorf("drop spanner database: %w", err)
+	}
+
+	d.logger.Info(ctx, "test database deleted", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	return nil
+}
+
+// SpannerMigrateFactory creates a migrator for Spanner DDL scripts.
+// The migrations directory must contain *.sql files with a numeric prefix before "_",
+// for example 0001_init.sql. Statements are separated by ";" and applied as one DDL batch per file.
+// If a file contains goose annotations, only the "-- +goose Up" section is executed.
+func SpannerMigrateFactory(_ testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+	url, err := parseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+
+	instancePath, database, ok := cutLast(url.Database, "/")
+	if !ok || strings.Count(instancePath, "/") != 1 || database == "" {
+		return nil, errors.New("dsn must contain project, instance and database: http://host:port/project/instance/database")
+	}
+
+	migrations, err := loadScriptMigrations(migrationsDir, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	client := newSpannerClient(url, instancePath)
+
+	return &scriptMigrator{
+		exec: func(ctx context.Context, script string) error {
+			return client.updateDDL(ctx, database, splitSQLStatements(script))
+		},
+		begin: nil,
+		close: func() error {
+			client.httpClient.CloseIdleConnections()
+			return nil
+		},
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// splitSQLStatements splits the script into statements separated by ";" and skips comments and empty statements.
+func splitSQLStatements(script string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+
+	var statements []string
+	for statement := range strings.SplitSeq(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+// spannerClient is a minimal client for the Spanner emulator REST API.
+type spannerClient struct {
+	endpoint   string
+	project    string
+	instance   string
+	httpClient *http.Client
+}
+
+// newSpannerClient creates a client for the emulator from the url and "project/instance" path.
+func newSpannerClient(url *dbURL, instancePath string) *spannerClient {
+	project, instance, _ := strings.Cut(instancePath, "/")
+
+	return &spannerClient{
+		endpoint:   "http://" + url.address(),
+		project:    project,
+		instance:   instance,
+		httpClient: &http.Client{}, //nolint:exhaustruct // default client settings.
+	}
+}
+
+// instancePath returns projects/<project>/instances/<instance>.
+func (c *spannerClient) instancePath() string {
+	return fmt.Sprintf("projects/%s/instances/%s", c.project, c.instance)
+}
+
+// databasePath returns projects/<project>/instances/<instance>/databases/<database>.
+func (c *spannerClient) databasePath(database string) string {
+	return c.instancePath() + "/databases/" + database
+}
+
+// createInstance creates the instance, an existing instance is not an error.
+func (c *spannerClient) createInstance(ctx context.Context) error {
+	body := map[string]any{
+		"instanceId": c.instance,
+		"instance": map[string]any{
+			"config":      fmt.Sprintf("projects/%s/instanceConfigs/emulator-config", c.project),
+			"displayName": c.instance,
+			"nodeCount":   1,
+		},
+	}
+
+	operation, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/v1/projects/%s/instances", c.project), body)
+	if errors.Is(err, errSpannerAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.waitOperation(ctx, operation)
+}
+
+// createDatabase creates the database in the instance.
+func (c *spannerClient) createDatabase(ctx context.Context, database string) error {
+	body := map[string]any{"createStatement": fmt.Sprintf("CREATE DATABASE `%s`", database)}
+
+	operation, err := c.do(ctx, http.MethodPost, "/v1/"+c.instancePath()+"/databases", body)
+	if err != nil {
+		return err
+	}
+
+	return c.waitOperation(ctx, operation)
+}
+
+// updateDDL applies DDL statements to the database.
+func (c *spannerClient) updateDDL(ctx context.Context, database string, statements []string) error {
+	if len(statements) == 0 {
+		return nil
+	}
+
+	operation, err := c.do(ctx, http.MethodPatch, "/v1/"+c.databasePath(database)+"/ddl",
+		map[string]any{"statements": statements})
+	if err != nil {
+		return err
+	}
+
+	return c.waitOperation(ctx, operation)
+}
+
+// errSpannerAlreadyExists is returned when the created resource already exists.
+var errSpannerAlreadyExists = errors.New("already exists")
+
+// spannerOperation is a long-running operation of the Spanner API.
+type spannerOperation struct {
+	Name  string          `json:"name"`
+	Done  bool            `json:"done"`
+	Error json.RawMessage `json:"error"`
+}
+
+// waitOperation waits for the long-running operation to finish.
+func (c *spannerClient) waitOperation(ctx context.Context, data json.RawMessage) error {
+	const pollInterval = 100 * time.Millisecond
+
+	for {
+		var operation spannerOperation
+		if err := json.Unmarshal(data, &operation); err != nil {
+			return fmt.Errorf("decode operation: %w", err)
+		}
+		if len(operation.Error) > 0 {
+			return fmt.Errorf("operation %s: %s", operation.Name, operation.Error)
+		}
+		if operation.Done || operation.Name == "" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		var err error
+		if data, err = c.do(ctx, http.MethodGet, "/v1/"+operation.Name, nil); err != nil {
+			return err
+		}
+	}
+}
+
+// do sends the request to the emulator REST API and returns the response body.
+func (c *spannerClient) do(ctx context.Context, method, path string, body any) (json.RawMessage, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("spanner request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // body is fully read below.
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return nil, errSpannerAlreadyExists
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("spanner status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,64 @@
+package testdock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Spanner(t *testing.T) {
+	t.Parallel()
+
+	informer := GetSpannerDatabase(t,
+		DefaultSpannerDSN,
+		WithMigrations("migrations/spanner", SpannerMigrateFactory),
+		WithMode(RunModeDocker),
+	)
+
+	require.Equal(t, "projects/test-project/instances/test-instance/databases/"+informer.DatabaseName(),
+		informer.DatabasePath())
+	require.NotEmpty(t, informer.EmulatorHost())
+
+	url, err := parseURL(informer.DSN())
+	require.NoError(t, err)
+	restClient := newSpannerClient(url, "test-project/test-instance")
+	data, err := restClient.do(t.Context(), http.MethodGet, "/v1/"+informer.DatabasePath()+"/ddl", nil)
+	require.NoError(t, err)
+
+	var ddl struct {
+		Statements []string `json:"statements"`
+	}
+	require.NoError(t, json.Unmarshal(data, &ddl))
+	require.Len(t, ddl.Statements, 1)
+	require.Contains(t, ddl.Statements[0], "test_table")
+}
+
+// TestSpannerTestDSN verifies the test database name rules and the DSN accepted by SpannerMigrateFactory.
+func TestSpannerTestDSN(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.driver = spannerDriverName
+	db.dsn = DefaultSpannerDSN
+
+	require.NoError(t, db.prepareOptions(spannerDriverName, nil))
+	require.Len(t, db.databaseName, spannerDatabaseNameLength)
+	require.True(t, strings.HasSuffix(db.DSN(), "/test-project/test-instance/"+db.databaseName))
+
+	_, err := SpannerMigrateFactory(t, db.DSN(), "migrations/spanner", nil)
+	require.NoError(t, err)
+
+	_, err = SpannerMigrateFactory(t, DefaultSpannerDSN, "migrations/spanner", nil)
+	require.Error(t, err)
+}
+
+// TestSplitSQLStatements verifies that comments and empty statements are skipped.
+func TestSplitSQLStatements(t *testing.T) {
+	t.Parallel()
+
+	statements := splitSQLStatements("-- comment\nCREATE TABLE a (id INT64) PRIMARY KEY (id);\n\nCREATE INDEX i ON a (id);\n")
+	require.Equal(t, []string{"CREATE TABLE a (id INT64) PRIMARY KEY (id)", "CREATE INDEX i ON a (id)"}, statements)
+}
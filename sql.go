@@ -3,8 +3,24 @@ package testdock
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxCreateDatabaseAttempts is the number of names tried when the test database already exists.
+	maxCreateDatabaseAttempts = 3
+	// pgDuplicateDatabaseCode is the PostgreSQL SQLSTATE of duplicate_database.
+	pgDuplicateDatabaseCode = "42P04"
+	// pgDuplicateSchemaCode is the PostgreSQL SQLSTATE of duplicate_schema.
+	pgDuplicateSchemaCode = "42P06"
+	// mysqlDatabaseExistsNumber is the MySQL error ER_DB_CREATE_EXISTS.
+	mysqlDatabaseExistsNumber = 1007
 )
 
 // GetSQLConn inits a test database, applies migrations, and returns sql connection to the database.
@@ -13,6 +29,14 @@ import (
 func GetSQLConn(tb testing.TB, driver, dsn string, opt ...Option) (*sql.DB, Informer) {
 	tb.Helper()
 
+	return getSQLConn(tb, driver, dsn, withDriverDefaults(driver, opt)...)
+}
+
+// getSQLConn implements GetSQLConn without the registered defaults of the driver,
+// which are applied by the Get... function of the preset.
+func getSQLConn(tb testing.TB, driver, dsn string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
 	ctx := context.Background()
 	tDB := newTDB(ctx, tb, driver, dsn, opt)
 
@@ -35,13 +59,15 @@ func GetSQLConn(tb testing.TB, driver, dsn string, opt ...Option) (*sql.DB, Info
 // connectSQLDB connects to the database with retries using database/sql.
 // testDatabase: if true, will be connected to the temporary test database.
 func (d *testDB) connectSQLDB(ctx context.Context, testDatabase bool) (*sql.DB, error) {
-	var dbURL *dbURL
 	if testDatabase {
-		dbURL = d.url.replaceDatabase(d.databaseName)
-	} else {
-		dbURL = d.url.replaceDatabase(d.connectDatabase)
+		return d.connectSQLURL(ctx, d.testURL())
 	}
 
+	return d.connectSQLURL(ctx, d.url.replaceDatabase(d.connectDatabase))
+}
+
+// connectSQLURL connects to the database from the url with retries and waits for the readiness checks.
+func (d *testDB) connectSQLURL(ctx context.Context, dbURL *dbURL) (*sql.DB, error) {
 	d.logger.Info(ctx, "connecting to test database", "url", dbURL.string(true))
 
 	var db *sql.DB
@@ -54,6 +80,10 @@ func (d *testDB) connectSQLDB(ctx context.Context, testDatabase bool) (*sql.DB,
 			_ = db.Close()
 			return err
 		}
+		if err = d.checkReadiness(ctx, db); err != nil {
+			_ = db.Close()
+			return err
+		}
 		return nil
 	})
 	if err != nil {
@@ -72,12 +102,87 @@ func (d *testDB) createSQLDatabase(ctx context.Context) error {
 	}
 	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
 
-	_, err = db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s", d.databaseName))
+	// names can collide when machines with the same clock create databases on one server
+	for attempt := 1; ; attempt++ {
+		query := fmt.Sprintf("CREATE DATABASE %s", d.databaseName)
+		if d.templateName != "" {
+			query += " TEMPLATE " + d.templateName
+		}
+		_, err = db.ExecContext(ctx, query)
+		if err == nil {
+			break
+		}
+		if attempt >= maxCreateDatabaseAttempts || !isDatabaseExistsError(err) || d.fixedDatabaseName != "" {
+			return fmt.Errorf("create db: %w", err)
+		}
+
+		d.logger.Info(ctx, "test database already exists, retrying with a new name",
+			"dsn", d.dsnNoPass, "database", d.databaseName, "attempt", attempt)
+		d.databaseName = d.newTestDatabaseName()
+	}
+
+	d.logger.Info(ctx, "new test sql database created", "dsn", d.dsnNoPass, "database", d.databaseName,
+		"template", d.templateName)
+
+	return nil
+}
+
+// isDatabaseExistsError reports that CREATE DATABASE or CREATE SCHEMA failed because it already exists.
+func isDatabaseExistsError(err error) bool {
+	var (
+		pgErr    *pgconn.PgError
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+
+	switch {
+	case errors.As(err, &pgErr):
+		return pgErr.Code == pgDuplicateDatabaseCode || pgErr.Code == pgDuplicateSchemaCode
+	case errors.As(err, &pqErr):
+		return pqErr.Code == pgDuplicateDatabaseCode || pqErr.Code == pgDuplicateSchemaCode
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == mysqlDatabaseExistsNumber
+	default:
+		return false
+	}
+}
+
+// isSQLDriver reports that the test database of the driver is accessed with database/sql.
+func isSQLDriver(driver string) bool {
+	switch driver {
+	case mongoDriverName, surrealDriverName, spannerDriverName, firestoreDriverName, pubSubDriverName,
+		azureBlobDriverName, localStackDriverName, tarantoolDriverName, typesenseDriverName, meilisearchDriverName,
+		qdrantDriverName, weaviateDriverName, redisDriverName, clickHouseDriverName:
+		return false
+	default:
+		return true
+	}
+}
+
+// initTestDatabase executes initialization queries in the temporary test database before migrations.
+func (d *testDB) initTestDatabase(ctx context.Context) error {
+	if d.citusWorkers > 0 {
+		if err := d.initCitusDatabase(ctx); err != nil {
+			return err
+		}
+	}
+	if len(d.initQueries) == 0 {
+		return nil
+	}
+
+	d.logger.Info(ctx, "initializing test database", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	db, err := d.connectSQLDB(ctx, true)
 	if err != nil {
-		return fmt.Errorf("create db: %w", err)
+		return err
 	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
 
-	d.logger.Info(ctx, "new test sql database created", "dsn", d.dsnNoPass, "database", d.databaseName)
+	for _, query := range d.initQueries {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("init test database (%s): %w", query, err)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,53 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/n-r-w/ctxlog"
+)
+
+// GetSQLiteConn creates a SQLite database in a temporary file, applies migrations,
+// and returns sql connection to the database. Docker is not used.
+// The file is removed after the test. Use WithSQLiteInMemory to create a shared-cache in-memory database instead.
+// driver is the name registered by the SQLite driver package, for example "sqlite3" for github.com/mattn/go-sqlite3
+// or "sqlite" for modernc.org/sqlite. Do not forget to import the driver package.
+func GetSQLiteConn(tb testing.TB, driver string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	ctx := context.Background()
+	tDB := newFileTDB(ctx, tb, driver, "test.sqlite", withDriverDefaults(driver, opt))
+
+	db, err := tDB.connectFileDB(ctx)
+	if err != nil {
+		tb.Fatalf("cannot connect to sqlite: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		if closeErr := closeResourceWithTimeout(tDB.closeTimeout, db.Close, func() string {
+			return tDB.closeTimeoutDetails("sqlite sql connection", nil)
+		}); closeErr != nil {
+			tb.Errorf("%v", closeErr)
+		}
+	})
+
+	return db, tDB
+}
+
+// WithSQLiteInMemory creates a shared-cache in-memory SQLite database instead of a temporary file.
+// Informer.DSN returns the file:<name>?mode=memory&cache=shared connection string.
+func WithSQLiteInMemory() Option {
+	return func(o *testDB) {
+		o.fileInMemory = true
+	}
+}
+
+// GolangMigrateFactorySQLite creates a migrator for https://github.com/golang-migrate/migrate with SQLite databases.
+// scheme is the name of golang-migrate database driver: "sqlite3" (import github.com/golang-migrate/migrate/v4/database/sqlite3)
+// or "sqlite" (import github.com/golang-migrate/migrate/v4/database/sqlite).
+func GolangMigrateFactorySQLite(scheme string) MigrateFactory {
+	return describeMigrateFactory(func(t testing.TB, dsn, migrationsDir string, logger ctxlog.ILogger) (Migrator, error) {
+		return GolangMigrateFactory(t, scheme+"://"+dsn, migrationsDir, logger)
+	}, "golang-migrate %s", scheme)
+}
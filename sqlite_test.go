@@ -0,0 +1,21 @@
+package testdock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithSQLiteInMemory verifies that the option selects the in-memory database.
+func TestWithSQLiteInMemory(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t, "sqlite3", "")
+	require.NoError(t, db.prepareFileOptions([]Option{WithSQLiteInMemory()}))
+	require.True(t, db.fileInMemory)
+
+	c := Capabilities("sqlite3")
+	require.Equal(t, IsolationDatabase, c.Isolation)
+	require.True(t, c.GooseMigrations)
+	require.False(t, c.DockerPreset)
+}
@@ -0,0 +1,51 @@
+package testdock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SurrealDB(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetSurrealDBClient(t,
+		DefaultSurrealDBDSN,
+		WithMigrations("migrations/surrealdb", SurrealMigrateFactory),
+		WithMode(RunModeDocker),
+	)
+
+	require.Equal(t, informer.DatabaseName(), client.Database())
+
+	results, err := client.Query(t.Context(), "SELECT name FROM test_table;")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	var rows []struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, json.Unmarshal(results[0].Result, &rows))
+	require.Len(t, rows, 1)
+	require.Equal(t, "test", rows[0].Name)
+}
+
+// TestSurrealDBDSNContainsNamespaceAndDatabase verifies that the test DSN is accepted by SurrealMigrateFactory.
+func TestSurrealDBDSNContainsNamespaceAndDatabase(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	db.driver = surrealDriverName
+	db.dsn = DefaultSurrealDBDSN
+
+	require.NoError(t, db.prepareOptions(surrealDriverName, nil))
+
+	client, err := parseSurrealDBDSN(db.DSN())
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:8000", client.Endpoint())
+	require.Equal(t, "test", client.Namespace())
+	require.Equal(t, db.databaseName, client.Database())
+
+	_, err = parseSurrealDBDSN(DefaultSurrealDBDSN)
+	require.Error(t, err)
+}
@@ -0,0 +1,4 @@
+local prefix = ...
+local space = box.schema.space.create(prefix .. '_test_table', {if_not_exists = true})
+space:format({{name = 'id', type = 'unsigned'}, {name = 'name', type = 'string'}})
+space:create_index('primary', {parts = {'id'}, if_not_exists = true})
@@ -0,0 +1,152 @@
+package tarantool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/n-r-w/testdock/v2/internal/backend/backendtest"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func Test_Tarantool(t *testing.T) {
+	t.Parallel()
+
+	conn, informer := GetConn(t,
+		testdock.DefaultTarantoolDSN,
+		testdock.WithMigrations("migrations", MigrateFactory),
+		testdock.WithMode(testdock.RunModeDocker),
+	)
+
+	space := informer.DatabaseName() + "_test_table"
+	_, err := conn.Do(tarantool.NewInsertRequest(space).Tuple([]any{1, "a"})).Get()
+	require.NoError(t, err)
+
+	count, err := conn.Do(tarantool.NewEvalRequest("return box.space[...]:count()").Args([]any{space})).Get()
+	require.NoError(t, err)
+	require.Len(t, count, 1)
+	require.EqualValues(t, 1, count[0])
+}
+
+// fakeTarantoolRequest is a request received by the fake Tarantool server.
+type fakeTarantoolRequest struct {
+	requestType uint64
+	body        map[int]any
+}
+
+// TestDropSpaces verifies authentication and the evaluation of the cleanup script with the test prefix.
+func TestDropSpaces(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	requests := make(chan fakeTarantoolRequest, 16) //nolint:mnd // more than the requests of the test.
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go serveFakeTarantool(conn, requests)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr) //nolint:forcetypeassert // tcp listener.
+	db := backendtest.New(t, backend.URL{
+		User: "admin", Password: "secret", Host: addr.IP.String(), Port: addr.Port, Database: "",
+	}, "test_prefix")
+
+	require.NoError(t, dropSpaces(t.Context(), db))
+
+	var evals []fakeTarantoolRequest
+	for len(evals) < 2 {
+		request := <-requests
+		switch request.requestType {
+		case fakeTarantoolAuth:
+			require.Equal(t, "admin", request.body[fakeTarantoolUserName])
+		case fakeTarantoolEval:
+			evals = append(evals, request)
+		}
+	}
+	require.Equal(t, "return box.info.status", evals[0].body[fakeTarantoolExpr])
+	require.Contains(t, evals[1].body[fakeTarantoolExpr], "box.space[name]:drop()")
+	require.Equal(t, []any{db.DatabaseName()}, evals[1].body[fakeTarantoolTuple])
+}
+
+// iproto keys and request types used by the fake Tarantool server.
+const (
+	fakeTarantoolAuth         = 0x07
+	fakeTarantoolEval         = 0x08
+	fakeTarantoolID           = 0x49
+	fakeTarantoolTuple        = 0x21
+	fakeTarantoolUserName     = 0x23
+	fakeTarantoolExpr         = 0x27
+	fakeTarantoolData         = 0x30
+	fakeTarantoolErrorMessage = 0x31
+	fakeTarantoolTypeError    = 0x8000
+	fakeTarantoolUnknownType  = 48
+)
+
+// serveFakeTarantool sends the greeting, answers IPROTO_ID as an old server, accepts any authentication
+// and answers evaluations with the running status.
+func serveFakeTarantool(conn net.Conn, requests chan<- fakeTarantoolRequest) {
+	defer conn.Close() //nolint:errcheck // test server.
+
+	salt := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)) //nolint:mnd // salt length.
+	if _, err := fmt.Fprintf(conn, "%-63s\n%-63s\n", "Tarantool 2.11.0 (Binary)", salt); err != nil {
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		var frame [5]byte
+		if _, err := io.ReadFull(r, frame[:]); err != nil {
+			return
+		}
+		packet := make([]byte, binary.BigEndian.Uint32(frame[1:]))
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return
+		}
+
+		dec := msgpack.NewDecoder(bytes.NewReader(packet))
+		var (
+			header map[int]uint64
+			body   map[int]any
+		)
+		if err := dec.Decode(&header); err != nil {
+			return
+		}
+		_ = dec.Decode(&body)
+		requests <- fakeTarantoolRequest{requestType: header[0], body: body}
+
+		code, response := uint64(0), map[int]any{}
+		switch header[0] {
+		case fakeTarantoolID:
+			code = fakeTarantoolTypeError | fakeTarantoolUnknownType
+			response[fakeTarantoolErrorMessage] = "unknown request type"
+		case fakeTarantoolEval:
+			response[fakeTarantoolData] = []any{"running"}
+		}
+
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		_ = enc.Encode(map[int]uint64{0: code, 1: header[1]})
+		_ = enc.Encode(response)
+		binary.BigEndian.PutUint32(frame[1:], uint32(buf.Len())) //nolint:gosec // small test packets.
+		frame[0] = 0xce
+		if _, err := conn.Write(append(frame[:], buf.Bytes()...)); err != nil {
+			return
+		}
+	}
+}
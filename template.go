@@ -0,0 +1,258 @@
+package testdock
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+)
+
+// templateDatabasePrefix is the prefix of the names of template databases.
+const templateDatabasePrefix = "t_tpl_"
+
+// we ensure the template databases are checked or built only once per test binary.
+//
+//nolint:gochecknoglobals // used to synchronize access to the same template database across tests.
+var (
+	globalTemplateMu        sync.Mutex
+	globalTemplateDatabases = make(map[string]*templateDatabaseInfo)
+)
+
+type templateDatabaseInfo struct {
+	ready bool
+	mu    sync.Mutex
+}
+
+// WithTemplateDatabase creates the test databases from a migrated template database.
+// Only PostgreSQL is supported.
+func WithTemplateDatabase() Option {
+	return func(o *testDB) {
+		o.templateDatabase = true
+	}
+}
+
+// prepareTemplateOptions validates WithTemplateDatabase.
+func (d *testDB) prepareTemplateOptions() error {
+	if !d.templateDatabase {
+		return nil
+	}
+
+	switch {
+	case !isPostgresDriver(d.driver):
+		return fmt.Errorf("WithTemplateDatabase is not supported for driver %s", d.driver)
+	case d.migrationsDir == "" && len(d.extraMigrations) == 0 && len(d.initQueries) == 0:
+		return errors.New("WithTemplateDatabase requires migrations or init queries")
+	case d.noTestDatabase:
+		return errors.New("WithTemplateDatabase can not be used without a test database")
+	case d.artifactMode != ArtifactModeOff:
+		return errors.New("WithTemplateDatabase can not be used with WithArtifact")
+	case d.citusWorkers > 0:
+		return errors.New("WithTemplateDatabase is not supported for Citus")
+	}
+
+	return nil
+}
+
+// prepareTemplateDatabase builds the template database if it does not exist,
+// so createTestDatabase creates the test database from it.
+func (d *testDB) prepareTemplateDatabase(ctx context.Context) error {
+	if !d.templateDatabase {
+		return nil
+	}
+	if err := d.extractMigrationsFS(); err != nil {
+		return err
+	}
+
+	name, err := d.templateDatabaseName()
+	if err != nil {
+		return err
+	}
+
+	key := d.url.replaceDatabase(name).string(false)
+	if d.resource != nil && d.resource.Container != nil {
+		// the template is lost with a removed container
+		key += " " + d.resource.Container.ID
+	}
+	globalTemplateMu.Lock()
+	info, ok := globalTemplateDatabases[key]
+	if !ok {
+		info = &templateDatabaseInfo{}
+		globalTemplateDatabases[key] = info
+	}
+	globalTemplateMu.Unlock()
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if !info.ready {
+		if err = d.ensureTemplateDatabase(ctx, name); err != nil {
+			return fmt.Errorf("template database %s: %w", name, err)
+		}
+		info.ready = true
+	}
+	d.templateName = name
+
+	return nil
+}
+
+// templateDatabaseName returns the name of the template database from the hash of the migrations.
+func (d *testDB) templateDatabaseName() (string, error) {
+	sum, err := d.migrationsHash()
+	if err != nil {
+		return "", err
+	}
+
+	const hashLen = 24
+
+	return templateDatabasePrefix + sum[:hashLen], nil
+}
+
+// migrationsHash returns the hash of the migration files, the migrate factories with their options,
+// the init queries and the migration target version, which identifies the schema of a migrated test database.
+func (d *testDB) migrationsHash() (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "init %q\n", d.initQueries)
+	if d.hasMigrationTargetVersion {
+		_, _ = fmt.Fprintf(h, "target %d\n", d.migrationTargetVersion)
+	}
+
+	if d.migrationsDir != "" {
+		_, _ = fmt.Fprintf(h, "factory %s\n", migrateFactoryDescription(d.migrateFactory))
+		if err := d.hashTemplateMigrations(h, "", d.migrationsDir); err != nil {
+			return "", err
+		}
+	}
+	for i, set := range d.extraMigrations {
+		prefix := fmt.Sprintf("extra %d", i)
+		_, _ = fmt.Fprintf(h, "%s factory %s\n", prefix, migrateFactoryDescription(set.factory))
+		if err := d.hashTemplateMigrations(h, prefix, set.dir); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// hashTemplateMigrations adds the checksums of the migration files of the directory to the hash.
+// Source URLs of golang-migrate are hashed by the URL.
+func (d *testDB) hashTemplateMigrations(h hash.Hash, prefix, dir string) error {
+	if isMigrationsSourceURL(dir) {
+		_, _ = fmt.Fprintf(h, "%s url %s\n", prefix, dir)
+		return nil
+	}
+
+	var fsys fs.FS
+	if prefix == "" {
+		var err error
+		if fsys, err = d.migrationsDirFS(); err != nil {
+			return err
+		}
+	} else {
+		resolved, err := resolveMigrationsDir(dir)
+		if err != nil {
+			return err
+		}
+		fsys = os.DirFS(resolved)
+	}
+
+	checksums := make(map[string]string)
+	if err := addMigrationChecksums(checksums, fsys, ""); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(checksums)) {
+		_, _ = fmt.Fprintf(h, "%s %s %s\n", prefix, name, checksums[name])
+	}
+
+	return nil
+}
+
+// ensureTemplateDatabase builds the template database unless a complete one exists.
+// An advisory lock serializes test binaries which build the same template on one server.
+func (d *testDB) ensureTemplateDatabase(ctx context.Context, name string) error {
+	db, err := d.connectSQLDB(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // the advisory lock is released with the connection.
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", name); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+	}()
+
+	// the template is marked as a template only after the migrations succeeded
+	var isTemplate bool
+	err = conn.QueryRowContext(ctx, "SELECT datistemplate FROM pg_database WHERE datname = $1", name).Scan(&isTemplate)
+	switch {
+	case err == nil && isTemplate:
+		d.logger.Info(ctx, "using template database", "dsn", d.dsnNoPass, "template", name)
+		return nil
+	case err == nil:
+		d.logger.Info(ctx, "removing incomplete template database", "dsn", d.dsnNoPass, "template", name)
+		if _, err = conn.ExecContext(ctx, "DROP DATABASE "+name); err != nil {
+			return fmt.Errorf("drop incomplete template: %w", err)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("find template: %w", err)
+	}
+
+	d.logger.Info(ctx, "building template database", "dsn", d.dsnNoPass, "template", name)
+	if _, err = conn.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		return fmt.Errorf("create template: %w", err)
+	}
+
+	if err = d.migrateTemplateDatabase(ctx, name); err != nil {
+		if _, dropErr := conn.ExecContext(context.Background(), "DROP DATABASE "+name); dropErr != nil {
+			d.logger.Info(ctx, "failed to drop template database", "dsn", d.dsnNoPass, "error", dropErr)
+		}
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "ALTER DATABASE "+name+" WITH IS_TEMPLATE true ALLOW_CONNECTIONS false")
+	if err != nil {
+		return fmt.Errorf("mark template: %w", err)
+	}
+	// CREATE DATABASE ... TEMPLATE fails while the template has connections, for example idle connections of a migrator
+	if _, err = conn.ExecContext(ctx,
+		"SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+		name); err != nil {
+		return fmt.Errorf("close template connections: %w", err)
+	}
+
+	d.logger.Info(ctx, "template database built", "dsn", d.dsnNoPass, "template", name)
+
+	return nil
+}
+
+// migrateTemplateDatabase executes the init queries and applies the migrations to the template database.
+func (d *testDB) migrateTemplateDatabase(ctx context.Context, name string) error {
+	testDatabase := d.databaseName
+	d.databaseName = name
+	defer func() {
+		d.databaseName = testDatabase
+	}()
+
+	if err := d.initTestDatabase(ctx); err != nil {
+		return err
+	}
+	if d.migrationsDir != "" || len(d.extraMigrations) > 0 {
+		return d.migrationsUp(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxTemplateDatabase(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5555", 1)
+	options := []Option{
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithTemplateDatabase(),
+		WithDockerImage(testPostgresImage),
+	}
+
+	var databases []string
+	for range 2 {
+		pool, info := GetPgxPool(t, dsn, options...)
+		requireTemplateMigrated(t, pool)
+		databases = append(databases, info.DatabaseName())
+	}
+	require.NotEqual(t, databases[0], databases[1])
+}
+
+// Test_PgxTemplateDatabaseSequential1 and Test_PgxTemplateDatabaseSequential2 are not parallel,
+// so the second test runs in a new container after the first one is purged and must build the template again.
+func Test_PgxTemplateDatabaseSequential1(t *testing.T) {
+	testPgxTemplateDatabaseSequential(t)
+}
+
+func Test_PgxTemplateDatabaseSequential2(t *testing.T) {
+	testPgxTemplateDatabaseSequential(t)
+}
+
+func testPgxTemplateDatabaseSequential(t *testing.T) {
+	t.Helper()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5562", 1)
+	pool, _ := GetPgxPool(t, dsn,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithTemplateDatabase(),
+		WithDockerImage(testPostgresImage),
+	)
+	requireTemplateMigrated(t, pool)
+}
+
+// requireTemplateMigrated checks that the test database is a copy of the migrated template.
+func requireTemplateMigrated(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	var versions int
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM goose_db_version").Scan(&versions))
+	require.Positive(t, versions)
+
+	var templates int
+	require.NoError(t, pool.QueryRow(t.Context(),
+		"SELECT count(*) FROM pg_database WHERE datistemplate AND datname LIKE 't_tpl_%'").Scan(&templates))
+	require.Equal(t, 1, templates)
+}
+
+// TestTemplateDatabaseName verifies that the template name changes with the migrations.
+func TestTemplateDatabaseName(t *testing.T) {
+	t.Parallel()
+
+	templateName := func(files fstest.MapFS, extensions ...string) string {
+		db := newExternalTestDB(t)
+		require.NoError(t, db.prepareOptions(db.driver, []Option{
+			WithMigrationsFS(files, "sql", GooseMigrateFactoryPGX), WithPostgresExtensions(extensions...),
+			WithTemplateDatabase(),
+		}))
+		name, err := db.templateDatabaseName()
+		require.NoError(t, err)
+		return name
+	}
+
+	files := fstest.MapFS{"sql/0001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE a (id INT);")}}
+	name := templateName(files)
+	require.True(t, strings.HasPrefix(name, templateDatabasePrefix))
+	require.Len(t, name, len(templateDatabasePrefix)+24)
+	require.Equal(t, name, templateName(files))
+
+	changed := fstest.MapFS{"sql/0001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE b (id INT);")}}
+	require.NotEqual(t, name, templateName(changed))
+	require.NotEqual(t, name, templateName(files, "pgcrypto"))
+}
+
+// TestPrepareTemplateOptions verifies the validation of WithTemplateDatabase.
+func TestPrepareTemplateOptions(t *testing.T) {
+	t.Parallel()
+
+	db := newExternalTestDB(t)
+	err := db.prepareOptions(db.driver, []Option{WithTemplateDatabase()})
+	require.ErrorContains(t, err, "requires migrations or init queries")
+
+	db = newExternalTestDB(t)
+	db.driver = "mysql"
+	db.dsn = DefaultMySQLDSN
+	err = db.prepareOptions(db.driver, []Option{
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX), WithTemplateDatabase(),
+	})
+	require.ErrorContains(t, err, "not supported for driver mysql")
+}
+
+// TestMigrationsHashFactory verifies that the migrations hash changes with the migrate factory and its options.
+func TestMigrationsHashFactory(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{"sql/0001_init.sql": &fstest.MapFile{Data: []byte("CREATE TABLE a (id INT);")}}
+	hash := func(factory MigrateFactory) string {
+		db := newTestDB(t, "pgx", DefaultPostgresDSN)
+		require.NoError(t, db.prepareOptions(db.driver, []Option{
+			WithMode(RunModeExternal), WithMigrationsFS(files, "sql", factory),
+		}))
+		sum, err := db.migrationsHash()
+		require.NoError(t, err)
+		return sum
+	}
+
+	sum := hash(GooseMigrateFactoryPGX)
+	require.Equal(t, sum, hash(GooseMigrateFactory(goose.DialectPostgres, "pgx")))
+	require.NotEqual(t, sum, hash(GooseMigrateFactory(goose.DialectPostgres, "postgres")))
+	require.NotEqual(t, sum, hash(SQLScriptMigrateFactory("pgx")))
+
+	table := hash(GooseMigrateFactory(goose.DialectPostgres, "pgx", goose.WithTableName("versions")))
+	require.NotEqual(t, sum, table)
+	require.Equal(t, table, hash(GooseMigrateFactory(goose.DialectPostgres, "pgx", goose.WithTableName("versions"))))
+	require.NotEqual(t, table, hash(GooseMigrateFactory(goose.DialectPostgres, "pgx", goose.WithTableName("other"))))
+}
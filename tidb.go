@@ -0,0 +1,64 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// GetTiDBConn inits a test TiDB database, applies migrations,
+// and returns sql connection (mysql driver) to the database.
+// The TiDB docker image starts with an empty root password,
+// so the password from the DSN is set for the root user after the container is created.
+// Docker image: https://hub.docker.com/r/pingcap/tidb.
+func GetTiDBConn(tb testing.TB, dsn string, opt ...Option) (*sql.DB, Informer) {
+	tb.Helper()
+
+	optPrepared := make([]Option, 0, len(opt))
+	optPrepared = append(optPrepared,
+		WithDockerRepository("pingcap/tidb"),
+		WithDockerImage("v8.5.1"),
+		withDockerStartHook(setTiDBRootPassword),
+	)
+
+	optPrepared = append(optPrepared, withDriverDefaults("tidb", opt)...)
+
+	return getSQLConn(tb, "mysql", dsn, optPrepared...)
+}
+
+// setTiDBRootPassword sets the password from the DSN for the root user of a new TiDB container.
+func setTiDBRootPassword(ctx context.Context, d *testDB) error {
+	if d.url.User != "root" || d.url.Password == "" {
+		return nil
+	}
+
+	u := d.url.replaceDatabase("")
+	u.Password = ""
+
+	var db *sql.DB
+	err := d.retryConnect(ctx, u.string(true), func() (err error) {
+		db, err = sql.Open(d.driver, u.string(false))
+		if err != nil {
+			return err
+		}
+		if err = db.PingContext(ctx); err != nil {
+			_ = db.Close()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("connect tidb without password: %w", err)
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER USER 'root'@'%%' IDENTIFIED BY '%s'",
+		strings.ReplaceAll(d.url.Password, "'", "''")))
+	if err != nil {
+		return fmt.Errorf("set tidb root password: %w", err)
+	}
+
+	return nil
+}
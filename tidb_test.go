@@ -0,0 +1,19 @@
+package testdock
+
+import (
+	"testing"
+)
+
+func Test_TiDB(t *testing.T) {
+	t.Parallel()
+
+	db, informer := GetTiDBConn(t,
+		DefaultTiDBDSN,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryTiDB),
+		WithMode(RunModeDocker),
+	)
+
+	checkInformer(t, DefaultTiDBDSN, informer)
+
+	testSQLHelper(t, db)
+}
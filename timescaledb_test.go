@@ -0,0 +1,32 @@
+package testdock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TimescaleDB(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, informer := GetTimescalePool(t,
+		DefaultPostgresDSN,
+		WithMigrations("migrations/timescale/goose", GooseMigrateFactoryPGX),
+		WithMode(RunModeDocker), // external postgres has no timescaledb extension
+	)
+
+	checkInformer(t, DefaultPostgresDSN, informer)
+
+	_, err := db.Exec(ctx, "INSERT INTO test_metrics (ts, value) VALUES ($1, $2)", time.Now(), 1.5)
+	require.NoError(t, err)
+
+	var hypertables int
+	err = db.QueryRow(ctx,
+		"SELECT count(*) FROM timescaledb_information.hypertables WHERE hypertable_name = 'test_metrics'",
+	).Scan(&hypertables)
+	require.NoError(t, err)
+	require.Equal(t, 1, hypertables)
+}
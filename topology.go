@@ -0,0 +1,183 @@
+package testdock
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// dockerTopologyNameLength is the length of the unique name of the topology network.
+const dockerTopologyNameLength = 20
+
+// dockerTopology is a private docker network with the main container of the test database
+// and auxiliary containers started by docker start hooks, for example replicas or cluster nodes.
+// Containers find each other by container names, see containerName.
+type dockerTopology struct {
+	name      string              // unique prefix of the network and container names
+	network   *dockertest.Network // private network of the containers
+	mu        sync.Mutex
+	resources []*dockertest.Resource          // auxiliary containers in the order of creation
+	byRole    map[string]*dockertest.Resource // auxiliary containers by role
+	configDir string                          // host directory with generated configuration files
+}
+
+// withDockerTopology runs the main container with the role name on a private docker network,
+// so docker start hooks can add auxiliary containers with runTopologyContainer.
+// Containers of a topology are not shared with other test binaries.
+func withDockerTopology(role string) Option {
+	return func(o *testDB) {
+		o.topologyRole = role
+	}
+}
+
+// newDockerTopology creates the private network of the topology.
+func newDockerTopology(pool *dockertest.Pool) (*dockerTopology, error) {
+	name := "testdock-" + strings.ReplaceAll(uuid.New().String(), "-", "")[:dockerTopologyNameLength-len("testdock-")]
+
+	network, err := pool.CreateNetwork(name, func(config *docker.CreateNetworkOptions) {
+		config.Labels = dockerNetworkLabels()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create docker network %s: %w", name, err)
+	}
+
+	return &dockerTopology{ //nolint:exhaustruct // mutex and containers start from zero values.
+		name:    name,
+		network: network,
+		byRole:  make(map[string]*dockertest.Resource),
+	}, nil
+}
+
+// containerName returns the name of the container with the role, which is also its host name in the network.
+func (t *dockerTopology) containerName(role string) string {
+	return t.name + "-" + role
+}
+
+// resource returns the auxiliary container with the role.
+func (t *dockerTopology) resource(role string) *dockertest.Resource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.byRole[role]
+}
+
+// writeConfig writes the generated configuration file to the host directory of the topology
+// and returns its path for mounting into containers. The directory is removed with the topology,
+// so the Docker daemon must have access to the host file system.
+func (t *dockerTopology) writeConfig(name, content string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.configDir == "" {
+		dir, err := os.MkdirTemp("", t.name+"-")
+		if err != nil {
+			return "", fmt.Errorf("create topology config directory: %w", err)
+		}
+		t.configDir = dir
+	}
+
+	path := filepath.Join(t.configDir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil { //nolint:gosec,mnd // containers read the file as another user.
+		return "", fmt.Errorf("write topology config %s: %w", name, err)
+	}
+
+	return path, nil
+}
+
+// runTopologyContainer starts an auxiliary container with the role in the network of the topology.
+// Ports of the container are published on random host ports, see topologyHostPort.
+func (d *testDB) runTopologyContainer(role string, runOptions *dockertest.RunOptions) (*dockertest.Resource, error) {
+	if d.topology == nil {
+		return nil, errors.New("docker topology is not created")
+	}
+
+	runOptions.Name = d.topology.containerName(role)
+	runOptions.NetworkID = d.topology.network.Network.ID
+	labels := d.containerLabels()
+	maps.Copy(labels, runOptions.Labels)
+	runOptions.Labels = labels
+
+	resource, err := globalDockerPool.RunWithOptions(runOptions, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no", MaximumRetryCount: 0}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("run %s container: %w", role, err)
+	}
+
+	d.topology.mu.Lock()
+	d.topology.resources = append(d.topology.resources, resource)
+	d.topology.byRole[role] = resource
+	d.topology.mu.Unlock()
+
+	return resource, nil
+}
+
+// topologyHostPort returns the host port published for the port of the auxiliary container with the role.
+func (d *testDB) topologyHostPort(role string, port int) (int, error) {
+	if d.topology == nil {
+		return 0, errors.New("docker topology is not created")
+	}
+
+	resource := d.topology.resource(role)
+	if resource == nil {
+		return 0, fmt.Errorf("%s container is not started", role)
+	}
+
+	var hostPort int
+	if _, err := fmt.Sscan(resource.GetPort(fmt.Sprintf("%d/tcp", port)), &hostPort); err != nil {
+		return 0, fmt.Errorf("port %d of %s container is not published: %w", port, role, err)
+	}
+
+	return hostPort, nil
+}
+
+// purge removes the auxiliary containers and the network of the topology.
+// The main container must be removed before, otherwise the network is still in use.
+func (t *dockerTopology) purge(pool *dockertest.Pool) error {
+	t.mu.Lock()
+	resources := t.resources
+	t.resources = nil
+	t.byRole = make(map[string]*dockertest.Resource)
+	configDir := t.configDir
+	t.configDir = ""
+	t.mu.Unlock()
+
+	var errs []error
+	for _, resource := range resources {
+		if err := pool.Purge(resource); err != nil {
+			errs = append(errs, fmt.Errorf("purge docker resource %s: %w", resource.Container.Name, err))
+		}
+	}
+	if err := pool.RemoveNetwork(t.network); err != nil {
+		errs = append(errs, fmt.Errorf("remove docker network %s: %w", t.name, err))
+	}
+	if configDir != "" {
+		if err := os.RemoveAll(configDir); err != nil {
+			errs = append(errs, fmt.Errorf("remove topology config directory: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// topologyURL returns the url of the DSN with the host port published for the port of the auxiliary container.
+func (d *testDB) topologyURL(role string, port int) (*dbURL, error) {
+	hostPort, err := d.topologyHostPort(role, port)
+	if err != nil {
+		return nil, err
+	}
+
+	url := d.url.clone()
+	url.Port = hostPort
+
+	return url, nil
+}
@@ -0,0 +1,160 @@
+package testdock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// truncateKeepTables are the version tables of migrators, which TruncateAll keeps,
+// so migrations are not applied again to the emptied database.
+//
+//nolint:gochecknoglobals // predefined table names.
+var truncateKeepTables = []string{
+	"goose_db_version",
+	"schema_migrations",
+	"flyway_schema_history",
+	atlasRevisionsTable,
+	migrationChecksumTable,
+}
+
+// TruncateAll removes the rows of all tables of the test database except the listed tables
+// and the version tables of migrators.
+func (d *testDB) TruncateAll(ctx context.Context, exceptTables ...string) error {
+	if d.driver != "mysql" && !isPostgresDriver(d.driver) {
+		return fmt.Errorf("truncate is not supported for driver %s", d.driver)
+	}
+
+	db, err := d.connectSQLDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases setup connection; keep ExecContext result.
+
+	if d.driver == "mysql" {
+		err = truncateMySQLTables(ctx, db, d.databaseName, exceptTables)
+	} else {
+		err = truncatePostgresTables(ctx, db, d.schemaName, exceptTables)
+	}
+	if err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+
+	d.logger.Info(ctx, "test database truncated", "dsn", d.dsnNoPass, "database", d.databaseName)
+
+	return nil
+}
+
+// truncatePostgresTables empties the tables of all user schemas, or of the schema of WithSchemaIsolation,
+// with one TRUNCATE ... CASCADE, so the order of foreign keys does not matter
+// and sequences of identity columns are restarted. The tables of extensions, like spatial_ref_sys of PostGIS
+// or the catalogs of TimescaleDB, are kept.
+func truncatePostgresTables(ctx context.Context, db *sql.DB, schema string, exceptTables []string) error {
+	rows, err := db.QueryContext(ctx, `SELECT t.schemaname, t.tablename FROM pg_tables t
+		WHERE t.schemaname NOT IN ('pg_catalog', 'information_schema') AND t.schemaname NOT LIKE 'pg_toast%'
+		AND ($1::text = '' OR t.schemaname = $1::text)
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d
+			WHERE d.classid = 'pg_class'::regclass AND d.deptype = 'e'
+			AND d.objid = format('%I.%I', t.schemaname, t.tablename)::regclass)`, schema)
+	if err != nil {
+		return fmt.Errorf("list tables: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err reports the iteration errors.
+
+	var tables []string
+	for rows.Next() {
+		var schema, table string
+		if err = rows.Scan(&schema, &table); err != nil {
+			return fmt.Errorf("scan table: %w", err)
+		}
+		if isTruncateKept(exceptTables, table, schema+"."+table) {
+			continue
+		}
+		tables = append(tables, pgx.Identifier{schema, table}.Sanitize())
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("list tables: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, "TRUNCATE TABLE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE")
+
+	return err
+}
+
+// truncateMySQLTables empties the tables of the database with the foreign key checks disabled for the session.
+func truncateMySQLTables(ctx context.Context, db *sql.DB, database string, exceptTables []string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connection: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck // the session variable is dropped with the connection.
+
+	tables, err := listMySQLTables(ctx, conn, database)
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("disable foreign key checks: %w", err)
+	}
+	for _, table := range tables {
+		if isTruncateKept(exceptTables, table) {
+			continue
+		}
+		if _, err = conn.ExecContext(ctx, "TRUNCATE TABLE "+quoteMySQLIdentifier(table)); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	if _, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+		return fmt.Errorf("enable foreign key checks: %w", err)
+	}
+
+	return nil
+}
+
+// isTruncateKept reports that the table is excluded from TruncateAll by any of its names.
+func isTruncateKept(exceptTables []string, names ...string) bool {
+	for _, name := range names {
+		if slices.Contains(exceptTables, name) || slices.Contains(truncateKeepTables, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listMySQLTables returns the base tables of the MySQL database.
+func listMySQLTables(ctx context.Context, conn *sql.Conn, database string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = ? AND table_type = 'BASE TABLE'`, database)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err reports the iteration errors.
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("scan table: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// quoteMySQLIdentifier quotes the MySQL identifier.
+func quoteMySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
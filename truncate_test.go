@@ -0,0 +1,53 @@
+package testdock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PgxTruncateAll(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5557", 1)
+	pool, info := GetPgxPool(t, dsn,
+		WithMigrations("migrations/pg/goose", GooseMigrateFactoryPGX),
+		WithDockerImage(testPostgresImage),
+	)
+
+	_, err := pool.Exec(t.Context(), "CREATE TABLE kept (id INT)")
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), "INSERT INTO kept VALUES (1)")
+	require.NoError(t, err)
+
+	dbInfo, ok := info.(DatabaseInformer)
+	require.True(t, ok)
+	require.NoError(t, dbInfo.TruncateAll(t.Context(), "public.kept"))
+
+	count := func(table string) int {
+		var n int
+		require.NoError(t, pool.QueryRow(t.Context(), "SELECT count(*) FROM "+table).Scan(&n))
+		return n
+	}
+	require.Zero(t, count("test_table"))
+	require.Equal(t, 1, count("kept"))
+	require.Positive(t, count("goose_db_version"))
+
+	// identity sequences are restarted
+	var id int
+	require.NoError(t, pool.QueryRow(t.Context(), "INSERT INTO test_table (name) VALUES ('a') RETURNING id").Scan(&id))
+	require.Equal(t, 1, id)
+}
+
+// TestIsTruncateKept verifies the tables kept by TruncateAll.
+func TestIsTruncateKept(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isTruncateKept(nil, "goose_db_version"))
+	require.True(t, isTruncateKept(nil, migrationChecksumTable))
+	require.True(t, isTruncateKept([]string{"public.users"}, "users", "public.users"))
+	require.True(t, isTruncateKept([]string{"users"}, "users", "public.users"))
+	require.False(t, isTruncateKept([]string{"audit.users"}, "users", "public.users"))
+	require.False(t, isTruncateKept(nil, "orders"))
+}
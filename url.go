@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net"
 	"slices"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 // dbURL represents a parsed database connection string.
 // Supported connection string format:
 // [protocol://]user:password@[transport(]host:port[)][/database][?option1=a&option2=b]
+// IPv6 hosts are written in brackets, for example [::1]:5432, and are stored without them.
 //
 // Required fields: user, password, host, port
 // Optional fields: protocol, transport, database and options.
@@ -119,19 +121,19 @@ func parseURL(connStr string) (*dbURL, error) {
 	}
 
 	// Parse host and port
-	hostAndPort := strings.SplitN(rest, ":", splitCount)
-	if len(hostAndPort) != splitCount {
-		return nil, errors.New("invalid connection string format: missing port")
+	host, port, err := splitHostPort(rest)
+	if err != nil {
+		return nil, err
 	}
-	u.Host = hostAndPort[0]
+	u.Host = host
 	if u.Host == "" {
 		return nil, errors.New("host is required")
 	}
 
-	if hostAndPort[1] == "" {
+	if port == "" {
 		return nil, errors.New("port is required")
 	}
-	p, err := strconv.Atoi(hostAndPort[1])
+	p, err := strconv.Atoi(port)
 	if err != nil {
 		return nil, fmt.Errorf("parse port: %w", err)
 	}
@@ -143,6 +145,39 @@ func parseURL(connStr string) (*dbURL, error) {
 	return u, nil
 }
 
+// splitHostPort splits host:port or [ipv6]:port into the host without brackets and the port.
+func splitHostPort(hostPort string) (string, string, error) {
+	if rest, ok := strings.CutPrefix(hostPort, "["); ok {
+		host, port, found := strings.Cut(rest, "]")
+		if !found {
+			return "", "", errors.New("invalid connection string format: missing ']' in IPv6 host")
+		}
+		if port, found = strings.CutPrefix(port, ":"); !found {
+			return "", "", errors.New("invalid connection string format: missing port")
+		}
+		if net.ParseIP(host) == nil {
+			return "", "", fmt.Errorf("invalid IPv6 host %s", host)
+		}
+
+		return host, port, nil
+	}
+
+	host, port, found := strings.Cut(hostPort, ":")
+	if !found {
+		return "", "", errors.New("invalid connection string format: missing port")
+	}
+	if strings.Contains(port, ":") {
+		return "", "", errors.New("invalid connection string format: IPv6 host must be in brackets")
+	}
+
+	return host, port, nil
+}
+
+// address returns host:port of the URL, the IPv6 host is written in brackets.
+func (u *dbURL) address() string {
+	return net.JoinHostPort(u.Host, strconv.Itoa(u.Port))
+}
+
 // string returns the connection string representation of the URL.
 func (u *dbURL) string(hidePassword bool) string {
 	if u == nil {
@@ -177,7 +212,11 @@ func (u *dbURL) string(hidePassword bool) string {
 		writeString(u.Transport)
 		writeString("(")
 	}
-	writeString(u.Host)
+	if strings.Contains(u.Host, ":") {
+		writeString("[" + u.Host + "]")
+	} else {
+		writeString(u.Host)
+	}
 	if u.Port != 0 {
 		writeString(":")
 		writeString(strconv.Itoa(u.Port))
@@ -0,0 +1,73 @@
+// Package vector returns the clients of the vector databases started by testdock.
+package vector
+
+import (
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	"github.com/n-r-w/testdock/v2/internal/backend"
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/auth"
+)
+
+// qdrantGRPCDockerPort is the gRPC port of Qdrant inside the docker container.
+const qdrantGRPCDockerPort = 6334
+
+// GetQdrantClient starts Qdrant like testdock.GetQdrant and returns the qdrant go-client
+// connected to the gRPC API. Use collection names starting with VectorInformer.Prefix.
+// The client is closed after the test.
+// In RunModeExternal the gRPC port is the REST port of the DSN plus one, as in the default configuration of Qdrant.
+func GetQdrantClient(tb testing.TB, dsn string, opt ...testdock.Option) (*qdrant.Client, testdock.VectorInformer) {
+	tb.Helper()
+
+	informer := testdock.GetQdrant(tb, dsn, opt...)
+	db := backend.FromInformer(informer)
+
+	//nolint:exhaustruct // defaults of the qdrant client.
+	client, err := qdrant.NewClient(&qdrant.Config{
+		Host:   db.URL().Host,
+		Port:   qdrantGRPCPort(db),
+		APIKey: informer.APIKey(),
+		// the image is configurable, so its version may differ from the client
+		SkipCompatibilityCheck: true,
+	})
+	if err != nil {
+		tb.Fatalf("cannot create qdrant client: %v", err)
+	}
+
+	db.CloseAfterTest("qdrant client", client.Close)
+
+	return client, informer
+}
+
+// GetWeaviateClient starts Weaviate like testdock.GetWeaviate and returns the weaviate-go-client client
+// authenticated with the API key of the DSN. Use class names starting with VectorInformer.Prefix.
+func GetWeaviateClient(tb testing.TB, dsn string, opt ...testdock.Option,
+) (*weaviate.Client, testdock.VectorInformer) {
+	tb.Helper()
+
+	informer := testdock.GetWeaviate(tb, dsn, opt...)
+
+	//nolint:exhaustruct // defaults of the weaviate client.
+	client, err := weaviate.NewClient(weaviate.Config{
+		Host:       backend.FromInformer(informer).URL().Address(),
+		Scheme:     "http",
+		AuthConfig: auth.ApiKey{Value: informer.APIKey()},
+	})
+	if err != nil {
+		tb.Fatalf("cannot create weaviate client: %v", err)
+	}
+
+	return client, informer
+}
+
+// qdrantGRPCPort returns the host port of the gRPC API of Qdrant: the published port of the container,
+// or the REST port plus one without a docker container.
+func qdrantGRPCPort(db backend.Database) int {
+	if port, ok := db.PublishedPort(qdrantGRPCDockerPort); ok {
+		return port
+	}
+
+	return db.URL().Port + 1
+}
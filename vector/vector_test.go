@@ -0,0 +1,44 @@
+package vector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/testdock/v2"
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+func Test_Qdrant(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetQdrantClient(t, testdock.DefaultQdrantDSN, testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, "secret", informer.APIKey())
+	require.NotEmpty(t, informer.Endpoint())
+	name := informer.Prefix() + "_items"
+
+	//nolint:exhaustruct // optional SDK fields use zero values.
+	require.NoError(t, client.CreateCollection(t.Context(), &qdrant.CreateCollection{
+		CollectionName: name,
+		VectorsConfig:  qdrant.NewVectorsConfig(&qdrant.VectorParams{Size: 4, Distance: qdrant.Distance_Cosine}),
+	}))
+	exists, err := client.CollectionExists(t.Context(), name)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func Test_Weaviate(t *testing.T) {
+	t.Parallel()
+
+	client, informer := GetWeaviateClient(t, testdock.DefaultWeaviateDSN, testdock.WithMode(testdock.RunModeDocker))
+	require.Equal(t, "secret", informer.APIKey())
+	require.True(t, strings.HasPrefix(informer.Prefix(), "T_"))
+	require.NotEmpty(t, informer.Endpoint())
+	name := informer.Prefix() + "Item"
+
+	require.NoError(t, client.Schema().ClassCreator().WithClass(&models.Class{Class: name}).Do(t.Context())) //nolint:exhaustruct // optional SDK fields use zero values.
+	exists, err := client.Schema().ClassExistenceChecker().WithClassName(name).Do(t.Context())
+	require.NoError(t, err)
+	require.True(t, exists)
+}
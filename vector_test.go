@@ -0,0 +1,99 @@
+package testdock
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Qdrant(t *testing.T) {
+	t.Parallel()
+
+	informer := GetQdrant(t, DefaultQdrantDSN, WithMode(RunModeDocker))
+	require.Equal(t, "secret", informer.APIKey())
+	require.NotEmpty(t, informer.Prefix())
+	require.NotEmpty(t, informer.Endpoint())
+}
+
+func Test_Weaviate(t *testing.T) {
+	t.Parallel()
+
+	informer := GetWeaviate(t, DefaultWeaviateDSN, WithMode(RunModeDocker))
+	require.Equal(t, "secret", informer.APIKey())
+	require.True(t, strings.HasPrefix(informer.Prefix(), "T_"))
+	require.NotEmpty(t, informer.Endpoint())
+}
+
+// TestDeleteQdrantPrefix verifies the readiness wait and that only collections with the test prefix are deleted.
+func TestDeleteQdrantPrefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+		prefix  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/readyz":
+			_, _ = w.Write([]byte("all shards are ready"))
+		case r.Method == http.MethodGet:
+			require.Equal(t, "key", r.Header.Get("Api-Key"))
+			require.Equal(t, "/collections", r.URL.Path)
+			_, _ = fmt.Fprintf(w, `{"result":{"collections":[{"name":"%s_points"},{"name":"other"}]}}`, prefix)
+		case r.Method == http.MethodDelete:
+			require.Equal(t, "key", r.Header.Get("Api-Key"))
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/collections/"))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"result":true}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newSearchTestDB(t, qdrantDriverName, srv.URL)
+	prefix = db.databaseName
+
+	require.NoError(t, db.waitVectorDB(t.Context()))
+	require.NoError(t, db.deleteVectorPrefix(t.Context()))
+	require.Equal(t, []string{prefix + "_points"}, deleted)
+}
+
+// TestDeleteWeaviatePrefix verifies the class name prefix and that only classes with it are deleted.
+func TestDeleteWeaviatePrefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+		prefix  string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/.well-known/ready":
+		case r.Method == http.MethodGet:
+			require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			require.Equal(t, "/v1/schema", r.URL.Path)
+			_, _ = fmt.Fprintf(w, `{"classes":[{"class":"%sArticle"},{"class":"Other"}]}`, prefix)
+		case r.Method == http.MethodDelete:
+			require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1/schema/"))
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	db := newSearchTestDB(t, weaviateDriverName, srv.URL)
+	prefix = db.databaseName
+	require.True(t, strings.HasPrefix(prefix, "T_"))
+
+	require.NoError(t, db.waitVectorDB(t.Context()))
+	require.NoError(t, db.deleteVectorPrefix(t.Context()))
+	require.Equal(t, []string{prefix + "Article"}, deleted)
+}
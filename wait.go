@@ -0,0 +1,298 @@
+package testdock
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// waitStrategyInterval is the interval between the checks of a wait strategy.
+const waitStrategyInterval = 250 * time.Millisecond
+
+// WaitStrategy checks whether a new docker container is ready to accept connections.
+// Strategies are checked once after the container is created and before the connection to the database,
+// so the connection does not have to be retried while the engine is starting.
+type WaitStrategy interface {
+	// Ready returns nil when the container is ready. It is called repeatedly until it returns nil
+	// or the total retry duration (WithTotalRetryDuration) is reached.
+	Ready(ctx context.Context, target WaitTarget) error
+	// String returns the description of the strategy for logs and errors.
+	String() string
+}
+
+// WaitTarget describes the container checked by a WaitStrategy.
+type WaitTarget struct {
+	Pool          *dockertest.Pool     // docker pool of the container
+	Resource      *dockertest.Resource // docker container
+	Driver        string               // driver of the database
+	DSN           string               // connection string of the server with the host-mapped port
+	Host          string               // host of the host-mapped port
+	Port          int                  // host-mapped port
+	ContainerPort int                  // port of the database inside the container
+}
+
+// WithWaitStrategy sets the strategies which must succeed before a new docker container is considered ready.
+// The default is the strategies of the Get... function. Used only in RunModeDocker.
+func WithWaitStrategy(strategies ...WaitStrategy) Option {
+	return func(o *testDB) {
+		o.waitStrategies = strategies
+	}
+}
+
+// WaitForTCP waits until the host-mapped port accepts TCP connections.
+func WaitForTCP() WaitStrategy {
+	return tcpWaitStrategy{}
+}
+
+// WaitForExec waits until the command executed in the container exits with code 0,
+// for example WaitForExec("pg_isready", "-U", "postgres").
+func WaitForExec(cmd ...string) WaitStrategy {
+	return execWaitStrategy{cmd: cmd}
+}
+
+// WaitForSQL waits until the query succeeds on the server from the DSN.
+// Only databases with a database/sql driver are supported.
+func WaitForSQL(query string) WaitStrategy {
+	return sqlWaitStrategy{query: query}
+}
+
+// WaitForHTTP waits until GET of the path on the host-mapped port returns a 2xx status.
+func WaitForHTTP(path string) WaitStrategy {
+	return httpWaitStrategy{path: path}
+}
+
+// WaitForLog waits until the container output (stdout and stderr) contains at least occurrences matches
+// of the regular expression. The pattern is compiled when the strategy is checked.
+func WaitForLog(pattern string, occurrences int) WaitStrategy {
+	return logWaitStrategy{pattern: pattern, occurrences: max(occurrences, 1)}
+}
+
+// waitForContainer checks the wait strategies of a new container.
+func (d *testDB) waitForContainer(ctx context.Context, logDsn string) error {
+	if len(d.waitStrategies) == 0 {
+		return nil
+	}
+
+	target := WaitTarget{
+		Pool:          globalDockerPool,
+		Resource:      d.resource,
+		Driver:        d.driver,
+		DSN:           d.url.string(false),
+		Host:          d.url.Host,
+		Port:          d.url.Port,
+		ContainerPort: d.dockerPort,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.untilTestDeadline(d.totalRetryDuration))
+	defer cancel()
+
+	for _, strategy := range d.waitStrategies {
+		if err := waitFor(ctx, strategy, target); err != nil {
+			d.logger.Info(ctx, "container is not ready", "component", "docker", "dsn", logDsn,
+				"strategy", strategy.String(), "error", err)
+			return fmt.Errorf("wait for %s: %w", strategy, err)
+		}
+		d.logger.Info(ctx, "container is ready", "component", "docker", "dsn", logDsn, "strategy", strategy.String())
+	}
+
+	return nil
+}
+
+// waitFor checks the strategy until it succeeds or the context is done and returns the last error.
+func waitFor(ctx context.Context, strategy WaitStrategy, target WaitTarget) error {
+	for {
+		err := strategy.Ready(ctx, target)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(waitStrategyInterval):
+		}
+	}
+}
+
+// prepareWaitStrategies validates the wait strategies.
+func (d *testDB) prepareWaitStrategies() error {
+	for _, strategy := range d.waitStrategies {
+		if strategy == nil {
+			return errors.New("wait strategy is nil")
+		}
+		if s, ok := strategy.(logWaitStrategy); ok {
+			if _, err := regexp.Compile(s.pattern); err != nil {
+				return fmt.Errorf("wait strategy %s: %w", s, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tcpWaitStrategy waits for the host-mapped port.
+type tcpWaitStrategy struct{}
+
+// Ready connects to the port.
+func (tcpWaitStrategy) Ready(ctx context.Context, target WaitTarget) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.Host, strconv.Itoa(target.Port)))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// String returns the description of the strategy.
+func (tcpWaitStrategy) String() string {
+	return "tcp port"
+}
+
+// execWaitStrategy waits for the command in the container.
+type execWaitStrategy struct {
+	cmd []string
+}
+
+// Ready executes the command.
+func (s execWaitStrategy) Ready(_ context.Context, target WaitTarget) error {
+	if target.Resource == nil {
+		return errors.New("docker resource is not found")
+	}
+
+	var output bytes.Buffer
+	exitCode, err := target.Resource.Exec(s.cmd, dockertest.ExecOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		StdOut: &output,
+		StdErr: &output,
+	})
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("exit code %d: %s", exitCode, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+// String returns the description of the strategy.
+func (s execWaitStrategy) String() string {
+	return "exec " + strings.Join(s.cmd, " ")
+}
+
+// sqlWaitStrategy waits for the query.
+type sqlWaitStrategy struct {
+	query string
+}
+
+// Ready executes the query.
+func (s sqlWaitStrategy) Ready(ctx context.Context, target WaitTarget) error {
+	db, err := sql.Open(target.Driver, target.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck // Close only releases the check connection.
+
+	rows, err := db.QueryContext(ctx, s.query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck // rows.Err is checked below.
+
+	return rows.Err()
+}
+
+// String returns the description of the strategy.
+func (s sqlWaitStrategy) String() string {
+	return "sql " + s.query
+}
+
+// httpWaitStrategy waits for the HTTP endpoint.
+type httpWaitStrategy struct {
+	path string
+}
+
+// Ready requests the endpoint.
+func (s httpWaitStrategy) Ready(ctx context.Context, target WaitTarget) error {
+	url := fmt.Sprintf("http://%s/%s", net.JoinHostPort(target.Host, strconv.Itoa(target.Port)),
+		strings.TrimPrefix(s.path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // only the status is checked.
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// String returns the description of the strategy.
+func (s httpWaitStrategy) String() string {
+	return "http " + s.path
+}
+
+// logWaitStrategy waits for the lines of the container output.
+type logWaitStrategy struct {
+	pattern     string
+	occurrences int
+}
+
+// Ready reads the output of the container and counts the matches.
+func (s logWaitStrategy) Ready(ctx context.Context, target WaitTarget) error {
+	if target.Pool == nil || target.Resource == nil {
+		return errors.New("docker resource is not found")
+	}
+
+	var output bytes.Buffer
+	err := target.Pool.Client.Logs(docker.LogsOptions{ //nolint:exhaustruct // optional SDK fields use zero values.
+		Context:      ctx,
+		Container:    target.Resource.Container.ID,
+		OutputStream: &output,
+		ErrorStream:  &output,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.match(output.String())
+}
+
+// match checks the number of matches in the output.
+func (s logWaitStrategy) match(output string) error {
+	re, err := regexp.Compile(s.pattern)
+	if err != nil {
+		return err
+	}
+
+	if found := len(re.FindAllStringIndex(output, s.occurrences)); found < s.occurrences {
+		return fmt.Errorf("found %d of %d log lines", found, s.occurrences)
+	}
+
+	return nil
+}
+
+// String returns the description of the strategy.
+func (s logWaitStrategy) String() string {
+	return fmt.Sprintf("log %q x%d", s.pattern, s.occurrences)
+}
@@ -0,0 +1,125 @@
+package testdock
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWaitForTCP verifies the check of the host-mapped port.
+func TestWaitForTCP(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port //nolint:forcetypeassert // tcp listener.
+
+	target := WaitTarget{Host: "127.0.0.1", Port: port} //nolint:exhaustruct // only the address is used.
+	require.NoError(t, WaitForTCP().Ready(t.Context(), target))
+
+	require.NoError(t, listener.Close())
+	require.Error(t, WaitForTCP().Ready(t.Context(), target))
+}
+
+// TestWaitForHTTP verifies that the endpoint is requested until it returns a 2xx status.
+func TestWaitForHTTP(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	target := WaitTarget{Host: host, Port: portNum} //nolint:exhaustruct // only the address is used.
+	require.ErrorContains(t, WaitForHTTP("health").Ready(t.Context(), target), "503")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, waitFor(ctx, WaitForHTTP("/health"), target))
+	require.EqualValues(t, 3, requests.Load())
+}
+
+// TestWaitForLog verifies counting of the log lines, including the double start of MySQL.
+func TestWaitForLog(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tempServer = "[System] [MY-010931] [Server] /usr/sbin/mysqld: ready for connections. " +
+			"Version: '9.1.0'  socket: '/var/run/mysqld/mysqld.sock'  port: 0  MySQL Community Server - GPL.\n"
+		server = "[System] [MY-010931] [Server] /usr/sbin/mysqld: ready for connections. " +
+			"Version: '9.1.0'  socket: '/var/run/mysqld/mysqld.sock'  port: 3306  MySQL Community Server - GPL.\n"
+		mariaDB = "[Note] mariadbd: ready for connections.\n" +
+			"Version: '11.4.5-MariaDB-ubu2404'  socket: '/run/mysqld/mysqld.sock'  port: 3306  mariadb.org binary distribution\n"
+	)
+
+	strategy, ok := WaitForLog(mysqlReadyLog, 1).(logWaitStrategy)
+	require.True(t, ok)
+	require.ErrorContains(t, strategy.match(tempServer), "found 0 of 1 log lines")
+	require.NoError(t, strategy.match(tempServer+server))
+	require.NoError(t, strategy.match(mariaDB))
+
+	strategy, ok = WaitForLog("ready", 2).(logWaitStrategy)
+	require.True(t, ok)
+	require.ErrorContains(t, strategy.match("ready"), "found 1 of 2 log lines")
+	require.NoError(t, strategy.match("ready\nready"))
+}
+
+// TestWithWaitStrategy verifies that the strategies replace the defaults and are validated.
+func TestWithWaitStrategy(t *testing.T) {
+	t.Parallel()
+
+	newDB := func() *testDB {
+		db := newExternalTestDB(t)
+		return db
+	}
+
+	db := newDB()
+	require.NoError(t, db.prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("mysql"),
+		WithWaitStrategy(WaitForLog(mysqlReadyLog, 1)), WithWaitStrategy(WaitForTCP()),
+	}))
+	require.Equal(t, []WaitStrategy{WaitForTCP()}, db.waitStrategies)
+
+	err := newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("mysql"), WithWaitStrategy(WaitForLog("(", 1)),
+	})
+	require.ErrorContains(t, err, "missing closing )")
+
+	err = newDB().prepareOptions(db.driver, []Option{
+		WithMode(RunModeDocker), WithDockerRepository("mysql"), WithWaitStrategy(nil),
+	})
+	require.ErrorContains(t, err, "wait strategy is nil")
+}
+
+func Test_PgxWaitStrategy(t *testing.T) {
+	t.Parallel()
+
+	dsn := strings.Replace(DefaultPostgresDSN, "5432", "5545", 1)
+	pool, _ := GetPgxPool(t, dsn,
+		WithWaitStrategy(
+			WaitForTCP(),
+			WaitForExec("pg_isready", "-U", "postgres"),
+			WaitForLog("database system is ready to accept connections", 2),
+			WaitForSQL("SELECT 1"),
+		),
+	)
+	require.NoError(t, pool.Ping(t.Context()))
+}